@@ -26,7 +26,10 @@ func NewClientWithURI(ctx context.Context, uri string) (*Client, error) {
 	return NewClientWithOptions(ctx, options.Client().ApplyURI(uri))
 }
 
+// NewClientWithOptions every command of the client runs in a client span, unless the monitor is replaced by opts
+// 客户端的每条命令都在client span中执行, 除非opts替换了monitor
 func NewClientWithOptions(ctx context.Context, opts ...*options.ClientOptions) (*Client, error) {
+	opts = append([]*options.ClientOptions{options.Client().SetMonitor(newCommandMonitor())}, opts...)
 	client, err := mongo.Connect(ctx, opts...)
 	if err != nil {
 		return nil, err
@@ -58,8 +61,14 @@ func (c *Client) Kernel() *mongo.Client {
 	return c.wrapClient
 }
 
+// Do Execute exec in a span with the database and collection of model
+// 在带有model的库与集合信息的span中执行exec
 func (c *Client) Do(ctx context.Context, model Model, exec func(ctx context.Context, model Model) (interface{}, error)) (interface{}, error) {
-	return exec(ctx, model)
+	ctx, span := startSpan(ctx, model, "do")
+	defer span.End()
+	res, err := exec(ctx, model)
+	span.RecordError(err)
+	return res, err
 }
 
 // DoWithTransaction Execute the transaction, if the return err is nil, the transaction is automatically committed, otherwise it is rolled back
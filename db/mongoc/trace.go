@@ -0,0 +1,49 @@
+package mongoc
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/whereabouts/sdk/trace"
+	"go.mongodb.org/mongo-driver/event"
+	"sync"
+)
+
+// newCommandMonitor Run every command sent to mongodb in a client span with the database, collection and operation
+func newCommandMonitor() *event.CommandMonitor {
+	var spans sync.Map
+	end := func(requestID int64, err error) {
+		if span, ok := spans.LoadAndDelete(requestID); ok {
+			span.(*trace.Span).RecordError(err)
+			span.(*trace.Span).End()
+		}
+	}
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
+			collection, _ := evt.Command.Lookup(evt.CommandName).StringValueOK()
+			_, span := trace.Start(ctx, "mongodb "+evt.CommandName,
+				trace.WithKind(trace.KindClient),
+				trace.WithAttribute("db.system", "mongodb"),
+				trace.WithAttribute("db.name", evt.DatabaseName),
+				trace.WithAttribute("db.mongodb.collection", collection),
+				trace.WithAttribute("db.operation", evt.CommandName),
+			)
+			spans.Store(evt.RequestID, span)
+		},
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			end(evt.RequestID, nil)
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			end(evt.RequestID, errors.New(evt.Failure))
+		},
+	}
+}
+
+// startSpan Start a span of the model, the commands sent within it are its children
+func startSpan(ctx context.Context, model Model, operation string) (context.Context, *trace.Span) {
+	return trace.Start(ctx, "mongoc "+operation+" "+model.Database()+"."+model.Collection(),
+		trace.WithAttribute("db.system", "mongodb"),
+		trace.WithAttribute("db.name", model.Database()),
+		trace.WithAttribute("db.mongodb.collection", model.Collection()),
+		trace.WithAttribute("db.operation", operation),
+	)
+}
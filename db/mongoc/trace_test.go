@@ -0,0 +1,56 @@
+package mongoc
+
+import (
+	"context"
+	"errors"
+	"github.com/whereabouts/sdk/trace"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+	exporter := trace.NewMemoryExporter()
+	p := trace.NewProvider(exporter)
+	trace.SetProvider(p)
+	defer trace.SetProvider(nil)
+
+	monitor := newCommandMonitor()
+	c := &Client{}
+	m := &baseModel{database: "test", collection: "user", client: c}
+	_, err := m.Do(context.Background(), func(ctx context.Context, model Model) (interface{}, error) {
+		// simulate the events of the driver
+		command, _ := bson.Marshal(bson.D{{Key: "find", Value: "user"}})
+		monitor.Started(ctx, &event.CommandStartedEvent{
+			Command:      command,
+			DatabaseName: "test",
+			CommandName:  "find",
+			RequestID:    1,
+		})
+		monitor.Failed(ctx, &event.CommandFailedEvent{
+			CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", RequestID: 1},
+			Failure:              "unauthorized",
+		})
+		return nil, errors.New("unauthorized")
+	})
+	if err == nil {
+		t.Fatal("expect the err of exec")
+	}
+	if err = p.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := exporter.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("expect 2 spans, got %d", len(spans))
+	}
+	command, do := spans[0], spans[1]
+	if do.Name != "mongoc do test.user" || do.Attributes["db.mongodb.collection"] != "user" || do.StatusCode != trace.StatusError {
+		t.Fatalf("unexpected span of Do %+v", do)
+	}
+	if command.Kind != trace.KindClient || command.ParentSpanID != do.SpanContext.SpanID ||
+		command.Attributes["db.operation"] != "find" || command.Attributes["db.mongodb.collection"] != "user" ||
+		command.StatusMessage != "unauthorized" {
+		t.Fatalf("unexpected span of command %+v", command)
+	}
+}
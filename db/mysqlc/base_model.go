@@ -0,0 +1,310 @@
+package mysqlc
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/pkg/errors"
+	"reflect"
+	"strings"
+)
+
+type baseModel struct {
+	table  string
+	client *Client
+}
+
+// NewBaseModel The columns are generated from the struct tags of documents and results,
+// see tagName for the rules of tags
+// 列由文档与结果结构体的标签生成, 标签规则见tagName
+func NewBaseModel(client *Client, table string) *baseModel {
+	return &baseModel{
+		table:  table,
+		client: client,
+	}
+}
+
+func (m *baseModel) Table() string {
+	return m.table
+}
+
+func (m *baseModel) Kernel() *sql.DB {
+	return m.client.Kernel()
+}
+
+func (m *baseModel) Do(ctx context.Context, exec func(ctx context.Context, model Model) (interface{}, error)) (interface{}, error) {
+	return m.client.Do(ctx, m, exec)
+}
+
+func (m *baseModel) DoWithTransaction(ctx context.Context, exec func(ctx context.Context, model Model) (interface{}, error)) (interface{}, error) {
+	return m.client.DoWithTransaction(ctx, m, exec)
+}
+
+func (m *baseModel) InsertOne(ctx context.Context, document interface{}) (*InsertOneResult, error) {
+	columns, values, err := convert2Columns(document)
+	if err != nil {
+		return nil, err
+	}
+	res, err := m.insert(ctx, []record{{columns: columns, values: values}})
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	return &InsertOneResult{InsertedID: id}, nil
+}
+
+func (m *baseModel) InsertMany(ctx context.Context, documents []interface{}) (*InsertManyResult, error) {
+	records := make([]record, 0, len(documents))
+	for _, document := range documents {
+		columns, values, err := convert2Columns(document)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record{columns: columns, values: values})
+	}
+	return m.insertMany(ctx, records)
+}
+
+func (m *baseModel) DeleteOne(ctx context.Context, filter Filter) (*DeleteResult, error) {
+	return m.delete(ctx, filter, true)
+}
+
+func (m *baseModel) DeleteMany(ctx context.Context, filter Filter) (*DeleteResult, error) {
+	return m.delete(ctx, filter, false)
+}
+
+func (m *baseModel) FindOne(ctx context.Context, filter Filter, result interface{}, opts ...*FindOptions) error {
+	return m.findOne(ctx, filter, result, opts...)
+}
+
+func (m *baseModel) FindMany(ctx context.Context, filter Filter, results interface{}, opts ...*FindOptions) error {
+	return m.findMany(ctx, filter, results, opts...)
+}
+
+func (m *baseModel) UpdateOne(ctx context.Context, filter Filter, update interface{}) (*UpdateResult, error) {
+	columns, values, err := convert2Columns(update)
+	if err != nil {
+		return nil, err
+	}
+	return m.update(ctx, filter, record{columns: columns, values: values}, true)
+}
+
+func (m *baseModel) UpdateMany(ctx context.Context, filter Filter, update interface{}) (*UpdateResult, error) {
+	columns, values, err := convert2Columns(update)
+	if err != nil {
+		return nil, err
+	}
+	return m.update(ctx, filter, record{columns: columns, values: values}, false)
+}
+
+func (m *baseModel) Count(ctx context.Context, filter Filter) (int64, error) {
+	clause, args := where(filter)
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s%s", quote(m.table), clause)
+	var count int64
+	if err := m.client.executor(ctx).QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// record The columns and values of a row to insert or update
+type record struct {
+	columns []string
+	values  []interface{}
+}
+
+// set Put the value of the column, replace it if the column exists
+func (r *record) set(column string, value interface{}) {
+	for i, c := range r.columns {
+		if c == column {
+			r.values[i] = value
+			return
+		}
+	}
+	r.columns = append(r.columns, column)
+	r.values = append(r.values, value)
+}
+
+// placeholder Expr will be written into sql directly, other values use "?"
+func placeholder(value interface{}) (string, []interface{}) {
+	if expr, ok := value.(condition); ok {
+		return expr.Build()
+	}
+	return "?", []interface{}{value}
+}
+
+func (m *baseModel) insertMany(ctx context.Context, records []record) (*InsertManyResult, error) {
+	if len(records) == 0 {
+		return &InsertManyResult{}, nil
+	}
+	res, err := m.insert(ctx, records)
+	if err != nil {
+		return nil, err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	return &InsertManyResult{InsertedCount: count, FirstInsertedID: id}, nil
+}
+
+// insert Insert the records in one statement, the columns are the union of all records,
+// and the missing columns of a record use DEFAULT
+// 以一条语句插入所有记录, 列为所有记录的并集, 记录缺少的列使用DEFAULT
+func (m *baseModel) insert(ctx context.Context, records []record) (sql.Result, error) {
+	columns := make([]string, 0)
+	positions := make(map[string]int)
+	for _, r := range records {
+		for _, column := range r.columns {
+			if _, ok := positions[column]; !ok {
+				positions[column] = len(columns)
+				columns = append(columns, column)
+			}
+		}
+	}
+	if len(columns) == 0 {
+		return nil, errors.New("mysqlc: nothing to insert, no column found")
+	}
+
+	quoted := make([]string, 0, len(columns))
+	for _, column := range columns {
+		quoted = append(quoted, quote(column))
+	}
+	rows := make([]string, 0, len(records))
+	args := make([]interface{}, 0, len(records)*len(columns))
+	for _, r := range records {
+		holders := make([]string, len(columns))
+		for i := range holders {
+			holders[i] = "DEFAULT"
+		}
+		values := make([][]interface{}, len(columns))
+		for i, column := range r.columns {
+			holders[positions[column]], values[positions[column]] = placeholder(r.values[i])
+		}
+		for _, v := range values {
+			args = append(args, v...)
+		}
+		rows = append(rows, "("+strings.Join(holders, ", ")+")")
+	}
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", quote(m.table), strings.Join(quoted, ", "), strings.Join(rows, ", "))
+	return m.client.executor(ctx).ExecContext(ctx, query, args...)
+}
+
+func (m *baseModel) update(ctx context.Context, filter Filter, r record, one bool) (*UpdateResult, error) {
+	if len(r.columns) == 0 {
+		return nil, errors.New("mysqlc: nothing to update, no column found")
+	}
+	sets := make([]string, 0, len(r.columns))
+	args := make([]interface{}, 0, len(r.columns))
+	for i, column := range r.columns {
+		holder, values := placeholder(r.values[i])
+		sets = append(sets, quote(column)+" = "+holder)
+		args = append(args, values...)
+	}
+	clause, whereArgs := where(filter)
+	query := fmt.Sprintf("UPDATE %s SET %s%s", quote(m.table), strings.Join(sets, ", "), clause)
+	if one {
+		query += " LIMIT 1"
+	}
+	res, err := m.client.executor(ctx).ExecContext(ctx, query, append(args, whereArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	return &UpdateResult{ModifiedCount: count}, nil
+}
+
+func (m *baseModel) delete(ctx context.Context, filter Filter, one bool) (*DeleteResult, error) {
+	clause, args := where(filter)
+	query := fmt.Sprintf("DELETE FROM %s%s", quote(m.table), clause)
+	if one {
+		query += " LIMIT 1"
+	}
+	res, err := m.client.executor(ctx).ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	return &DeleteResult{DeletedCount: count}, nil
+}
+
+func (m *baseModel) query(ctx context.Context, elemType reflect.Type, filter Filter, opts *FindOptions) (*sql.Rows, error) {
+	clause, args := where(filter)
+	query := fmt.Sprintf("SELECT %s FROM %s%s%s%s",
+		selectColumns(elemType, opts.Fields), quote(m.table), clause, opts.orderBy(), opts.limit())
+	return m.client.executor(ctx).QueryContext(ctx, query, args...)
+}
+
+func (m *baseModel) findOne(ctx context.Context, filter Filter, result interface{}, opts ...*FindOptions) error {
+	elemType, err := resultType(result)
+	if err != nil {
+		return err
+	}
+	rows, err := m.query(ctx, elemType, filter, mergeFindOptions(opts...).SetLimit(1))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return err
+		}
+		return ErrNoRows
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	if err = scanRow(rows, columns, reflect.ValueOf(result).Elem()); err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+func (m *baseModel) findMany(ctx context.Context, filter Filter, results interface{}, opts ...*FindOptions) error {
+	elemType, err := resultsType(results)
+	if err != nil {
+		return err
+	}
+	rows, err := m.query(ctx, elemType, filter, mergeFindOptions(opts...))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	sliceV := reflect.ValueOf(results).Elem()
+	isPtr := sliceV.Type().Elem().Kind() == reflect.Ptr
+	list := reflect.MakeSlice(sliceV.Type(), 0, 0)
+	for rows.Next() {
+		elem := reflect.New(elemType)
+		if err = scanRow(rows, columns, elem.Elem()); err != nil {
+			return err
+		}
+		if isPtr {
+			list = reflect.Append(list, elem)
+		} else {
+			list = reflect.Append(list, elem.Elem())
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	sliceV.Set(list)
+	return nil
+}
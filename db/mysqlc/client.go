@@ -0,0 +1,140 @@
+package mysqlc
+
+import (
+	"context"
+	"database/sql"
+	"github.com/pkg/errors"
+	"time"
+)
+
+type wrapDB = sql.DB
+
+type Client struct {
+	*wrapDB
+}
+
+// NewClient If only one db is used, it is recommended to use: NewGlobalClient
+// 若只使用到了一个库，推荐使用: NewGlobalClient
+func NewClient(ctx context.Context, config Config) (*Client, error) {
+	db, err := sql.Open(config.driver(), config.Convert2DSN())
+	if err != nil {
+		return nil, err
+	}
+
+	maxOpenConns := defaultMaxOpenConns
+	if config.MaxOpenConns != 0 {
+		maxOpenConns = config.MaxOpenConns
+	}
+	db.SetMaxOpenConns(maxOpenConns)
+
+	maxIdleConns := defaultMaxIdleConns
+	if config.MaxIdleConns != 0 {
+		maxIdleConns = config.MaxIdleConns
+	}
+	db.SetMaxIdleConns(maxIdleConns)
+
+	connMaxLifetime := defaultConnMaxLifetime
+	if config.ConnMaxLifetime != 0 {
+		connMaxLifetime = time.Duration(config.ConnMaxLifetime) * time.Second
+	}
+	db.SetConnMaxLifetime(connMaxLifetime)
+
+	connMaxIdleTime := defaultConnMaxIdleTime
+	if config.ConnMaxIdleTime != 0 {
+		connMaxIdleTime = time.Duration(config.ConnMaxIdleTime) * time.Second
+	}
+	db.SetConnMaxIdleTime(connMaxIdleTime)
+
+	if err = db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return &Client{wrapDB: db}, nil
+}
+
+// NewClientWithDSN dsn format: username:password@tcp(127.0.0.1:3306)/database?charset=utf8mb4&parseTime=true
+func NewClientWithDSN(ctx context.Context, dsn string) (*Client, error) {
+	return NewClient(ctx, Config{DSN: dsn})
+}
+
+func NewClientWithOptions(ctx context.Context, opts ...Option) (*Client, error) {
+	config := newConfig(opts...)
+	return NewClient(ctx, config)
+}
+
+var gClient *Client
+
+// NewGlobalClient If there is only one MySQL, you can select the global client
+func NewGlobalClient(ctx context.Context, config Config) (*Client, error) {
+	var err error
+	if gClient, err = NewClient(ctx, config); err != nil {
+		return nil, err
+	}
+	return gClient, nil
+}
+
+func GetGlobalClient() *Client {
+	return gClient
+}
+
+func (c *Client) Kernel() *sql.DB {
+	return c.wrapDB
+}
+
+type executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type txKey struct{}
+
+// TxFromContext Get the transaction opened by DoWithTransaction from ctx, used to execute raw sql in the transaction
+// 从ctx中获取DoWithTransaction开启的事务, 用于在事务中执行原生sql
+func TxFromContext(ctx context.Context) (*sql.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(*sql.Tx)
+	return tx, ok
+}
+
+// executor Use the transaction in ctx first, otherwise use the db
+func (c *Client) executor(ctx context.Context) executor {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+	return c.wrapDB
+}
+
+func (c *Client) Do(ctx context.Context, model Model, exec func(ctx context.Context, model Model) (interface{}, error)) (interface{}, error) {
+	return exec(ctx, model)
+}
+
+// DoWithTransaction Execute the transaction, if the return err is nil, the transaction is automatically committed, otherwise it is rolled back.
+// The model operations must use the ctx passed to exec to run in the transaction, nested calls join the outer transaction.
+// 执行事务, 如果返回err为nil，则事务自动提交, 否则回滚. model操作须使用传给exec的ctx才能在事务中执行, 嵌套调用将加入外层事务
+func (c *Client) DoWithTransaction(ctx context.Context, model Model, exec func(ctx context.Context, model Model) (interface{}, error)) (result interface{}, err error) {
+	if _, ok := TxFromContext(ctx); ok {
+		return exec(ctx, model)
+	}
+	tx, err := c.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+	result, err = exec(context.WithValue(ctx, txKey{}, tx), model)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return result, errors.Wrapf(err, "rollback err: %v", rollbackErr)
+		}
+		return result, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,212 @@
+package mysqlc
+
+import (
+	"fmt"
+	"github.com/go-sql-driver/mysql"
+	"github.com/whereabouts/sdk/utils/stringer"
+	"time"
+)
+
+const (
+	defaultDriver          = "mysql"
+	defaultHost            = "127.0.0.1"
+	defaultPort            = 3306
+	defaultCharset         = "utf8mb4"
+	defaultMaxOpenConns    = 50
+	defaultMaxIdleConns    = 10
+	defaultConnMaxLifetime = time.Duration(30) * time.Minute
+	defaultConnMaxIdleTime = time.Duration(10) * time.Minute
+)
+
+type Config struct {
+	// Driver the name of the registered database/sql driver, default "mysql"
+	// 注册到database/sql的驱动名称, 默认"mysql"
+	Driver string `mapstructure:"driver" json:"driver"`
+	// DSN If this item is configured, it will be used directly and the connection fields below will be ignored,
+	// format: username:password@tcp(127.0.0.1:3306)/database?charset=utf8mb4&parseTime=true
+	// 配置了DSN将直接使用, 忽略下方的连接字段
+	DSN string `mapstructure:"dsn" json:"dsn"`
+
+	Host     string `mapstructure:"host" json:"host"`
+	Port     int    `mapstructure:"port" json:"port"`
+	Username string `mapstructure:"username" json:"username"`
+	Password string `mapstructure:"password" json:"password"`
+	Database string `mapstructure:"database" json:"database"`
+	// Charset default utf8mb4
+	Charset string `mapstructure:"charset" json:"charset"`
+	// Params Other connection parameters appended to the DSN, such as "tls": "skip-verify"
+	// 追加到DSN的其他连接参数
+	Params map[string]string `mapstructure:"params" json:"params"`
+
+	// 连接超时秒数
+	DialTimeout int `mapstructure:"dial_timeout" json:"dial_timeout"`
+	// 读超时秒数
+	ReadTimeout int `mapstructure:"read_timeout" json:"read_timeout"`
+	// 写超时秒数
+	WriteTimeout int `mapstructure:"write_timeout" json:"write_timeout"`
+
+	// 连接池最大连接数, default 50
+	MaxOpenConns int `mapstructure:"max_open_conns" json:"max_open_conns"`
+	// 连接池最大空闲连接数, default 10
+	MaxIdleConns int `mapstructure:"max_idle_conns" json:"max_idle_conns"`
+	// 连接可复用的最大时间（秒）, default 30 minutes
+	ConnMaxLifetime int `mapstructure:"conn_max_lifetime" json:"conn_max_lifetime"`
+	// 连接最大空闲时间（秒）, default 10 minutes
+	ConnMaxIdleTime int `mapstructure:"conn_max_idle_time" json:"conn_max_idle_time"`
+}
+
+func (c Config) driver() string {
+	if stringer.NotEmpty(c.Driver) {
+		return c.Driver
+	}
+	return defaultDriver
+}
+
+// Convert2DSN build the data source name from the connection fields, if DSN is configured, return it directly
+// 根据连接字段构建DSN, 若配置了DSN则直接返回
+func (c Config) Convert2DSN() string {
+	if stringer.NotEmpty(c.DSN) {
+		return c.DSN
+	}
+	conf := mysql.NewConfig()
+	conf.User = c.Username
+	conf.Passwd = c.Password
+	conf.Net = "tcp"
+	host, port := defaultHost, defaultPort
+	if stringer.NotEmpty(c.Host) {
+		host = c.Host
+	}
+	if c.Port != 0 {
+		port = c.Port
+	}
+	conf.Addr = fmt.Sprintf("%s:%d", host, port)
+	conf.DBName = c.Database
+	conf.ParseTime = true
+	conf.Loc = time.Local
+
+	charset := defaultCharset
+	if stringer.NotEmpty(c.Charset) {
+		charset = c.Charset
+	}
+	conf.Params = map[string]string{"charset": charset}
+	for k, v := range c.Params {
+		conf.Params[k] = v
+	}
+
+	if c.DialTimeout != 0 {
+		conf.Timeout = time.Duration(c.DialTimeout) * time.Second
+	}
+	if c.ReadTimeout != 0 {
+		conf.ReadTimeout = time.Duration(c.ReadTimeout) * time.Second
+	}
+	if c.WriteTimeout != 0 {
+		conf.WriteTimeout = time.Duration(c.WriteTimeout) * time.Second
+	}
+	return conf.FormatDSN()
+}
+
+type Option func(config *Config)
+
+func newConfig(options ...Option) Config {
+	config := Config{}
+	for _, option := range options {
+		option(&config)
+	}
+	return config
+}
+
+func WithDriver(driver string) Option {
+	return func(config *Config) {
+		config.Driver = driver
+	}
+}
+
+func WithDSN(dsn string) Option {
+	return func(config *Config) {
+		config.DSN = dsn
+	}
+}
+
+func WithHost(host string) Option {
+	return func(config *Config) {
+		config.Host = host
+	}
+}
+
+func WithPort(port int) Option {
+	return func(config *Config) {
+		config.Port = port
+	}
+}
+
+func WithUsername(username string) Option {
+	return func(config *Config) {
+		config.Username = username
+	}
+}
+
+func WithPassword(password string) Option {
+	return func(config *Config) {
+		config.Password = password
+	}
+}
+
+func WithDatabase(database string) Option {
+	return func(config *Config) {
+		config.Database = database
+	}
+}
+
+func WithCharset(charset string) Option {
+	return func(config *Config) {
+		config.Charset = charset
+	}
+}
+
+func WithParams(params map[string]string) Option {
+	return func(config *Config) {
+		config.Params = params
+	}
+}
+
+func WithDialTimeout(dialTimeout int) Option {
+	return func(config *Config) {
+		config.DialTimeout = dialTimeout
+	}
+}
+
+func WithReadTimeout(readTimeout int) Option {
+	return func(config *Config) {
+		config.ReadTimeout = readTimeout
+	}
+}
+
+func WithWriteTimeout(writeTimeout int) Option {
+	return func(config *Config) {
+		config.WriteTimeout = writeTimeout
+	}
+}
+
+func WithMaxOpenConns(maxOpenConns int) Option {
+	return func(config *Config) {
+		config.MaxOpenConns = maxOpenConns
+	}
+}
+
+func WithMaxIdleConns(maxIdleConns int) Option {
+	return func(config *Config) {
+		config.MaxIdleConns = maxIdleConns
+	}
+}
+
+func WithConnMaxLifetime(connMaxLifetime int) Option {
+	return func(config *Config) {
+		config.ConnMaxLifetime = connMaxLifetime
+	}
+}
+
+func WithConnMaxIdleTime(connMaxIdleTime int) Option {
+	return func(config *Config) {
+		config.ConnMaxIdleTime = connMaxIdleTime
+	}
+}
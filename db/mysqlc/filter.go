@@ -0,0 +1,188 @@
+package mysqlc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Filter A condition used to build the WHERE clause of sql, Build returns the clause with "?" placeholders and its args
+// 用于构建sql的WHERE子句的条件, Build返回带"?"占位符的子句及其参数
+type Filter interface {
+	Build() (clause string, args []interface{})
+}
+
+// FilterAll An empty filter, matching all rows
+var FilterAll Filter = And()
+
+// M A column-value map, used as a filter it means all columns are equal to the values,
+// and used as an update it means set the columns to the values
+// 列名与值的映射, 作为过滤条件时表示所有列等于对应的值, 作为更新时表示将列设置为对应的值
+type M map[string]interface{}
+
+func (m M) Build() (string, []interface{}) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	filters := make([]Filter, 0, len(keys))
+	for _, k := range keys {
+		filters = append(filters, Eq(k, m[k]))
+	}
+	return And(filters...).Build()
+}
+
+type condition struct {
+	clause string
+	args   []interface{}
+}
+
+func (c condition) Build() (string, []interface{}) {
+	return c.clause, c.args
+}
+
+func compare(column string, op string, value interface{}) Filter {
+	return condition{clause: fmt.Sprintf("%s %s ?", quote(column), op), args: []interface{}{value}}
+}
+
+func Eq(column string, value interface{}) Filter {
+	if value == nil {
+		return IsNull(column)
+	}
+	return compare(column, "=", value)
+}
+
+func Ne(column string, value interface{}) Filter {
+	if value == nil {
+		return IsNotNull(column)
+	}
+	return compare(column, "<>", value)
+}
+
+func Gt(column string, value interface{}) Filter {
+	return compare(column, ">", value)
+}
+
+func Gte(column string, value interface{}) Filter {
+	return compare(column, ">=", value)
+}
+
+func Lt(column string, value interface{}) Filter {
+	return compare(column, "<", value)
+}
+
+func Lte(column string, value interface{}) Filter {
+	return compare(column, "<=", value)
+}
+
+// Like example: Like("name", "%Korbin%")
+func Like(column string, pattern string) Filter {
+	return compare(column, "LIKE", pattern)
+}
+
+func NotLike(column string, pattern string) Filter {
+	return compare(column, "NOT LIKE", pattern)
+}
+
+// In If values is empty, the filter matches nothing
+// 若values为空, 则不匹配任何行
+func In(column string, values ...interface{}) Filter {
+	return in(column, "IN", "1 = 0", values)
+}
+
+// NotIn If values is empty, the filter matches all rows
+// 若values为空, 则匹配所有行
+func NotIn(column string, values ...interface{}) Filter {
+	return in(column, "NOT IN", "1 = 1", values)
+}
+
+func in(column string, op string, empty string, values []interface{}) Filter {
+	if len(values) == 0 {
+		return condition{clause: empty}
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+	return condition{clause: fmt.Sprintf("%s %s (%s)", quote(column), op, placeholders), args: values}
+}
+
+func Between(column string, from interface{}, to interface{}) Filter {
+	return condition{clause: fmt.Sprintf("%s BETWEEN ? AND ?", quote(column)), args: []interface{}{from, to}}
+}
+
+func IsNull(column string) Filter {
+	return condition{clause: fmt.Sprintf("%s IS NULL", quote(column))}
+}
+
+func IsNotNull(column string) Filter {
+	return condition{clause: fmt.Sprintf("%s IS NOT NULL", quote(column))}
+}
+
+// And Join the filters with AND, empty filters will be ignored, and matches all rows if there is no filter
+// 以AND连接条件, 空条件将被忽略, 没有条件时匹配所有行
+func And(filters ...Filter) Filter {
+	return join("AND", filters)
+}
+
+// Or Join the filters with OR, empty filters will be ignored, and matches all rows if there is no filter
+// 以OR连接条件, 空条件将被忽略, 没有条件时匹配所有行
+func Or(filters ...Filter) Filter {
+	return join("OR", filters)
+}
+
+func join(op string, filters []Filter) Filter {
+	clauses := make([]string, 0, len(filters))
+	args := make([]interface{}, 0)
+	for _, filter := range filters {
+		if filter == nil {
+			continue
+		}
+		clause, filterArgs := filter.Build()
+		if clause == "" {
+			continue
+		}
+		clauses = append(clauses, clause)
+		args = append(args, filterArgs...)
+	}
+	switch len(clauses) {
+	case 0:
+		return condition{}
+	case 1:
+		return condition{clause: clauses[0], args: args}
+	}
+	return condition{clause: "(" + strings.Join(clauses, " "+op+" ") + ")", args: args}
+}
+
+func Not(filter Filter) Filter {
+	clause, args := filter.Build()
+	if clause == "" {
+		return condition{clause: "1 = 0"}
+	}
+	return condition{clause: fmt.Sprintf("NOT (%s)", clause), args: args}
+}
+
+// Expr Raw sql expression, can be used as a filter or an update value,
+// example: Expr("`age` > `min_age`"), M{"age": Expr("`age` + ?", 1)}
+// 原生sql表达式, 可作为过滤条件或更新的值
+func Expr(clause string, args ...interface{}) Filter {
+	return condition{clause: clause, args: args}
+}
+
+// quote Quote the column name with backticks, "table.column" will be quoted as `table`.`column`
+func quote(column string) string {
+	parts := strings.Split(column, ".")
+	for i, part := range parts {
+		parts[i] = "`" + strings.Replace(part, "`", "``", -1) + "`"
+	}
+	return strings.Join(parts, ".")
+}
+
+func where(filter Filter) (string, []interface{}) {
+	if filter == nil {
+		return "", nil
+	}
+	clause, args := filter.Build()
+	if clause == "" {
+		return "", nil
+	}
+	return " WHERE " + clause, args
+}
@@ -0,0 +1,134 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/whereabouts/sdk/cli/command"
+	"github.com/whereabouts/sdk/db/mysqlc"
+	"io/fs"
+	"os"
+	"strconv"
+	"text/tabwriter"
+)
+
+const (
+	defaultDir = "migrations"
+
+	flagDir   = "dir"
+	flagTable = "table"
+	flagSteps = "steps"
+)
+
+// Connector Create the client used by the migrate command, usually from the flags or config of the service
+// 创建migrate命令使用的客户端, 通常根据服务的flag或配置创建
+type Connector func(v command.Value) (*mysqlc.Client, error)
+
+// NewCommand Create the "migrate" command with subcommands up, down, status and force, which can be added to cli.App directly.
+// The migrations are loaded from source, or from the "--dir" flag if it is set or source is nil.
+// If connect is nil, the global client of mysqlc is used.
+// 创建带有up, down, status, force子命令的"migrate"命令, 可直接添加到cli.App.
+// 迁移文件从source加载, 若设置了"--dir"或source为nil则从该目录加载. connect为nil时使用mysqlc的全局客户端
+func NewCommand(source fs.FS, connect Connector, options ...Option) *command.Command {
+	newMigrator := func(v command.Value) (*Migrator, error) {
+		src := source
+		if v.IsSet(flagDir) || src == nil {
+			src = os.DirFS(v.String(flagDir))
+		}
+		client, err := connectClient(v, connect)
+		if err != nil {
+			return nil, err
+		}
+		config := newConfig(options...)
+		if v.IsSet(flagTable) {
+			config.Table = v.String(flagTable)
+		}
+		return NewWithConfig(client, src, config), nil
+	}
+
+	up := withCommonFlags(command.NewCommand(
+		command.WithName("up"),
+		command.WithUsage("apply all pending migrations"),
+	)).WithAction(func(v command.Value) error {
+		m, err := newMigrator(v)
+		if err != nil {
+			return err
+		}
+		return m.Up(context.Background())
+	})
+
+	down := withCommonFlags(command.NewCommand(
+		command.WithName("down"),
+		command.WithUsage("roll back the last applied migrations"),
+	)).WithFlagInt(flagSteps, 1, "the number of migrations to roll back", false).WithAction(func(v command.Value) error {
+		m, err := newMigrator(v)
+		if err != nil {
+			return err
+		}
+		return m.Down(context.Background(), v.Int(flagSteps))
+	})
+
+	status := withCommonFlags(command.NewCommand(
+		command.WithName("status"),
+		command.WithUsage("show the state of all migrations"),
+	)).WithAction(func(v command.Value) error {
+		m, err := newMigrator(v)
+		if err != nil {
+			return err
+		}
+		statuses, err := m.Status(context.Background())
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(v.Kernel().App.Writer, 0, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED\tDIRTY\tAPPLIED AT")
+		for _, s := range statuses {
+			appliedAt := "-"
+			if s.Applied {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			_, _ = fmt.Fprintf(w, "%d\t%s\t%t\t%t\t%s\n", s.Version, s.Name, s.Applied, s.Dirty, appliedAt)
+		}
+		return w.Flush()
+	})
+
+	force := withCommonFlags(command.NewCommand(
+		command.WithName("force"),
+		command.WithUsage("set the version without running migrations, usually to clear the dirty state"),
+		command.WithArgsUsage("<version>"),
+	)).WithAction(func(v command.Value) error {
+		if v.NArg() != 1 {
+			return errors.New("force requires exactly one argument <version>")
+		}
+		version, err := strconv.ParseUint(v.Args().First(), 10, 64)
+		if err != nil {
+			return errors.Wrapf(err, "invalid version '%s'", v.Args().First())
+		}
+		m, err := newMigrator(v)
+		if err != nil {
+			return err
+		}
+		return m.Force(context.Background(), version)
+	})
+
+	return command.NewCommand(
+		command.WithName("migrate"),
+		command.WithUsage("manage the versioned schema migrations of mysql"),
+	).AddSubCommand(up).AddSubCommand(down).AddSubCommand(status).AddSubCommand(force)
+}
+
+func withCommonFlags(cmd *command.Command) *command.Command {
+	return cmd.
+		WithFlagString(flagDir, defaultDir, "the directory of the migration files", false).
+		WithFlagString(flagTable, defaultTable, "the bookkeeping table of applied versions", false)
+}
+
+func connectClient(v command.Value, connect Connector) (*mysqlc.Client, error) {
+	if connect != nil {
+		return connect(v)
+	}
+	if client := mysqlc.GetGlobalClient(); client != nil {
+		return client, nil
+	}
+	return nil, errors.New("mysqlc global client is not initialized")
+}
@@ -0,0 +1,51 @@
+package migrate
+
+const (
+	defaultTable       = "schema_migrations"
+	defaultLockName    = "mysqlc_migrate"
+	defaultLockTimeout = 10
+)
+
+type Config struct {
+	// Table the bookkeeping table of applied versions, default "schema_migrations"
+	// 记录已执行版本的表, 默认"schema_migrations"
+	Table string `mapstructure:"table" json:"table"`
+	// LockName the name of the MySQL named lock used to prevent concurrent runs, default "mysqlc_migrate"
+	// 用于防止并发执行的MySQL命名锁名称, 默认"mysqlc_migrate"
+	LockName string `mapstructure:"lock_name" json:"lock_name"`
+	// LockTimeout the seconds to wait for the lock, default 10 seconds
+	// 等待锁的秒数, 默认10秒
+	LockTimeout int `mapstructure:"lock_timeout" json:"lock_timeout"`
+}
+
+type Option func(config *Config)
+
+func newConfig(options ...Option) Config {
+	config := Config{
+		Table:       defaultTable,
+		LockName:    defaultLockName,
+		LockTimeout: defaultLockTimeout,
+	}
+	for _, option := range options {
+		option(&config)
+	}
+	return config
+}
+
+func WithTable(table string) Option {
+	return func(config *Config) {
+		config.Table = table
+	}
+}
+
+func WithLockName(lockName string) Option {
+	return func(config *Config) {
+		config.LockName = lockName
+	}
+}
+
+func WithLockTimeout(lockTimeout int) Option {
+	return func(config *Config) {
+		config.LockTimeout = lockTimeout
+	}
+}
@@ -0,0 +1,182 @@
+package migrate
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/whereabouts/sdk/db/mysqlc"
+	"reflect"
+	"regexp"
+	"testing"
+	"testing/fstest"
+)
+
+var testSource = fstest.MapFS{
+	"1_create_user.up.sql":   {Data: []byte("CREATE TABLE `user` (`id` INT); -- the user table\nINSERT INTO `user` VALUES (1);")},
+	"1_create_user.down.sql": {Data: []byte("DROP TABLE `user`;")},
+	"2_add_name.up.sql":      {Data: []byte("ALTER TABLE `user` ADD `name` VARCHAR(32) DEFAULT 'a;b';")},
+	"README.md":              {Data: []byte("ignored")},
+}
+
+func newMockClient(t *testing.T, dsn string) (*mysqlc.Client, sqlmock.Sqlmock) {
+	_, mock, err := sqlmock.NewWithDSN(dsn, sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("create sqlmock err: %v", err)
+	}
+	mock.ExpectPing()
+	c, err := mysqlc.NewClient(context.Background(), mysqlc.Config{Driver: "sqlmock", DSN: dsn})
+	if err != nil {
+		t.Fatalf("create mysqlc client err: %v", err)
+	}
+	return c, mock
+}
+
+func q(query string) string {
+	return "^" + regexp.QuoteMeta(query) + "$"
+}
+
+func expectLock(mock sqlmock.Sqlmock, result int) {
+	mock.ExpectQuery(q("SELECT GET_LOCK(?, ?)")).WithArgs(defaultLockName, defaultLockTimeout).
+		WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(result))
+}
+
+func expectTable(mock sqlmock.Sqlmock) {
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS `schema_migrations`").WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func expectRecords(mock sqlmock.Sqlmock, rows *sqlmock.Rows) {
+	mock.ExpectQuery(q("SELECT `version`, `name`, `dirty`, `applied_at` FROM `schema_migrations` ORDER BY `version`")).WillReturnRows(rows)
+}
+
+func recordRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"version", "name", "dirty", "applied_at"})
+}
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := LoadMigrations(testSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != 2 || migrations[0].Version != 1 || migrations[1].Version != 2 {
+		t.Fatalf("unexpected migrations: %+v", migrations)
+	}
+	if migrations[0].Name != "create_user" || !migrations[0].hasDown || migrations[1].hasDown {
+		t.Fatalf("unexpected migrations: %+v %+v", migrations[0], migrations[1])
+	}
+
+	for name, source := range map[string]fstest.MapFS{
+		"bad version": {"v1_a.up.sql": {Data: []byte("SELECT 1")}},
+		"missing up":  {"1_a.down.sql": {Data: []byte("SELECT 1")}},
+		"diff names":  {"1_a.up.sql": {Data: []byte("SELECT 1")}, "1_b.down.sql": {Data: []byte("SELECT 1")}},
+	} {
+		if _, err = LoadMigrations(source); err == nil {
+			t.Fatalf("%s: expect err, got nil", name)
+		}
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := "CREATE TABLE `a;b` (`id` INT); # comment;\n" +
+		"INSERT INTO t VALUES ('x;\\'y', \"z;\");\n" +
+		"/* block; comment */ UPDATE t SET a = 1 -- tail; comment\n;\n;"
+	expect := []string{
+		"CREATE TABLE `a;b` (`id` INT)",
+		"INSERT INTO t VALUES ('x;\\'y', \"z;\")",
+		"UPDATE t SET a = 1",
+	}
+	if got := splitStatements(script); !reflect.DeepEqual(got, expect) {
+		t.Fatalf("expect %q, got %q", expect, got)
+	}
+}
+
+func TestUpAndDown(t *testing.T) {
+	c, mock := newMockClient(t, "migrate_up_down")
+	m := New(c, testSource)
+
+	// version 1 is applied, only version 2 should be applied
+	expectLock(mock, 1)
+	expectTable(mock)
+	expectRecords(mock, recordRows().AddRow(1, "create_user", false, 100))
+	mock.ExpectExec(q("INSERT INTO `schema_migrations` (`version`, `name`, `dirty`, `applied_at`) VALUES (?, ?, 1, ?)")).
+		WithArgs(2, "add_name", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(q("ALTER TABLE `user` ADD `name` VARCHAR(32) DEFAULT 'a;b'")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(q("UPDATE `schema_migrations` SET `dirty` = 0 WHERE `version` = ?")).WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(q("SELECT RELEASE_LOCK(?)")).WithArgs(defaultLockName).WillReturnResult(sqlmock.NewResult(0, 0))
+	if err := m.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// version 2 has no down file
+	expectLock(mock, 1)
+	expectTable(mock)
+	expectRecords(mock, recordRows().AddRow(1, "create_user", false, 100).AddRow(2, "add_name", false, 100))
+	mock.ExpectExec(q("SELECT RELEASE_LOCK(?)")).WithArgs(defaultLockName).WillReturnResult(sqlmock.NewResult(0, 0))
+	if err := m.Down(context.Background(), 1); err == nil {
+		t.Fatal("expect err of missing down migration, got nil")
+	}
+
+	expectLock(mock, 1)
+	expectTable(mock)
+	expectRecords(mock, recordRows().AddRow(1, "create_user", false, 100))
+	mock.ExpectExec(q("UPDATE `schema_migrations` SET `dirty` = 1 WHERE `version` = ?")).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(q("DROP TABLE `user`")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(q("DELETE FROM `schema_migrations` WHERE `version` = ?")).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(q("SELECT RELEASE_LOCK(?)")).WithArgs(defaultLockName).WillReturnResult(sqlmock.NewResult(0, 0))
+	if err := m.Down(context.Background(), 5); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDirtyAndLock(t *testing.T) {
+	c, mock := newMockClient(t, "migrate_dirty_lock")
+	m := New(c, testSource)
+
+	expectLock(mock, 0)
+	if err := m.Up(context.Background()); err == nil {
+		t.Fatal("expect err of lock timeout, got nil")
+	}
+
+	expectLock(mock, 1)
+	expectTable(mock)
+	expectRecords(mock, recordRows().AddRow(1, "create_user", true, 100))
+	mock.ExpectExec(q("SELECT RELEASE_LOCK(?)")).WithArgs(defaultLockName).WillReturnResult(sqlmock.NewResult(0, 0))
+	if err := m.Up(context.Background()); err == nil {
+		t.Fatal("expect err of dirty version, got nil")
+	}
+
+	expectLock(mock, 1)
+	expectTable(mock)
+	mock.ExpectExec(q("DELETE FROM `schema_migrations` WHERE `version` > ?")).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(q("UPDATE `schema_migrations` SET `dirty` = 0")).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(q("INSERT IGNORE INTO `schema_migrations` (`version`, `name`, `dirty`, `applied_at`) VALUES (?, ?, 0, ?)")).
+		WithArgs(1, "create_user", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(q("SELECT RELEASE_LOCK(?)")).WithArgs(defaultLockName).WillReturnResult(sqlmock.NewResult(0, 0))
+	if err := m.Force(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	c, mock := newMockClient(t, "migrate_status")
+	m := New(c, testSource)
+
+	expectTable(mock)
+	expectRecords(mock, recordRows().AddRow(1, "create_user", false, 100).AddRow(9, "removed", false, 200))
+	statuses, err := m.Status(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 3 {
+		t.Fatalf("expect 3 statuses, got %+v", statuses)
+	}
+	if !statuses[0].Applied || statuses[1].Applied || statuses[2].Version != 9 || statuses[2].Name != "removed" {
+		t.Fatalf("unexpected statuses: %+v", statuses)
+	}
+}
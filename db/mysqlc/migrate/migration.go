@@ -0,0 +1,157 @@
+package migrate
+
+import (
+	"github.com/pkg/errors"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	upSuffix   = ".up.sql"
+	downSuffix = ".down.sql"
+)
+
+// Migration A versioned migration loaded from the files named "{version}_{name}.up.sql" and "{version}_{name}.down.sql",
+// the down file is optional, but the migration can not be rolled back without it.
+// example: 20210801120000_create_user.up.sql
+// 版本化的迁移, 从名为"{version}_{name}.up.sql"与"{version}_{name}.down.sql"的文件加载, down文件可选, 但缺少时无法回滚
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      string
+	Down    string
+	hasDown bool
+}
+
+// LoadMigrations Load migrations from the root directory of source, sorted by version
+// 从source的根目录加载迁移, 按版本排序
+func LoadMigrations(source fs.FS) ([]*Migration, error) {
+	entries, err := fs.ReadDir(source, ".")
+	if err != nil {
+		return nil, errors.Wrap(err, "read migration source err")
+	}
+	migrationMap := make(map[uint64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		filename := entry.Name()
+		var up bool
+		switch {
+		case strings.HasSuffix(filename, upSuffix):
+			up = true
+		case strings.HasSuffix(filename, downSuffix):
+			up = false
+		default:
+			continue
+		}
+		version, name, err := parseFilename(filename)
+		if err != nil {
+			return nil, err
+		}
+		content, err := fs.ReadFile(source, filename)
+		if err != nil {
+			return nil, errors.Wrapf(err, "read migration file %s err", filename)
+		}
+		m, ok := migrationMap[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			migrationMap[version] = m
+		}
+		if m.Name != name {
+			return nil, errors.Errorf("migration version %d has different names: %s and %s", version, m.Name, name)
+		}
+		if up {
+			if m.Up != "" {
+				return nil, errors.Errorf("duplicate up migration of version %d", version)
+			}
+			m.Up = string(content)
+		} else {
+			if m.hasDown {
+				return nil, errors.Errorf("duplicate down migration of version %d", version)
+			}
+			m.Down = string(content)
+			m.hasDown = true
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(migrationMap))
+	for _, m := range migrationMap {
+		if strings.TrimSpace(m.Up) == "" {
+			return nil, errors.Errorf("up migration of version %d is missing or empty", m.Version)
+		}
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+func parseFilename(filename string) (uint64, string, error) {
+	base := strings.TrimSuffix(strings.TrimSuffix(filename, upSuffix), downSuffix)
+	parts := strings.SplitN(base, "_", 2)
+	version, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil || version == 0 {
+		return 0, "", errors.Errorf("invalid migration filename %s, format: {version}_{name}.up.sql", filename)
+	}
+	name := ""
+	if len(parts) == 2 {
+		name = parts[1]
+	}
+	return version, name, nil
+}
+
+// splitStatements Split the sql script into statements by semicolons outside quotes and comments,
+// because the driver does not execute multiple statements by default
+// 按引号与注释之外的分号将sql脚本拆分为多条语句, 因为驱动默认不支持一次执行多条语句
+func splitStatements(script string) []string {
+	statements := make([]string, 0)
+	var current strings.Builder
+	flush := func() {
+		if stmt := strings.TrimSpace(current.String()); stmt != "" {
+			statements = append(statements, stmt)
+		}
+		current.Reset()
+	}
+	runes := []rune(script)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\'' || r == '"' || r == '`':
+			// quoted string or identifier, the backslash escapes the next char except in identifiers
+			current.WriteRune(r)
+			for i++; i < len(runes); i++ {
+				current.WriteRune(runes[i])
+				if runes[i] == '\\' && r != '`' && i+1 < len(runes) {
+					i++
+					current.WriteRune(runes[i])
+					continue
+				}
+				if runes[i] == r {
+					break
+				}
+			}
+		case r == '#' || (r == '-' && i+2 < len(runes) && runes[i+1] == '-' && (runes[i+2] == ' ' || runes[i+2] == '\t')):
+			// line comment
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			current.WriteRune('\n')
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			// block comment
+			for i += 2; i < len(runes) && !(runes[i] == '*' && i+1 < len(runes) && runes[i+1] == '/'); i++ {
+			}
+			i++
+			current.WriteRune(' ')
+		case r == ';':
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+	return statements
+}
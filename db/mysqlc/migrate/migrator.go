@@ -0,0 +1,292 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/whereabouts/sdk/db/mysqlc"
+	"github.com/whereabouts/sdk/logger"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+)
+
+type Migrator struct {
+	client *mysqlc.Client
+	source fs.FS
+	config Config
+}
+
+// Status The state of a version, the versions that are applied but not found in the source are listed at the end
+// 版本的状态, 已执行但在source中找不到的版本列在最后
+type Status struct {
+	Version   uint64
+	Name      string
+	Applied   bool
+	Dirty     bool
+	AppliedAt time.Time
+}
+
+type record struct {
+	version   uint64
+	name      string
+	dirty     bool
+	appliedAt int64
+}
+
+func New(client *mysqlc.Client, source fs.FS, options ...Option) *Migrator {
+	return NewWithConfig(client, source, newConfig(options...))
+}
+
+func NewWithConfig(client *mysqlc.Client, source fs.FS, config Config) *Migrator {
+	return &Migrator{client: client, source: source, config: config}
+}
+
+// NewWithDir Load the migration files from the local directory
+// 从本地目录加载迁移文件
+func NewWithDir(client *mysqlc.Client, dir string, options ...Option) *Migrator {
+	return New(client, os.DirFS(dir), options...)
+}
+
+// Up Apply all pending migrations in order
+// 按顺序执行所有未执行的迁移
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		migrations, records, err := m.load(ctx, conn)
+		if err != nil {
+			return err
+		}
+		applied := make(map[uint64]bool, len(records))
+		for _, r := range records {
+			applied[r.version] = true
+		}
+		for _, migration := range migrations {
+			if applied[migration.Version] {
+				continue
+			}
+			if err = m.apply(ctx, conn, migration); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Down Roll back the last n applied migrations in reverse order
+// 按倒序回滚最近执行的n个迁移
+func (m *Migrator) Down(ctx context.Context, n int) error {
+	if n <= 0 {
+		return errors.Errorf("invalid steps %d to roll back, must be positive", n)
+	}
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		migrations, records, err := m.load(ctx, conn)
+		if err != nil {
+			return err
+		}
+		migrationMap := make(map[uint64]*Migration, len(migrations))
+		for _, migration := range migrations {
+			migrationMap[migration.Version] = migration
+		}
+		for i := len(records) - 1; i >= 0 && n > 0; i, n = i-1, n-1 {
+			migration, ok := migrationMap[records[i].version]
+			if !ok {
+				return errors.Errorf("can not roll back version %d, migration not found in source", records[i].version)
+			}
+			if err = m.rollback(ctx, conn, migration); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Status List the state of all versions in the source and the bookkeeping table
+// 列出source与记录表中所有版本的状态
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	migrations, err := LoadMigrations(m.source)
+	if err != nil {
+		return nil, err
+	}
+	if err = m.ensureTable(ctx, m.client.Kernel()); err != nil {
+		return nil, err
+	}
+	records, err := m.records(ctx, m.client.Kernel())
+	if err != nil {
+		return nil, err
+	}
+	recordMap := make(map[uint64]record, len(records))
+	for _, r := range records {
+		recordMap[r.version] = r
+	}
+	statuses := make([]Status, 0, len(migrations))
+	for _, migration := range migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if r, ok := recordMap[migration.Version]; ok {
+			status.Applied = true
+			status.Dirty = r.dirty
+			status.AppliedAt = time.Unix(r.appliedAt, 0)
+			delete(recordMap, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, r := range records {
+		if _, ok := recordMap[r.version]; ok {
+			statuses = append(statuses, Status{Version: r.version, Name: r.name, Applied: true, Dirty: r.dirty, AppliedAt: time.Unix(r.appliedAt, 0)})
+		}
+	}
+	return statuses, nil
+}
+
+// Force Mark the versions up to version as applied and clean, and the versions after it as not applied,
+// without executing any migration. It is used to fix the dirty state after a failed migration has been repaired by hand,
+// version 0 means no version is applied.
+// 将version及之前的版本标记为已执行且非dirty, 之后的版本标记为未执行, 不执行任何迁移.
+// 用于手动修复失败的迁移后清除dirty状态, version为0表示没有版本被执行
+func (m *Migrator) Force(ctx context.Context, version uint64) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		migrations, err := LoadMigrations(m.source)
+		if err != nil {
+			return err
+		}
+		if err = m.ensureTable(ctx, conn); err != nil {
+			return err
+		}
+		table := quote(m.config.Table)
+		if _, err = conn.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE `version` > ?", table), version); err != nil {
+			return err
+		}
+		if _, err = conn.ExecContext(ctx, fmt.Sprintf("UPDATE %s SET `dirty` = 0", table)); err != nil {
+			return err
+		}
+		now := time.Now().Unix()
+		for _, migration := range migrations {
+			if migration.Version > version {
+				break
+			}
+			if _, err = conn.ExecContext(ctx, fmt.Sprintf("INSERT IGNORE INTO %s (`version`, `name`, `dirty`, `applied_at`) VALUES (?, ?, 0, ?)", table),
+				migration.Version, migration.Name, now); err != nil {
+				return err
+			}
+		}
+		logger.Infof("migrate: forced version %d", version)
+		return nil
+	})
+}
+
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, migration *Migration) error {
+	table := quote(m.config.Table)
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (`version`, `name`, `dirty`, `applied_at`) VALUES (?, ?, 1, ?)", table),
+		migration.Version, migration.Name, time.Now().Unix()); err != nil {
+		return err
+	}
+	for _, stmt := range splitStatements(migration.Up) {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			return errors.Wrapf(err, "migrate up version %d failed, the version is dirty now, fix it and force the version", migration.Version)
+		}
+	}
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("UPDATE %s SET `dirty` = 0 WHERE `version` = ?", table), migration.Version); err != nil {
+		return err
+	}
+	logger.Infof("migrate: applied version %d %s", migration.Version, migration.Name)
+	return nil
+}
+
+func (m *Migrator) rollback(ctx context.Context, conn *sql.Conn, migration *Migration) error {
+	if !migration.hasDown {
+		return errors.Errorf("can not roll back version %d, down migration not found", migration.Version)
+	}
+	table := quote(m.config.Table)
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("UPDATE %s SET `dirty` = 1 WHERE `version` = ?", table), migration.Version); err != nil {
+		return err
+	}
+	for _, stmt := range splitStatements(migration.Down) {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			return errors.Wrapf(err, "migrate down version %d failed, the version is dirty now, fix it and force the version", migration.Version)
+		}
+	}
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE `version` = ?", table), migration.Version); err != nil {
+		return err
+	}
+	logger.Infof("migrate: rolled back version %d %s", migration.Version, migration.Name)
+	return nil
+}
+
+// load Load the migrations and the applied records, and refuse to continue if any version is dirty
+func (m *Migrator) load(ctx context.Context, conn *sql.Conn) ([]*Migration, []record, error) {
+	migrations, err := LoadMigrations(m.source)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = m.ensureTable(ctx, conn); err != nil {
+		return nil, nil, err
+	}
+	records, err := m.records(ctx, conn)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, r := range records {
+		if r.dirty {
+			return nil, nil, errors.Errorf("version %d is dirty, fix it and force the version", r.version)
+		}
+	}
+	return migrations, records, nil
+}
+
+type execQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+func (m *Migrator) ensureTable(ctx context.Context, db execQuerier) error {
+	_, err := db.ExecContext(ctx, fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s ("+
+		"`version` BIGINT UNSIGNED NOT NULL PRIMARY KEY, "+
+		"`name` VARCHAR(255) NOT NULL DEFAULT '', "+
+		"`dirty` TINYINT(1) NOT NULL DEFAULT 0, "+
+		"`applied_at` BIGINT NOT NULL DEFAULT 0)", quote(m.config.Table)))
+	return errors.Wrap(err, "create migration table err")
+}
+
+func (m *Migrator) records(ctx context.Context, db execQuerier) ([]record, error) {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("SELECT `version`, `name`, `dirty`, `applied_at` FROM %s ORDER BY `version`", quote(m.config.Table)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	records := make([]record, 0)
+	for rows.Next() {
+		r := record{}
+		if err = rows.Scan(&r.version, &r.name, &r.dirty, &r.appliedAt); err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+	}
+	return records, rows.Err()
+}
+
+// withLock Run f with a MySQL named lock on a dedicated connection, so that only one migration runs at the same time
+// 在独占的连接上持有MySQL命名锁运行f, 保证同一时间只有一个迁移在执行
+func (m *Migrator) withLock(ctx context.Context, f func(conn *sql.Conn) error) error {
+	conn, err := m.client.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var locked sql.NullInt64
+	if err = conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", m.config.LockName, m.config.LockTimeout).Scan(&locked); err != nil {
+		return errors.Wrap(err, "acquire migration lock err")
+	}
+	if !locked.Valid || locked.Int64 != 1 {
+		return errors.Errorf("failed to acquire migration lock '%s' in %d seconds, another migration may be running", m.config.LockName, m.config.LockTimeout)
+	}
+	defer func() {
+		_, _ = conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", m.config.LockName)
+	}()
+	return f(conn)
+}
+
+func quote(table string) string {
+	return "`" + strings.Replace(table, "`", "``", -1) + "`"
+}
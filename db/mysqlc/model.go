@@ -0,0 +1,46 @@
+package mysqlc
+
+import (
+	"context"
+	"database/sql"
+)
+
+type Model interface {
+	Kernel() *sql.DB
+	Table() string
+	InsertOne(ctx context.Context, document interface{}) (*InsertOneResult, error)
+	InsertMany(ctx context.Context, documents []interface{}) (*InsertManyResult, error)
+	DeleteOne(ctx context.Context, filter Filter) (*DeleteResult, error)
+	DeleteMany(ctx context.Context, filter Filter) (*DeleteResult, error)
+	FindOne(ctx context.Context, filter Filter, result interface{}, opts ...*FindOptions) error
+	FindMany(ctx context.Context, filter Filter, results interface{}, opts ...*FindOptions) error
+	UpdateOne(ctx context.Context, filter Filter, update interface{}) (*UpdateResult, error)
+	UpdateMany(ctx context.Context, filter Filter, update interface{}) (*UpdateResult, error)
+	Count(ctx context.Context, filter Filter) (int64, error)
+	Do(ctx context.Context, exec func(ctx context.Context, model Model) (interface{}, error)) (interface{}, error)
+	DoWithTransaction(ctx context.Context, exec func(ctx context.Context, model Model) (interface{}, error)) (interface{}, error)
+}
+
+// ErrNoRows FindOne returns it if no row matches the filter
+var ErrNoRows = sql.ErrNoRows
+
+type InsertOneResult struct {
+	// InsertedID the auto increment id of the inserted row
+	InsertedID int64
+}
+
+type InsertManyResult struct {
+	InsertedCount int64
+	// FirstInsertedID the auto increment id of the first inserted row, which MySQL reports for a multiple-row insert
+	// 批量插入时MySQL返回的第一行的自增id
+	FirstInsertedID int64
+}
+
+type UpdateResult struct {
+	// ModifiedCount the number of rows actually changed
+	ModifiedCount int64
+}
+
+type DeleteResult struct {
+	DeletedCount int64
+}
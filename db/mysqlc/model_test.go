@@ -0,0 +1,282 @@
+package mysqlc
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"testing"
+)
+
+type User struct {
+	ID     int64  `db:"id,omitempty"`
+	Name   string `json:"name"`
+	Age    int    `json:"age"`
+	Ignore string `db:"-"`
+}
+
+func TestFilter(t *testing.T) {
+	clause, args := And(
+		Eq("name", "Korbin"),
+		Or(Gt("age", 18), In("id", 1, 2)),
+		IsNull("deleted"),
+		FilterAll,
+	).Build()
+	expect := "(`name` = ? AND (`age` > ? OR `id` IN (?, ?)) AND `deleted` IS NULL)"
+	if clause != expect {
+		t.Fatalf("expect clause %s, got %s", expect, clause)
+	}
+	if len(args) != 4 || args[0] != "Korbin" || args[1] != 18 {
+		t.Fatalf("unexpected args %v", args)
+	}
+	if clause, _ = (M{"b": 1, "a": 2}).Build(); clause != "(`a` = ? AND `b` = ?)" {
+		t.Fatalf("unexpected clause of M %s", clause)
+	}
+	if clause, _ = In("id").Build(); clause != "1 = 0" {
+		t.Fatalf("expect empty In matches nothing, got %s", clause)
+	}
+}
+
+func TestModel(t *testing.T) {
+	ctx := context.Background()
+	c, mock := newMockClient(t, "test_model")
+	defer c.Close()
+	m := NewBaseModel(c, "user")
+
+	mock.ExpectExec("INSERT INTO `user` (`name`, `age`) VALUES (?, ?)").
+		WithArgs("Korbin", 18).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	insertResult, err := m.InsertOne(ctx, User{Name: "Korbin", Age: 18})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if insertResult.InsertedID != 1 {
+		t.Fatalf("expect inserted id 1, got %d", insertResult.InsertedID)
+	}
+
+	mock.ExpectExec("INSERT INTO `user` (`id`, `name`, `age`) VALUES (?, ?, ?), (DEFAULT, ?, ?)").
+		WithArgs(5, "A", 1, "B", 2).
+		WillReturnResult(sqlmock.NewResult(5, 2))
+	insertManyResult, err := m.InsertMany(ctx, []interface{}{User{ID: 5, Name: "A", Age: 1}, &User{Name: "B", Age: 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if insertManyResult.InsertedCount != 2 || insertManyResult.FirstInsertedID != 5 {
+		t.Fatalf("unexpected insert many result %+v", insertManyResult)
+	}
+
+	mock.ExpectQuery("SELECT `id`, `name`, `age` FROM `user` WHERE `name` = ? LIMIT 1").
+		WithArgs("Korbin").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "age"}).AddRow(1, "Korbin", 18))
+	user := User{}
+	if err = m.FindOne(ctx, Eq("name", "Korbin"), &user); err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != 1 || user.Name != "Korbin" || user.Age != 18 {
+		t.Fatalf("unexpected user %+v", user)
+	}
+
+	mock.ExpectQuery("SELECT `id`, `name`, `age` FROM `user` WHERE `name` = ? LIMIT 1").
+		WithArgs("None").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "age"}))
+	if err = m.FindOne(ctx, Eq("name", "None"), &user); !errors.Is(err, ErrNoRows) {
+		t.Fatalf("expect ErrNoRows, got %v", err)
+	}
+
+	mock.ExpectQuery("SELECT `id`, `name`, `age` FROM `user` WHERE `age` >= ? ORDER BY `age` DESC LIMIT 10 OFFSET 20").
+		WithArgs(18).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "age"}).AddRow(1, "A", 20).AddRow(2, "B", 19))
+	users := make([]*User, 0)
+	opts := NewFindOptions().SetSort("age", Desc).SetLimit(10).SetSkip(20)
+	if err = m.FindMany(ctx, Gte("age", 18), &users, opts); err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 || users[1].Name != "B" {
+		t.Fatalf("unexpected users %+v", users)
+	}
+
+	mock.ExpectQuery("SELECT `name` FROM `user`").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow([]byte("A")))
+	names := make([]M, 0)
+	if err = m.FindMany(ctx, FilterAll, &names, NewFindOptions().SetFields("name")); err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 1 || names[0]["name"] != "A" {
+		t.Fatalf("unexpected names %+v", names)
+	}
+
+	mock.ExpectExec("UPDATE `user` SET `age` = `age` + ? WHERE `name` = ? LIMIT 1").
+		WithArgs(1, "Korbin").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	updateResult, err := m.UpdateOne(ctx, M{"name": "Korbin"}, M{"age": Expr("`age` + ?", 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updateResult.ModifiedCount != 1 {
+		t.Fatalf("expect modified count 1, got %d", updateResult.ModifiedCount)
+	}
+
+	mock.ExpectQuery("SELECT COUNT(*) FROM `user` WHERE `age` < ?").
+		WithArgs(18).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	count, err := m.Count(ctx, Lt("age", 18))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Fatalf("expect count 3, got %d", count)
+	}
+
+	mock.ExpectExec("DELETE FROM `user` WHERE `age` < ?").
+		WithArgs(18).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	deleteResult, err := m.DeleteMany(ctx, Lt("age", 18))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleteResult.DeletedCount != 3 {
+		t.Fatalf("expect deleted count 3, got %d", deleteResult.DeletedCount)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTransaction(t *testing.T) {
+	ctx := context.Background()
+	c, mock := newMockClient(t, "test_transaction")
+	defer c.Close()
+	m := NewBaseModel(c, "user")
+
+	// failed
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `user` SET `name` = ? WHERE `id` = ? LIMIT 1").
+		WithArgs("Hezebin1", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectRollback()
+	_, err := m.DoWithTransaction(ctx, func(ctx context.Context, model Model) (interface{}, error) {
+		if _, err := model.UpdateOne(ctx, Eq("id", 1), M{"name": "Hezebin1"}); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("if the returned err is not nil, the transaction will be rolled back")
+	})
+	if err == nil {
+		t.Fatal("expect transaction err, got nil")
+	}
+
+	// successful
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `user` SET `name` = ? WHERE `id` = ? LIMIT 1").
+		WithArgs("Hezebin2", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	res, err := m.DoWithTransaction(ctx, func(ctx context.Context, model Model) (interface{}, error) {
+		if _, ok := TxFromContext(ctx); !ok {
+			return nil, errors.New("expect transaction in ctx")
+		}
+		return model.UpdateOne(ctx, Eq("id", 1), M{"name": "Hezebin2"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.(*UpdateResult).ModifiedCount != 1 {
+		t.Fatalf("unexpected result %+v", res)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	cases := map[string]string{"UserID": "user_id", "CreateAt": "create_at", "HTTPServer": "http_server", "Name": "name"}
+	for in, expect := range cases {
+		if got := snakeCase(in); got != expect {
+			t.Errorf("snakeCase(%s) expect %s, got %s", in, expect, got)
+		}
+	}
+}
+
+type TimeUser struct {
+	ID       int64  `db:"id,omitempty"`
+	Name     string `db:"name"`
+	CreateAt int64  `db:"create_at"`
+	UpdateAt int64  `db:"update_at"`
+	DeleteAt int64  `db:"delete_at"`
+}
+
+func TestAutoTime(t *testing.T) {
+	ctx := context.Background()
+	c, mock := newMockClient(t, "test_auto_time")
+	defer c.Close()
+	m := NewAutoTimeModel(c, "user")
+
+	mock.ExpectExec("INSERT INTO `user` (`name`, `create_at`, `update_at`, `delete_at`) VALUES (?, ?, ?, ?)").
+		WithArgs("auto_time", sqlmock.AnyArg(), sqlmock.AnyArg(), 0).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	if _, err := m.InsertOne(ctx, TimeUser{Name: "auto_time"}); err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectExec("UPDATE `user` SET `name` = ?, `update_at` = ? WHERE `id` = ? LIMIT 1").
+		WithArgs("renamed", sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if _, err := m.UpdateOne(ctx, Eq("id", 1), M{"name": "renamed"}); err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectExec("DELETE FROM `user` WHERE `id` = ?").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if _, err := m.DeleteMany(ctx, Eq("id", 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSoftDelete(t *testing.T) {
+	ctx := context.Background()
+	c, mock := newMockClient(t, "test_soft_delete")
+	defer c.Close()
+	m := NewAutoTimeModel(c, "user").SetSoftDelete(true).SetDeleteTimeFieldKey("deleted_at")
+
+	mock.ExpectExec("INSERT INTO `user` (`name`, `create_at`, `update_at`, `delete_at`, `deleted_at`) VALUES (?, ?, ?, ?, ?)").
+		WithArgs("soft_delete", sqlmock.AnyArg(), sqlmock.AnyArg(), 0, 0).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	if _, err := m.InsertOne(ctx, TimeUser{Name: "soft_delete"}); err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectQuery("SELECT `id`, `name`, `create_at`, `update_at`, `delete_at` FROM `user` WHERE (`name` = ? AND `deleted_at` = ?) LIMIT 1").
+		WithArgs("soft_delete", 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "soft_delete"))
+	user := TimeUser{}
+	if err := m.FindOne(ctx, Eq("name", "soft_delete"), &user); err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectQuery("SELECT COUNT(*) FROM `user` WHERE `deleted_at` = ?").
+		WithArgs(0).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	if _, err := m.Count(ctx, FilterAll); err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectExec("UPDATE `user` SET `deleted_at` = ?, `update_at` = ? WHERE (`id` = ? AND `deleted_at` = ?) LIMIT 1").
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 1, 0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	res, err := m.DeleteOne(ctx, Eq("id", 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.DeletedCount != 1 {
+		t.Fatalf("expect deleted count 1, got %d", res.DeletedCount)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
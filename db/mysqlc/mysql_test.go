@@ -0,0 +1,77 @@
+package mysqlc
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"testing"
+)
+
+func newMockClient(t *testing.T, dsn string) (*Client, sqlmock.Sqlmock) {
+	_, mock, err := sqlmock.NewWithDSN(dsn, sqlmock.MonitorPingsOption(true), sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("create sqlmock err: %v", err)
+	}
+	mock.ExpectPing()
+	c, err := NewClient(context.Background(), Config{Driver: "sqlmock", DSN: dsn})
+	if err != nil {
+		t.Fatalf("create mysqlc client err: %v", err)
+	}
+	return c, mock
+}
+
+func TestConvert2DSN(t *testing.T) {
+	dsn := Config{
+		Username: "root",
+		Password: "root",
+		Host:     "localhost",
+		Database: "test",
+		Params:   map[string]string{"tls": "skip-verify"},
+	}.Convert2DSN()
+	expect := "root:root@tcp(localhost:3306)/test?loc=Local&parseTime=true&charset=utf8mb4&tls=skip-verify"
+	if dsn != expect {
+		t.Fatalf("expect dsn %s, got %s", expect, dsn)
+	}
+	if dsn = (Config{DSN: "raw"}).Convert2DSN(); dsn != "raw" {
+		t.Fatalf("expect raw dsn to be used directly, got %s", dsn)
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	c, mock := newMockClient(t, "test_new_client")
+	defer c.Close()
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Kernel().Stats().MaxOpenConnections != defaultMaxOpenConns {
+		t.Fatalf("expect max open conns %d, got %d", defaultMaxOpenConns, c.Kernel().Stats().MaxOpenConnections)
+	}
+}
+
+func TestNewClientPingErr(t *testing.T) {
+	_, mock, err := sqlmock.NewWithDSN("test_ping_err", sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+	if _, err = NewClient(context.Background(), Config{Driver: "sqlmock", DSN: "test_ping_err"}); err == nil {
+		t.Fatal("expect ping err, got nil")
+	}
+}
+
+func TestManager(t *testing.T) {
+	c, _ := newMockClient(t, "test_manager")
+	defer c.Close()
+	Manager().Add("test", c)
+	defer Manager().Clear()
+	if !Manager().Has("test") {
+		t.Fatal("expect client of alias 'test' exists")
+	}
+	got, err := Manager().Get("test")
+	if err != nil || got != c {
+		t.Fatalf("expect the added client, got %v, err: %v", got, err)
+	}
+	if _, err = Manager().Get("none"); err == nil {
+		t.Fatal("expect err for unknown alias")
+	}
+}
@@ -0,0 +1,101 @@
+package mysqlc
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	Asc  = 1
+	Desc = -1
+)
+
+type sortField struct {
+	column string
+	order  int
+}
+
+// FindOptions Options of FindOne and FindMany, all setters return the options itself for chaining
+// FindOne与FindMany的选项, 所有setter返回自身以便链式调用
+type FindOptions struct {
+	Fields []string
+	Limit  int64
+	Skip   int64
+	sorts  []sortField
+}
+
+func NewFindOptions() *FindOptions {
+	return &FindOptions{}
+}
+
+// SetFields Specify the columns to select, default are the columns mapped from the result struct
+// 指定查询的列, 默认为结果结构体映射的列
+func (opts *FindOptions) SetFields(fields ...string) *FindOptions {
+	opts.Fields = fields
+	return opts
+}
+
+// SetSort order must be Asc or Desc, can be called multiple times to sort by multiple columns
+// order为Asc或Desc, 可多次调用以按多列排序
+func (opts *FindOptions) SetSort(column string, order int) *FindOptions {
+	opts.sorts = append(opts.sorts, sortField{column: column, order: order})
+	return opts
+}
+
+func (opts *FindOptions) SetLimit(limit int64) *FindOptions {
+	opts.Limit = limit
+	return opts
+}
+
+func (opts *FindOptions) SetSkip(skip int64) *FindOptions {
+	opts.Skip = skip
+	return opts
+}
+
+func mergeFindOptions(opts ...*FindOptions) *FindOptions {
+	merged := NewFindOptions()
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if len(opt.Fields) > 0 {
+			merged.Fields = opt.Fields
+		}
+		if opt.Limit != 0 {
+			merged.Limit = opt.Limit
+		}
+		if opt.Skip != 0 {
+			merged.Skip = opt.Skip
+		}
+		merged.sorts = append(merged.sorts, opt.sorts...)
+	}
+	return merged
+}
+
+func (opts *FindOptions) orderBy() string {
+	if len(opts.sorts) == 0 {
+		return ""
+	}
+	orders := make([]string, 0, len(opts.sorts))
+	for _, s := range opts.sorts {
+		if s.order == Desc {
+			orders = append(orders, quote(s.column)+" DESC")
+		} else {
+			orders = append(orders, quote(s.column)+" ASC")
+		}
+	}
+	return " ORDER BY " + strings.Join(orders, ", ")
+}
+
+// limit MySQL requires LIMIT when OFFSET is used, so use the max row count if only skip is set
+func (opts *FindOptions) limit() string {
+	switch {
+	case opts.Limit > 0 && opts.Skip > 0:
+		return fmt.Sprintf(" LIMIT %d OFFSET %d", opts.Limit, opts.Skip)
+	case opts.Limit > 0:
+		return fmt.Sprintf(" LIMIT %d", opts.Limit)
+	case opts.Skip > 0:
+		return fmt.Sprintf(" LIMIT 18446744073709551615 OFFSET %d", opts.Skip)
+	}
+	return ""
+}
@@ -0,0 +1,239 @@
+package mysqlc
+
+import (
+	"database/sql"
+	"github.com/pkg/errors"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// tagName The column of a struct field is taken from the tag "db" first, then the tag "json",
+// and finally the snake case of the field name, "-" means ignoring the field,
+// and the option "omitempty" means ignoring the field when it is zero value on insert and update.
+// example: ID int64 `db:"id,omitempty"`
+// 结构体字段的列名依次取自标签"db"、标签"json"、字段名的蛇形命名, "-"表示忽略该字段,
+// 选项"omitempty"表示插入和更新时忽略零值字段
+const tagName = "db"
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	schemaCache = new(sync.Map)
+)
+
+type fieldInfo struct {
+	index     []int
+	column    string
+	omitempty bool
+}
+
+type schema struct {
+	fields   []fieldInfo
+	columns  []string
+	byColumn map[string]fieldInfo
+}
+
+func schemaOf(t reflect.Type) *schema {
+	if s, ok := schemaCache.Load(t); ok {
+		return s.(*schema)
+	}
+	s := &schema{byColumn: make(map[string]fieldInfo)}
+	parseFields(t, nil, s)
+	schemaCache.Store(t, s)
+	return s
+}
+
+func parseFields(t reflect.Type, index []int, s *schema) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts := parseTag(f)
+		if name == "-" {
+			continue
+		}
+		fieldIndex := append(append(make([]int, 0, len(index)+1), index...), i)
+		// flatten the embedded struct without column name
+		if f.Anonymous && name == "" && isNestedStruct(f.Type) {
+			parseFields(f.Type, fieldIndex, s)
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = snakeCase(f.Name)
+		}
+		if _, ok := s.byColumn[name]; ok {
+			continue
+		}
+		info := fieldInfo{index: fieldIndex, column: name, omitempty: strings.Contains(opts, "omitempty")}
+		s.fields = append(s.fields, info)
+		s.columns = append(s.columns, name)
+		s.byColumn[name] = info
+	}
+}
+
+func parseTag(f reflect.StructField) (name string, opts string) {
+	tag, ok := f.Tag.Lookup(tagName)
+	if !ok {
+		tag = f.Tag.Get("json")
+	}
+	if idx := strings.Index(tag, ","); idx != -1 {
+		return tag[:idx], tag[idx+1:]
+	}
+	return tag, ""
+}
+
+func isNestedStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != timeType && !reflect.PtrTo(t).Implements(scannerType)
+}
+
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// convert2Columns Convert a struct or map to columns and values, the columns of map are sorted
+// 将结构体或map转换为列与值, map的列按字典序排序
+func convert2Columns(obj interface{}) ([]string, []interface{}, error) {
+	v := reflect.ValueOf(obj)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, nil, errors.New("mysqlc: can not convert nil to columns")
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, nil, errors.Errorf("mysqlc: the key of map(%T) must be string", obj)
+		}
+		keys := make([]string, 0, v.Len())
+		for _, k := range v.MapKeys() {
+			keys = append(keys, k.String())
+		}
+		sort.Strings(keys)
+		values := make([]interface{}, 0, len(keys))
+		for _, k := range keys {
+			values = append(values, v.MapIndex(reflect.ValueOf(k).Convert(v.Type().Key())).Interface())
+		}
+		return keys, values, nil
+	case reflect.Struct:
+		s := schemaOf(v.Type())
+		columns := make([]string, 0, len(s.fields))
+		values := make([]interface{}, 0, len(s.fields))
+		for _, f := range s.fields {
+			fv := v.FieldByIndex(f.index)
+			if f.omitempty && fv.IsZero() {
+				continue
+			}
+			columns = append(columns, f.column)
+			values = append(values, fv.Interface())
+		}
+		return columns, values, nil
+	}
+	return nil, nil, errors.Errorf("mysqlc: unsupported type %T, must be struct or map", obj)
+}
+
+// selectColumns The columns to select, specified fields first, then the columns mapped from struct, otherwise all
+func selectColumns(elemType reflect.Type, fields []string) string {
+	if len(fields) == 0 && elemType.Kind() == reflect.Struct {
+		fields = schemaOf(elemType).columns
+	}
+	if len(fields) == 0 {
+		return "*"
+	}
+	quoted := make([]string, 0, len(fields))
+	for _, field := range fields {
+		quoted = append(quoted, quote(field))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// resultType check the result is a pointer of struct or map, and return the element type
+func resultType(result interface{}) (reflect.Type, error) {
+	t := reflect.TypeOf(result)
+	if t == nil || t.Kind() != reflect.Ptr {
+		return nil, errors.Errorf("mysqlc: result(%T) must be a pointer", result)
+	}
+	t = t.Elem()
+	if t.Kind() != reflect.Struct && !isStringMap(t) {
+		return nil, errors.Errorf("mysqlc: result(%T) must be a pointer of struct or map[string]interface{}", result)
+	}
+	return t, nil
+}
+
+// resultsType check the results is a pointer of slice, and return the element type without pointer
+func resultsType(results interface{}) (reflect.Type, error) {
+	t := reflect.TypeOf(results)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Slice {
+		return nil, errors.Errorf("mysqlc: results(%T) must be a pointer of slice", results)
+	}
+	elem := t.Elem().Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct && !isStringMap(elem) {
+		return nil, errors.Errorf("mysqlc: the element of results(%T) must be struct or map[string]interface{}", results)
+	}
+	return elem, nil
+}
+
+func isStringMap(t reflect.Type) bool {
+	return t.Kind() == reflect.Map && t.Key().Kind() == reflect.String && t.Elem().Kind() == reflect.Interface
+}
+
+// scanRow Scan the current row into dest, dest must be an addressable struct or a map[string]interface{}
+func scanRow(rows *sql.Rows, columns []string, dest reflect.Value) error {
+	if dest.Kind() == reflect.Map {
+		values := make([]interface{}, len(columns))
+		ptrs := make([]interface{}, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return err
+		}
+		if dest.IsNil() {
+			dest.Set(reflect.MakeMapWithSize(dest.Type(), len(columns)))
+		}
+		for i, column := range columns {
+			value := values[i]
+			if b, ok := value.([]byte); ok {
+				value = string(b)
+			}
+			var v reflect.Value
+			if value == nil {
+				v = reflect.Zero(dest.Type().Elem())
+			} else {
+				v = reflect.ValueOf(value)
+			}
+			dest.SetMapIndex(reflect.ValueOf(column).Convert(dest.Type().Key()), v)
+		}
+		return nil
+	}
+
+	s := schemaOf(dest.Type())
+	ptrs := make([]interface{}, len(columns))
+	for i, column := range columns {
+		if f, ok := s.byColumn[column]; ok {
+			ptrs[i] = dest.FieldByIndex(f.index).Addr().Interface()
+		} else {
+			ptrs[i] = new(interface{})
+		}
+	}
+	return rows.Scan(ptrs...)
+}
@@ -0,0 +1,164 @@
+package mysqlc
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	defaultCreateTimeFieldKey = "create_at"
+	defaultUpdateTimeFieldKey = "update_at"
+	defaultDeleteTimeFieldKey = "delete_at"
+)
+
+// autoTimeModel The time columns store unix seconds, and a row with delete time 0 means it is not deleted
+// 时间列存储unix秒数, 删除时间为0表示未删除
+type autoTimeModel struct {
+	*baseModel
+	softDelete         bool
+	createTimeFieldKey string
+	updateTimeFieldKey string
+	deleteTimeFieldKey string
+}
+
+func NewAutoTimeModel(client *Client, table string) *autoTimeModel {
+	return &autoTimeModel{
+		baseModel:          NewBaseModel(client, table),
+		createTimeFieldKey: defaultCreateTimeFieldKey,
+		updateTimeFieldKey: defaultUpdateTimeFieldKey,
+		deleteTimeFieldKey: defaultDeleteTimeFieldKey,
+	}
+}
+
+func (m *autoTimeModel) SetSoftDelete(softDelete bool) *autoTimeModel {
+	m.softDelete = softDelete
+	return m
+}
+
+func (m *autoTimeModel) SetCreateTimeFieldKey(createTimeFieldKey string) *autoTimeModel {
+	m.createTimeFieldKey = createTimeFieldKey
+	return m
+}
+
+func (m *autoTimeModel) SetUpdateTimeFieldKey(updateTimeFieldKey string) *autoTimeModel {
+	m.updateTimeFieldKey = updateTimeFieldKey
+	return m
+}
+
+func (m *autoTimeModel) SetDeleteTimeFieldKey(deleteTimeFieldKey string) *autoTimeModel {
+	m.deleteTimeFieldKey = deleteTimeFieldKey
+	return m
+}
+
+func (m *autoTimeModel) Do(ctx context.Context, exec func(ctx context.Context, model Model) (interface{}, error)) (interface{}, error) {
+	return m.client.Do(ctx, m, exec)
+}
+
+func (m *autoTimeModel) DoWithTransaction(ctx context.Context, exec func(ctx context.Context, model Model) (interface{}, error)) (interface{}, error) {
+	return m.client.DoWithTransaction(ctx, m, exec)
+}
+
+func (m *autoTimeModel) convert2Record(document interface{}) (record, error) {
+	columns, values, err := convert2Columns(document)
+	if err != nil {
+		return record{}, err
+	}
+	r := record{columns: columns, values: values}
+	now := time.Now().Unix()
+	r.set(m.createTimeFieldKey, now)
+	r.set(m.updateTimeFieldKey, now)
+	if m.softDelete {
+		r.set(m.deleteTimeFieldKey, int64(0))
+	}
+	return r, nil
+}
+
+func (m *autoTimeModel) InsertOne(ctx context.Context, document interface{}) (*InsertOneResult, error) {
+	r, err := m.convert2Record(document)
+	if err != nil {
+		return nil, err
+	}
+	res, err := m.insert(ctx, []record{r})
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	return &InsertOneResult{InsertedID: id}, nil
+}
+
+func (m *autoTimeModel) InsertMany(ctx context.Context, documents []interface{}) (*InsertManyResult, error) {
+	records := make([]record, 0, len(documents))
+	for _, document := range documents {
+		r, err := m.convert2Record(document)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+	}
+	return m.insertMany(ctx, records)
+}
+
+func (m *autoTimeModel) softDeleteFilter(filter Filter) Filter {
+	if !m.softDelete {
+		return filter
+	}
+	return And(filter, Eq(m.deleteTimeFieldKey, 0))
+}
+
+func (m *autoTimeModel) DeleteOne(ctx context.Context, filter Filter) (*DeleteResult, error) {
+	return m.deleteWithSoft(ctx, filter, true)
+}
+
+func (m *autoTimeModel) DeleteMany(ctx context.Context, filter Filter) (*DeleteResult, error) {
+	return m.deleteWithSoft(ctx, filter, false)
+}
+
+func (m *autoTimeModel) deleteWithSoft(ctx context.Context, filter Filter, one bool) (*DeleteResult, error) {
+	// 软删除更新删除时间
+	if m.softDelete {
+		now := time.Now().Unix()
+		softDelResult, err := m.update(ctx, m.softDeleteFilter(filter), record{
+			columns: []string{m.deleteTimeFieldKey, m.updateTimeFieldKey},
+			values:  []interface{}{now, now},
+		}, one)
+		if err != nil {
+			return nil, err
+		}
+		return &DeleteResult{DeletedCount: softDelResult.ModifiedCount}, nil
+	}
+	// 真实删除
+	return m.delete(ctx, filter, one)
+}
+
+func (m *autoTimeModel) FindOne(ctx context.Context, filter Filter, result interface{}, opts ...*FindOptions) error {
+	return m.findOne(ctx, m.softDeleteFilter(filter), result, opts...)
+}
+
+func (m *autoTimeModel) FindMany(ctx context.Context, filter Filter, results interface{}, opts ...*FindOptions) error {
+	return m.findMany(ctx, m.softDeleteFilter(filter), results, opts...)
+}
+
+func (m *autoTimeModel) UpdateOne(ctx context.Context, filter Filter, update interface{}) (*UpdateResult, error) {
+	return m.updateWithTime(ctx, filter, update, true)
+}
+
+func (m *autoTimeModel) UpdateMany(ctx context.Context, filter Filter, update interface{}) (*UpdateResult, error) {
+	return m.updateWithTime(ctx, filter, update, false)
+}
+
+func (m *autoTimeModel) updateWithTime(ctx context.Context, filter Filter, update interface{}, one bool) (*UpdateResult, error) {
+	columns, values, err := convert2Columns(update)
+	if err != nil {
+		return nil, err
+	}
+	r := record{columns: columns, values: values}
+	r.set(m.updateTimeFieldKey, time.Now().Unix())
+	return m.update(ctx, m.softDeleteFilter(filter), r, one)
+}
+
+func (m *autoTimeModel) Count(ctx context.Context, filter Filter) (int64, error) {
+	return m.baseModel.Count(ctx, m.softDeleteFilter(filter))
+}
@@ -11,9 +11,12 @@ type Client struct {
 	wrapClient
 }
 
+// NewClient every command of the client runs in a client span
+// 客户端的每条命令都在client span中执行
 func NewClient(ctx context.Context, config Config) (*Client, error) {
 	options := config.Convert2Otions()
 	client := redis.NewUniversalClient(options)
+	client.AddHook(tracingHook{})
 	if err := client.Ping(ctx).Err(); err != nil {
 		return nil, err
 	}
@@ -0,0 +1,65 @@
+package redisc
+
+import (
+	"context"
+	"github.com/go-redis/redis/v8"
+	"github.com/whereabouts/sdk/trace"
+	"strings"
+)
+
+// tracingHook Run every command or pipeline in a client span
+type tracingHook struct{}
+
+func (tracingHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	options := []trace.StartOption{
+		trace.WithKind(trace.KindClient),
+		trace.WithAttribute("db.system", "redis"),
+		trace.WithAttribute("db.operation", cmd.Name()),
+	}
+	if args := cmd.Args(); len(args) > 1 {
+		if key, ok := args[1].(string); ok {
+			options = append(options, trace.WithAttribute("db.redis.key", key))
+		}
+	}
+	ctx, _ = trace.Start(ctx, "redis "+cmd.Name(), options...)
+	return ctx, nil
+}
+
+func (tracingHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	endSpan(ctx, cmd.Err())
+	return nil
+}
+
+func (tracingHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	names := make([]string, 0, len(cmds))
+	for _, cmd := range cmds {
+		names = append(names, cmd.Name())
+	}
+	ctx, _ = trace.Start(ctx, "redis pipeline",
+		trace.WithKind(trace.KindClient),
+		trace.WithAttribute("db.system", "redis"),
+		trace.WithAttribute("db.operation", strings.Join(names, " ")),
+	)
+	return ctx, nil
+}
+
+func (tracingHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	var err error
+	for _, cmd := range cmds {
+		if cmdErr := cmd.Err(); cmdErr != nil && cmdErr != redis.Nil {
+			err = cmdErr
+			break
+		}
+	}
+	endSpan(ctx, err)
+	return nil
+}
+
+// endSpan End the span started by the hook, redis.Nil is not an error
+func endSpan(ctx context.Context, err error) {
+	span := trace.SpanFrom(ctx)
+	if err != nil && err != redis.Nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
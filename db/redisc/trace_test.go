@@ -0,0 +1,54 @@
+package redisc
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/whereabouts/sdk/trace"
+	"testing"
+)
+
+func TestTracingHook(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c, err := NewClientWithOptions(context.Background(), WithAddrs(mr.Addr()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exporter := trace.NewMemoryExporter()
+	p := trace.NewProvider(exporter)
+	trace.SetProvider(p)
+	defer trace.SetProvider(nil)
+
+	ctx, parent := trace.Start(context.Background(), "parent")
+	m := NewModel(c, "user")
+	if err = m.Set(ctx, m.Key("1"), "tom", 0).Err(); err != nil {
+		t.Fatal(err)
+	}
+	if err = m.Get(ctx, m.Key("2")).Err(); err != redis.Nil {
+		t.Fatalf("expect redis.Nil, got %v", err)
+	}
+	_, _ = m.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Incr(ctx, m.Key("count"))
+		pipe.HGet(ctx, m.Key("1"), "name")
+		return nil
+	})
+	parent.End()
+	if err = p.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := exporter.Spans()
+	if len(spans) != 4 {
+		t.Fatalf("expect 4 spans, got %d", len(spans))
+	}
+	set, get, pipeline := spans[0], spans[1], spans[2]
+	if set.Name != "redis set" || set.Attributes["db.redis.key"] != "user.1" || set.ParentSpanID != parent.SpanContext.SpanID {
+		t.Fatalf("unexpected span %+v", set)
+	}
+	if get.StatusCode != trace.StatusUnset {
+		t.Fatalf("expect redis.Nil not an error, got %+v", get)
+	}
+	if pipeline.Attributes["db.operation"] != "incr hget" || pipeline.StatusCode != trace.StatusError {
+		t.Fatalf("unexpected pipeline span %+v", pipeline)
+	}
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"context"
+	"github.com/whereabouts/sdk/example/httpserver/proto"
+	"github.com/whereabouts/sdk/httpserver"
+	"github.com/whereabouts/sdk/httpserver/ws"
+)
+
+type ChatHandler struct {
+	hub *ws.Hub
+}
+
+func (h *ChatHandler) Init(router httpserver.RouteGroup, hub *ws.Hub) {
+	h.hub = hub
+	router.GET("/chat", ws.Handle(hub, h.Chat))
+}
+
+func (h *ChatHandler) Chat(ctx context.Context, conn *ws.Conn, msg *proto.HelloChatMsg) error {
+	if msg.Join {
+		conn.Join(msg.Room)
+		return nil
+	}
+	return h.hub.Broadcast(ctx, msg.Room, msg)
+}
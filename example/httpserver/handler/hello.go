@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/whereabouts/sdk/example/httpserver/proto"
+	"github.com/whereabouts/sdk/httpserver"
 	"github.com/whereabouts/sdk/httpserver/handler"
 	"github.com/whereabouts/sdk/httpserver/handler/result"
 	"net/http"
@@ -14,18 +15,21 @@ import (
 type HelloHandler struct {
 }
 
-func (h *HelloHandler) Init(router gin.IRouter) {
-	helloGroup := router.Group("hello")
-	helloGroup.GET("/:name", handler.New(h.Hello))
-	helloGroup.GET("/", handler.New(h.Hello))
-	helloGroup.GET("/had_err", handler.New(h.HelloHadError))
-	helloGroup.GET("/use_err", handler.New(h.HelloUseErr))
-	helloGroup.GET("/with_gin_ctx", handler.NewWithOptions(h.HelloWithGinCtx, handler.WithContext()))
-	helloGroup.GET("/with_gin_ctx_and_no_response", handler.NewWithOptions(h.HelloWithGinCtxAndNoResponse, handler.WithContext(), handler.WithoutResponse()))
-	helloGroup.GET("/with_result", handler.NewWithOptions(h.HelloWithResult, handler.WithResult()))
-	helloGroup.POST("/file", handler.New(h.HelloFile))
-	helloGroup.POST("/multiple_files", handler.NewWithOptions(h.HelloMultipleFiles, handler.WithContext()))
-	helloGroup.GET("/with_message", handler.NewWithOptions(h.HelloWithMessage, handler.WithResult()))
+func (h *HelloHandler) Init(router httpserver.RouteGroup) {
+	router.Group("hello").
+		GET("/:name", h.Hello).
+		GET("/", h.Hello).
+		GET("/had_err", h.HelloHadError).
+		GET("/use_err", h.HelloUseErr).
+		GET("/with_gin_ctx", h.HelloWithGinCtx, handler.WithContext()).
+		GET("/with_gin_ctx_and_no_response", h.HelloWithGinCtxAndNoResponse, handler.WithContext(), handler.WithoutResponse()).
+		GET("/with_result", h.HelloWithResult, handler.WithResult()).
+		POST("/file", h.HelloFile).
+		POST("/multiple_files", h.HelloMultipleFiles, handler.WithContext()).
+		GET("/with_message", h.HelloWithMessage, handler.WithResult()).
+		GET("/typed", handler.Handle(h.Hello)).
+		GET("/progress", handler.Stream(h.HelloProgress)).
+		GET("/export", handler.Download(h.HelloExport))
 }
 
 func (h *HelloHandler) Hello(ctx context.Context, req *proto.HelloReq) (*proto.HelloResp, error) {
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"github.com/whereabouts/sdk/example/httpserver/proto"
+	"github.com/whereabouts/sdk/excel"
+	"github.com/whereabouts/sdk/httpserver/handler"
+	"time"
+)
+
+func (h *HelloHandler) HelloProgress(ctx context.Context, req *proto.HelloProgressReq, events chan<- handler.Event) error {
+	for i := 1; i <= req.Steps; i++ {
+		select {
+		case <-time.After(100 * time.Millisecond):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		events <- handler.Event{ID: fmt.Sprint(i), Event: "progress", Data: map[string]int{"done": i, "total": req.Steps}}
+	}
+	return nil
+}
+
+func (h *HelloHandler) HelloExport(ctx context.Context, req *proto.HelloExportReq, file *handler.Attachment) error {
+	w, err := excel.NewRowWriter("hello")
+	if err != nil {
+		return err
+	}
+	// remove the temp file of rows if the export is canceled or fails
+	defer w.Close()
+	if err = w.WriteRow("id", "welcome"); err != nil {
+		return err
+	}
+	for i := 1; i <= req.Rows; i++ {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		if err = w.WriteRow(i, fmt.Sprintf("hello, %d!", i)); err != nil {
+			return err
+		}
+	}
+	// the xlsx is sent after all the rows are written, write CSV to the file to send the rows one by one
+	file.Name = "hello.xlsx"
+	file.ContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	return w.Write(file)
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/whereabouts/sdk/example/httpserver/server"
 	"github.com/whereabouts/sdk/httpserver"
 	"github.com/whereabouts/sdk/httpserver/middleware"
+	"github.com/whereabouts/sdk/httpserver/openapi"
 	"github.com/whereabouts/sdk/logger"
 )
 
@@ -14,16 +15,21 @@ func main() {
 	if err := config.Load(); err != nil {
 		logger.Fatalf("load config error: %v\n", err)
 	}
-	if err := httpserver.NewServer(
+	s := httpserver.NewServer(
 		httpserver.WithName(config.GetConfig().AppName),
 		httpserver.WithPort(config.GetConfig().Port),
 		httpserver.WithMode(config.GetConfig().Mode),
 		httpserver.WithMiddlewares(
 			middleware.Recovery(),
+			middleware.Trace(),
 			middleware.LoggingSimplyRequest(),
 			middleware.LoggingSimplyResponse(),
 		),
-	).Routes(server.Routes).OnBeforeRun(server.Init).OnShutdown(server.Close).Run(ctx); err != nil {
+		httpserver.WithOpenAPI(openapi.WithUI("/docs")),
+		httpserver.WithMetrics(),
+	)
+	server.Routes(s)
+	if err := s.OnBeforeRun(server.Init).OnShutdown(server.Close).Run(ctx); err != nil {
 		logger.Fatalf("server run with error: %v\n", err)
 	}
 }
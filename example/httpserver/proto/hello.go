@@ -27,3 +27,17 @@ type HelloMultipleFilesReq struct {
 type HelloMultipleFilesResp struct {
 	Result string `json:"result"`
 }
+
+type HelloProgressReq struct {
+	Steps int `json:"steps" form:"steps,default=10"`
+}
+
+type HelloExportReq struct {
+	Rows int `json:"rows" form:"rows,default=1000"`
+}
+
+type HelloChatMsg struct {
+	Room string `json:"room" binding:"required"`
+	Join bool   `json:"join"`
+	Text string `json:"text"`
+}
@@ -1,10 +1,12 @@
 package server
 
 import (
+	"context"
 	"github.com/whereabouts/sdk/logger"
 )
 
-func Close() {
+func Close(ctx context.Context) error {
 	// close db
 	logger.Println("doing something on shutdown...")
+	return nil
 }
@@ -1,10 +1,11 @@
 package server
 
 import (
-	"github.com/gin-gonic/gin"
 	handlers "github.com/whereabouts/sdk/example/httpserver/handler"
+	"github.com/whereabouts/sdk/httpserver"
 )
 
-func Routes(engine *gin.Engine) {
-	new(handlers.HelloHandler).Init(engine)
+func Routes(s httpserver.Server) {
+	new(handlers.HelloHandler).Init(s.Group("/"))
+	new(handlers.ChatHandler).Init(s.Group("/"), s.Hub())
 }
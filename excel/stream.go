@@ -0,0 +1,84 @@
+package excel
+
+import (
+	"github.com/pkg/errors"
+	"github.com/xuri/excelize/v2"
+	"io"
+	"io/ioutil"
+)
+
+const defaultSheet = "Sheet1"
+
+// RowWriter Write the rows of a new excel one by one, the rows are kept by excelize in a buffer which is
+// moved to a temp file when large rather than as cells, so it fits the exports of many rows.
+// The xlsx is a zip archive, so nothing is written out until all the rows are written and Write is called,
+// use CSV to send the rows while they are produced. Close should be deferred to remove the temp file
+// if Write is not reached
+// 逐行写入新excel, 行数据由excelize保存在缓冲区(较大时转存临时文件)而非单元格中, 适用于大量行的导出.
+// xlsx为zip压缩包, 因此在写完所有行并调用Write前不会输出任何数据, 需要边生成边发送时请使用CSV.
+// 应defer调用Close, 以便未执行到Write时删除临时文件
+type RowWriter struct {
+	kernel  *excelize.File
+	stream  *excelize.StreamWriter
+	row     int
+	flushed bool
+	done    bool
+}
+
+// NewRowWriter create a row writer of the sheet, Sheet1 if sheet is empty
+func NewRowWriter(sheet string) (*RowWriter, error) {
+	file := excelize.NewFile()
+	if sheet == "" {
+		sheet = defaultSheet
+	} else if sheet != defaultSheet {
+		file.SetSheetName(defaultSheet, sheet)
+	}
+	stream, err := file.NewStreamWriter(sheet)
+	if err != nil {
+		return nil, errors.Wrap(err, "new stream writer err")
+	}
+	return &RowWriter{kernel: file, stream: stream}, nil
+}
+
+// WriteRow write the values as the next row
+func (w *RowWriter) WriteRow(values ...interface{}) error {
+	cell, err := excelize.CoordinatesToCellName(1, w.row+1)
+	if err != nil {
+		return err
+	}
+	if err = w.stream.SetRow(cell, values); err != nil {
+		return errors.Wrapf(err, "write row %d err", w.row+1)
+	}
+	w.row++
+	return nil
+}
+
+// Rows returns the count of rows written
+func (w *RowWriter) Rows() int {
+	return w.row
+}
+
+// Write finish the rows and write the excel to out, no row can be written after it
+func (w *RowWriter) Write(out io.Writer) error {
+	if !w.flushed {
+		if err := w.stream.Flush(); err != nil {
+			return errors.Wrap(err, "flush rows err")
+		}
+		w.flushed = true
+	}
+	if err := w.kernel.Write(out); err != nil {
+		return err
+	}
+	w.done = true
+	return nil
+}
+
+// Close Remove the temp file of the rows if the excel is not written completely, since excelize removes it
+// only when the excel is written, it does nothing after Write succeeds
+// 若excel未完整写出则删除行数据的临时文件, 因为excelize只在写出excel时删除它, Write成功后调用无任何操作
+func (w *RowWriter) Close() error {
+	if w.done {
+		return nil
+	}
+	return w.Write(ioutil.Discard)
+}
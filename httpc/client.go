@@ -31,6 +31,9 @@ func NewClient(options ...Option) (Client, error) {
 	return NewClientWithConfig(newConfig(options...))
 }
 
+// NewClientWithConfig every attempt of the requests runs in a client span and forwards the trace to the downstream,
+// since the transport is wrapped for it, replace the transport by Kernel().SetTransport instead of modifying it
+// 每次请求尝试都在client span中执行并向下游转发trace, 由于transport为此被包装, 请通过Kernel().SetTransport替换transport而非修改它
 func NewClientWithConfig(config Config) (Client, error) {
 
 	if stringer.NotEmpty(config.Alias) && ClientManager().Has(config.Alias) {
@@ -47,7 +50,7 @@ func NewClientWithConfig(config Config) (Client, error) {
 		MaxIdleConnsPerHost:   runtime.GOMAXPROCS(0) + 1,
 	}
 	c := &client{
-		kernel: resty.NewWithClient(&http.Client{Transport: transport}),
+		kernel: resty.NewWithClient(&http.Client{Transport: &tracingTransport{base: transport}}),
 		config: config,
 	}
 	c.kernel.SetHostURL(c.config.Host)
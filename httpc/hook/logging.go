@@ -6,6 +6,7 @@ import (
 	"github.com/go-resty/resty/v2"
 	"github.com/whereabouts/sdk/logger"
 	"github.com/whereabouts/sdk/logger/field"
+	"github.com/whereabouts/sdk/logger/redact"
 	"github.com/whereabouts/sdk/utils/mapper"
 	"io/ioutil"
 	"net/http"
@@ -14,71 +15,127 @@ import (
 
 const maxBodyLen = 1024
 
+type loggingConfig struct {
+	simply     bool
+	redactor   *redact.Redactor
+	maxBodyLen int
+}
+
+type LoggingOption func(config *loggingConfig)
+
+func newLoggingConfig(options ...LoggingOption) loggingConfig {
+	config := loggingConfig{redactor: redact.Default, maxBodyLen: maxBodyLen}
+	for _, option := range options {
+		option(&config)
+	}
+	return config
+}
+
+// LoggingSimply do not log the headers
+// 不记录headers
+func LoggingSimply() LoggingOption {
+	return func(config *loggingConfig) {
+		config.simply = true
+	}
+}
+
+// LoggingRedactor mask the sensitive data by redactor, redact.Default by default
+// 使用redactor对敏感数据脱敏, 默认为redact.Default
+func LoggingRedactor(redactor *redact.Redactor) LoggingOption {
+	return func(config *loggingConfig) {
+		config.redactor = redactor
+	}
+}
+
+// LoggingMaxBodyLen the max bytes of body to log, default 1024
+// 记录的body的最大字节数, 默认1024
+func LoggingMaxBodyLen(maxBodyLen int) LoggingOption {
+	return func(config *loggingConfig) {
+		config.maxBodyLen = maxBodyLen
+	}
+}
+
 func LoggingRequest() RequestHook {
-	return LoggingRequestWithLogger(logger.StandardLogger(), false)
+	return LoggingRequestWithOptions(logger.StandardLogger())
 }
 
 func LoggingResponse() ResponseHook {
-	return LoggingResponseWithLogger(logger.StandardLogger(), false)
+	return LoggingResponseWithOptions(logger.StandardLogger())
 }
 
 func LoggingSimplyRequest() RequestHook {
-	return LoggingRequestWithLogger(logger.StandardLogger(), true)
+	return LoggingRequestWithOptions(logger.StandardLogger(), LoggingSimply())
 }
 
 func LoggingSimplyResponse() ResponseHook {
-	return LoggingResponseWithLogger(logger.StandardLogger(), true)
+	return LoggingResponseWithOptions(logger.StandardLogger(), LoggingSimply())
 }
 
 func LoggingRequestWithLogger(l *logger.Logger, simply bool) RequestHook {
+	if simply {
+		return LoggingRequestWithOptions(l, LoggingSimply())
+	}
+	return LoggingRequestWithOptions(l)
+}
+
+func LoggingResponseWithLogger(l *logger.Logger, simply bool) ResponseHook {
+	if simply {
+		return LoggingResponseWithOptions(l, LoggingSimply())
+	}
+	return LoggingResponseWithOptions(l)
+}
+
+// LoggingRequestWithOptions Log the outgoing requests with the sensitive data masked
+// 记录敏感数据已脱敏的请求
+func LoggingRequestWithOptions(l *logger.Logger, options ...LoggingOption) RequestHook {
+	config := newLoggingConfig(options...)
 	return func(c *resty.Client, r *resty.Request) error {
+		u := r.RawRequest.URL
 		fields := field.Fields{
 			"method": r.RawRequest.Method,
-			"url":    r.RawRequest.URL.String(),
-			"body":   requestBody(r),
+			"url":    u.Scheme + "://" + u.Host + config.redactor.URL(u),
+			"body":   truncate(config.redactor.Body(requestBody(r)), config.maxBodyLen),
 		}
-		if !simply {
-			fields["headers"] = convertHeaders2JSON(r.RawRequest.Header)
+		if !config.simply {
+			fields["headers"] = convertHeaders2JSON(config.redactor.Headers(r.RawRequest.Header))
 		}
-		l.WithFields(fields).Info("outgoing http request")
+		l.WithContext(r.Context()).WithFields(fields).Info("outgoing http request")
 		return nil
 	}
 }
 
-func LoggingResponseWithLogger(l *logger.Logger, simply bool) ResponseHook {
+// LoggingResponseWithOptions Log the incoming responses with the sensitive data masked
+// 记录敏感数据已脱敏的响应
+func LoggingResponseWithOptions(l *logger.Logger, options ...LoggingOption) ResponseHook {
+	config := newLoggingConfig(options...)
 	return func(c *resty.Client, r *resty.Response) error {
 		fields := field.Fields{
 			"status": r.Status(),
-			"body":   responseBody(r),
+			"body":   truncate(config.redactor.Body(r.Body()), config.maxBodyLen),
 		}
-		if !simply {
-			fields["headers"] = convertHeaders2JSON(r.Header())
+		if !config.simply {
+			fields["headers"] = convertHeaders2JSON(config.redactor.Headers(r.Header()))
 		}
-		l.WithFields(fields).Info("incoming http response")
+		l.WithContext(r.Request.Context()).WithFields(fields).Info("incoming http response")
 		return nil
 	}
 }
 
-func requestBody(r *resty.Request) string {
+func requestBody(r *resty.Request) []byte {
 	if r.RawRequest.Body == nil || r.RawRequest.Body == http.NoBody {
-		return ""
+		return nil
 	}
 	body, _ := ioutil.ReadAll(r.RawRequest.Body)
 	_ = r.RawRequest.Body.Close()
 	r.RawRequest.Body = ioutil.NopCloser(bytes.NewReader(body))
-	bodySize := len(body)
-	if bodySize > maxBodyLen {
-		bodySize = maxBodyLen
-	}
-	return string(body[:bodySize])
+	return body
 }
 
-func responseBody(r *resty.Response) string {
-	cnt := len(r.String())
-	if cnt > maxBodyLen {
-		cnt = maxBodyLen
+func truncate(body string, maxLen int) string {
+	if maxLen >= 0 && len(body) > maxLen {
+		return body[:maxLen]
 	}
-	return r.String()[:cnt]
+	return body
 }
 
 func convertHeaders2JSON(headers http.Header) string {
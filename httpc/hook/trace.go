@@ -0,0 +1,16 @@
+package hook
+
+import (
+	"github.com/go-resty/resty/v2"
+	"github.com/whereabouts/sdk/trace"
+)
+
+// Trace Forward the X-Request-ID and W3C traceparent in the context of request to the downstream,
+// the clients created by httpc forward them by the client spans already, it is for the other resty clients
+// 将request的context中的X-Request-ID与W3C traceparent转发给下游, httpc创建的客户端已通过client span转发, 本hook用于其他resty客户端
+func Trace() RequestHook {
+	return func(c *resty.Client, r *resty.Request) error {
+		trace.Inject(r.Context(), r.Header)
+		return nil
+	}
+}
@@ -0,0 +1,34 @@
+package httpc
+
+import (
+	"fmt"
+	"github.com/whereabouts/sdk/trace"
+	"net/http"
+)
+
+// tracingTransport Run every attempt of the requests in a client span, and forward the trace to the downstream
+type tracingTransport struct {
+	base http.RoundTripper
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	url := fmt.Sprintf("%s://%s%s", req.URL.Scheme, req.URL.Host, req.URL.Path)
+	ctx, span := trace.Start(req.Context(), "HTTP "+req.Method,
+		trace.WithKind(trace.KindClient),
+		trace.WithAttribute("http.method", req.Method),
+		trace.WithAttribute("http.url", url),
+	)
+	defer span.End()
+	req = req.Clone(ctx)
+	trace.Inject(ctx, req.Header)
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	span.SetAttribute("http.status_code", resp.StatusCode)
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(trace.StatusError, resp.Status)
+	}
+	return resp, nil
+}
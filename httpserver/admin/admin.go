@@ -0,0 +1,142 @@
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"github.com/gin-gonic/gin"
+	"github.com/whereabouts/sdk/httpserver/handler"
+	"github.com/whereabouts/sdk/httpserver/handler/result"
+	"github.com/whereabouts/sdk/logger/level"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+const (
+	PathPprof = "/debug/pprof"
+	PathInfo  = "/info"
+	PathLevel = "/log/level"
+
+	headerToken = "X-Admin-Token"
+)
+
+// the build information set by -ldflags, the ones of the vcs recorded by go build are used if not set
+// example: go build -ldflags "-X github.com/whereabouts/sdk/httpserver/admin.Version=v1.0.0"
+// 由-ldflags设置的构建信息, 未设置时使用go build记录的vcs信息
+var (
+	Version   string
+	Commit    string
+	BuildTime string
+)
+
+var startTime = time.Now()
+
+type Info struct {
+	Name       string      `json:"name"`
+	Version    string      `json:"version"`
+	Commit     string      `json:"commit"`
+	BuildTime  string      `json:"build_time"`
+	GoVersion  string      `json:"go_version"`
+	StartTime  time.Time   `json:"start_time"`
+	Uptime     string      `json:"uptime"`
+	Goroutines int         `json:"goroutines"`
+	Config     interface{} `json:"config"`
+}
+
+type LevelReq struct {
+	Level string `json:"level" form:"level" binding:"required,oneof=panic fatal error warn warning info debug trace"`
+}
+
+type LevelResp struct {
+	Level string `json:"level"`
+}
+
+// New Create the handler of admin endpoints, it is served on an internal port
+// 创建管理接口的handler, 应在内部端口上提供
+func New(options ...Option) http.Handler {
+	engine := gin.New()
+	Register(engine, options...)
+	return engine
+}
+
+// Register Register the pprof, info and log level endpoints and the handlers set by WithHandler on router
+// 在router上注册pprof, info与日志级别接口, 以及WithHandler设置的handler
+func Register(router gin.IRouter, options ...Option) {
+	config := newConfig(options...)
+	group := router.Group("", authorize(config.Token))
+
+	group.GET(PathPprof+"/", gin.WrapF(pprof.Index))
+	group.GET(PathPprof+"/cmdline", gin.WrapF(pprof.Cmdline))
+	group.GET(PathPprof+"/profile", gin.WrapF(pprof.Profile))
+	group.POST(PathPprof+"/symbol", gin.WrapF(pprof.Symbol))
+	group.GET(PathPprof+"/symbol", gin.WrapF(pprof.Symbol))
+	group.GET(PathPprof+"/trace", gin.WrapF(pprof.Trace))
+	for _, profile := range []string{"allocs", "block", "goroutine", "heap", "mutex", "threadcreate"} {
+		group.GET(PathPprof+"/"+profile, gin.WrapH(pprof.Handler(profile)))
+	}
+
+	group.GET(PathInfo, handler.Handle(func(ctx context.Context, req *struct{}) (*Info, error) {
+		return newInfo(config), nil
+	}))
+	group.GET(PathLevel, handler.Handle(func(ctx context.Context, req *struct{}) (*LevelResp, error) {
+		return &LevelResp{Level: config.logger.Kernel().GetLevel().String()}, nil
+	}))
+	group.PUT(PathLevel, handler.Handle(func(ctx context.Context, req *LevelReq) (*LevelResp, error) {
+		l := level.String2Level(req.Level)
+		config.logger.SetLevel(l)
+		config.logger.WithContext(ctx).Warnf("log level is changed to %s by admin", l)
+		return &LevelResp{Level: l.String()}, nil
+	}))
+	for _, r := range config.routes {
+		group.GET(r.path, gin.WrapH(r.handler))
+	}
+}
+
+// authorize Reject the requests without the token if it is set
+func authorize(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			return
+		}
+		got := c.GetHeader(headerToken)
+		if auth := c.GetHeader("Authorization"); got == "" && strings.HasPrefix(auth, "Bearer ") {
+			got = strings.TrimPrefix(auth, "Bearer ")
+		}
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			handler.AbortWithErr(c, result.Error(result.CodeBoolFail, result.Unauthorized).WithStatusCode(http.StatusUnauthorized))
+		}
+	}
+}
+
+func newInfo(config Config) *Info {
+	info := &Info{
+		Name:       config.name,
+		Version:    Version,
+		Commit:     Commit,
+		BuildTime:  BuildTime,
+		GoVersion:  runtime.Version(),
+		StartTime:  startTime,
+		Uptime:     time.Since(startTime).Round(time.Second).String(),
+		Goroutines: runtime.NumGoroutine(),
+		Config:     config.summary,
+	}
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	if info.Version == "" && build.Main.Version != "(devel)" {
+		info.Version = build.Main.Version
+	}
+	for _, setting := range build.Settings {
+		switch {
+		case setting.Key == "vcs.revision" && info.Commit == "":
+			info.Commit = setting.Value
+		case setting.Key == "vcs.time" && info.BuildTime == "":
+			info.BuildTime = setting.Value
+		}
+	}
+	return info
+}
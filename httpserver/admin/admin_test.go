@@ -0,0 +1,68 @@
+package admin
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/whereabouts/sdk/logger"
+	"github.com/whereabouts/sdk/logger/level"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	l := logger.New()
+	h := New(WithToken("secret"), WithName("demo"), WithSummary(map[string]interface{}{"port": 8080}), WithLogger(l))
+	request := func(method, target, body, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := request(http.MethodGet, PathInfo, "", ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expect 401 without token, got %d", w.Code)
+	}
+	if w := request(http.MethodGet, PathInfo, "", "wrong"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expect 401 with wrong token, got %d", w.Code)
+	}
+
+	w := request(http.MethodGet, PathInfo, "", "secret")
+	var info struct {
+		Data Info `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &info); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("unexpected info %d %s, err: %v", w.Code, w.Body.String(), err)
+	}
+	if info.Data.Name != "demo" || info.Data.GoVersion == "" || info.Data.Config.(map[string]interface{})["port"] != float64(8080) {
+		t.Fatalf("unexpected info %+v", info.Data)
+	}
+
+	if w = request(http.MethodPut, PathLevel, `{"level":"debug"}`, "secret"); w.Code != http.StatusOK {
+		t.Fatalf("expect level set, got %d %s", w.Code, w.Body.String())
+	}
+	if l.Kernel().GetLevel() != level.DebugLevel {
+		t.Fatalf("expect debug level, got %s", l.Kernel().GetLevel())
+	}
+	if w = request(http.MethodGet, PathLevel, "", "secret"); !strings.Contains(w.Body.String(), `"level":"debug"`) {
+		t.Fatalf("unexpected level %s", w.Body.String())
+	}
+	if w = request(http.MethodPut, PathLevel, `{"level":"verbose"}`, "secret"); w.Code != http.StatusBadRequest {
+		t.Fatalf("expect invalid level rejected, got %d", w.Code)
+	}
+
+	if w = request(http.MethodGet, PathPprof+"/", "", "secret"); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "goroutine") {
+		t.Fatalf("expect pprof index, got %d", w.Code)
+	}
+	if w = request(http.MethodGet, PathPprof+"/heap?debug=1", "", "secret"); w.Code != http.StatusOK {
+		t.Fatalf("expect heap profile, got %d", w.Code)
+	}
+}
@@ -0,0 +1,72 @@
+package admin
+
+import (
+	"github.com/whereabouts/sdk/logger"
+	"net/http"
+)
+
+type Config struct {
+	// Token the requests must carry it by "Authorization: Bearer {token}" or "X-Admin-Token" if set
+	// 设置时请求必须通过"Authorization: Bearer {token}"或"X-Admin-Token"携带该token
+	Token   string `mapstructure:"token" json:"token"`
+	name    string
+	summary interface{}
+	logger  *logger.Logger
+	routes  []route
+}
+
+type route struct {
+	path    string
+	handler http.Handler
+}
+
+type Option func(config *Config)
+
+func newConfig(options ...Option) Config {
+	config := Config{logger: logger.StandardLogger()}
+	for _, option := range options {
+		option(&config)
+	}
+	return config
+}
+
+func WithToken(token string) Option {
+	return func(config *Config) {
+		config.Token = token
+	}
+}
+
+// WithName the name of service shown by the info endpoint
+// info接口展示的服务名
+func WithName(name string) Option {
+	return func(config *Config) {
+		config.name = name
+	}
+}
+
+// WithSummary the summary of config shown by the info endpoint, it must not contain any secret
+// info接口展示的配置摘要, 不得包含任何密钥
+func WithSummary(summary interface{}) Option {
+	return func(config *Config) {
+		config.summary = summary
+	}
+}
+
+// WithHandler serve h at path by GET behind the token as the other admin endpoints, such as the metrics registry
+// 以GET在path提供h, 与其他管理接口一样需要token, 例如指标的registry
+//
+//	path, registry := metrics.Route()
+//	admin.New(admin.WithHandler(path, registry))
+func WithHandler(path string, h http.Handler) Option {
+	return func(config *Config) {
+		config.routes = append(config.routes, route{path: path, handler: h})
+	}
+}
+
+// WithLogger the logger whose level is got and set by the level endpoint, logger.StandardLogger() by default
+// level接口获取与设置级别的logger, 默认logger.StandardLogger()
+func WithLogger(l *logger.Logger) Option {
+	return func(config *Config) {
+		config.logger = l
+	}
+}
@@ -2,7 +2,14 @@ package httpserver
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/whereabouts/sdk/httpserver/admin"
+	"github.com/whereabouts/sdk/httpserver/handler/result"
+	"github.com/whereabouts/sdk/httpserver/health"
+	"github.com/whereabouts/sdk/httpserver/metrics"
 	"github.com/whereabouts/sdk/httpserver/middleware"
+	"github.com/whereabouts/sdk/httpserver/openapi"
+	"github.com/whereabouts/sdk/httpserver/ws"
+	"net"
 )
 
 const ModeDebug = gin.DebugMode
@@ -10,20 +17,76 @@ const ModeRelease = gin.ReleaseMode
 const ModeTest = gin.TestMode
 
 type Config struct {
-	Mode        string `mapstructure:"mode" json:"mode"`
-	Name        string `mapstructure:"name" json:"name"`
-	Port        int    `mapstructure:"port" json:"port"`
+	Mode string `mapstructure:"mode" json:"mode"`
+	Name string `mapstructure:"name" json:"name"`
+	// Port the port to serve, it is not served if negative, so that only the Listeners are served
+	// 服务的端口, 为负数时不服务该端口, 只服务Listeners
+	Port int `mapstructure:"port" json:"port"`
+	// TLS serve HTTPS and HTTP/2 on the port if set
+	// 设置时在端口上提供HTTPS与HTTP/2
+	TLS *TLSConfig `mapstructure:"tls" json:"tls"`
+	// H2C serve HTTP/2 without TLS on the port
+	// 在端口上不使用TLS提供HTTP/2
+	H2C bool `mapstructure:"h2c" json:"h2c"`
+	// Listeners the extra listeners served together with the port, such as a unix socket or an internal port
+	// 与端口一起服务的其他listener, 例如unix socket或内部端口
+	Listeners []ListenerConfig `mapstructure:"listeners" json:"listeners"`
+	// AdminPort the internal port to serve the admin endpoints, such as pprof, info and log level, disabled if 0
+	// 提供pprof, info与日志级别等管理接口的内部端口, 为0时不启用
+	AdminPort int `mapstructure:"admin_port" json:"admin_port"`
+	// AdminToken the admin endpoints require it if set
+	// 设置时管理接口要求该token
+	AdminToken string `mapstructure:"admin_token" json:"admin_token"`
+	// DrainPeriod the seconds to wait after marked not ready before stopping accepting connections,
+	// so that the load balancers can notice it, default 0
+	// 标记为未就绪后到停止接收连接前等待的秒数, 以便负载均衡感知, 默认0
+	DrainPeriod int `mapstructure:"drain_period" json:"drain_period"`
+	// ShutdownTimeout the seconds to wait the active requests when shutting down, default 30 if not positive
+	// 关闭时等待处理中请求的秒数, 不为正数时默认30
+	ShutdownTimeout int `mapstructure:"shutdown_timeout" json:"shutdown_timeout"`
+	// HookTimeout the default seconds to wait each shutdown hook, default 10 if not positive
+	// 每个shutdown hook的默认等待秒数, 不为正数时默认10
+	HookTimeout int `mapstructure:"hook_timeout" json:"hook_timeout"`
+	// CORS handle the cross-origin requests if set
+	// 设置时处理跨域请求
+	CORS *middleware.CORSConfig `mapstructure:"cors" json:"cors"`
+	// Secure set the security headers on every response if set
+	// 设置时为每个响应设置安全相关响应头
+	Secure *middleware.SecureConfig `mapstructure:"secure" json:"secure"`
+	// MaxBodySize the max bytes of request body, not limited if 0
+	// 请求体的最大字节数, 为0时不限制
+	MaxBodySize int64 `mapstructure:"max_body_size" json:"max_body_size"`
 	middlewares []middleware.Middleware
+	openAPI     []openapi.Option
+	encoder     result.Encoder
+	registry    *result.Registry
+	bundle      *result.Bundle
+	health      *healthConfig
+	metrics     []metrics.Option
+	admin       []admin.Option
+	hub         []ws.HubOption
+}
+
+type healthConfig struct {
+	checkers []health.Checker
+	options  []health.Option
 }
 
 type Option func(config *Config)
 
+const (
+	defaultShutdownTimeout = 30
+	defaultHookTimeout     = 10
+)
+
 func newConfig(options ...Option) Config {
 	config := Config{
-		Mode:        gin.DebugMode,
-		Name:        "",
-		Port:        8080,
-		middlewares: nil,
+		Mode:            gin.DebugMode,
+		Name:            "",
+		Port:            8080,
+		ShutdownTimeout: defaultShutdownTimeout,
+		HookTimeout:     defaultHookTimeout,
+		middlewares:     nil,
 	}
 	for _, option := range options {
 		option(&config)
@@ -58,8 +121,167 @@ func WithPort(port int) Option {
 	}
 }
 
+// WithTLS serve HTTPS and HTTP/2 on the port
+// 在端口上提供HTTPS与HTTP/2
+func WithTLS(tls TLSConfig) Option {
+	return func(config *Config) {
+		config.TLS = &tls
+	}
+}
+
+// WithH2C serve HTTP/2 without TLS on the port
+// 在端口上不使用TLS提供HTTP/2
+func WithH2C() Option {
+	return func(config *Config) {
+		config.H2C = true
+	}
+}
+
+// WithListeners serve the extra listeners together with the port
+// 与端口一起服务其他listener
+func WithListeners(listeners ...ListenerConfig) Option {
+	return func(config *Config) {
+		config.Listeners = append(config.Listeners, listeners...)
+	}
+}
+
+// WithListener serve the pre-opened listener together with the port, the TLS and H2C of listenerConfig are used
+// 与端口一起服务预先打开的listener, 使用listenerConfig中的TLS与H2C配置
+func WithListener(listener net.Listener, listenerConfig ...ListenerConfig) Option {
+	return func(config *Config) {
+		var lc ListenerConfig
+		if len(listenerConfig) > 0 {
+			lc = listenerConfig[0]
+		}
+		lc.listener = listener
+		config.Listeners = append(config.Listeners, lc)
+	}
+}
+
+// WithAdminPort serve the admin endpoints on the internal port, they are closed together with the server
+// 在内部端口上提供管理接口, 与server一起关闭
+func WithAdminPort(port int, options ...admin.Option) Option {
+	return func(config *Config) {
+		config.AdminPort = port
+		config.admin = append(config.admin, options...)
+	}
+}
+
+// WithHub set the options of the websocket hub of server, such as ws.WithBroker to fan out across the instances
+// 设置server的websocket hub的选项, 例如ws.WithBroker以在多个实例间分发
+func WithHub(options ...ws.HubOption) Option {
+	return func(config *Config) {
+		config.hub = append(config.hub, options...)
+	}
+}
+
+func WithDrainPeriod(drainPeriod int) Option {
+	return func(config *Config) {
+		config.DrainPeriod = drainPeriod
+	}
+}
+
+func WithShutdownTimeout(shutdownTimeout int) Option {
+	return func(config *Config) {
+		config.ShutdownTimeout = shutdownTimeout
+	}
+}
+
+func WithHookTimeout(hookTimeout int) Option {
+	return func(config *Config) {
+		config.HookTimeout = hookTimeout
+	}
+}
+
+// WithCORS handle the cross-origin requests before the middlewares set by WithMiddlewares
+// 在WithMiddlewares设置的中间件之前处理跨域请求
+func WithCORS(cors middleware.CORSConfig) Option {
+	return func(config *Config) {
+		config.CORS = &cors
+	}
+}
+
+// WithSecureHeaders set the security headers on every response, see middleware.DefaultSecureConfig
+// 为每个响应设置安全相关响应头, 参考middleware.DefaultSecureConfig
+func WithSecureHeaders(secure middleware.SecureConfig) Option {
+	return func(config *Config) {
+		config.Secure = &secure
+	}
+}
+
+func WithMaxBodySize(maxBodySize int64) Option {
+	return func(config *Config) {
+		config.MaxBodySize = maxBodySize
+	}
+}
+
 func WithMiddlewares(middlewares ...middleware.Middleware) Option {
 	return func(config *Config) {
 		config.middlewares = middlewares
 	}
 }
+
+// WithOpenAPI serve the OpenAPI document generated from the routes created by package handler,
+// the title defaults to the name of server
+// 提供由handler包创建的路由生成的OpenAPI文档, 标题默认为服务名
+func WithOpenAPI(options ...openapi.Option) Option {
+	return func(config *Config) {
+		config.openAPI = append([]openapi.Option{}, options...)
+	}
+}
+
+// WithEncoder set the encoder of the results rendered by the handlers of this server
+// 设置本server的handler渲染result时使用的编码器
+func WithEncoder(encoder result.Encoder) Option {
+	return func(config *Config) {
+		config.encoder = encoder
+	}
+}
+
+// WithRegistry set the registry of error codes used by the handlers of this server
+// 设置本server的handler使用的错误码registry
+func WithRegistry(registry *result.Registry) Option {
+	return func(config *Config) {
+		config.registry = registry
+	}
+}
+
+// WithBundle set the message bundle used by the handlers of this server to translate messages
+// 设置本server的handler翻译信息时使用的bundle
+func WithBundle(bundle *result.Bundle) Option {
+	return func(config *Config) {
+		config.bundle = bundle
+	}
+}
+
+// WithHealthChecks mount the liveness and readiness routes, the readiness runs the checkers
+// and fails when the server is shutting down
+// 挂载存活与就绪路由, 就绪探针会执行所有检查, 并在server关闭过程中失败
+func WithHealthChecks(checkers ...health.Checker) Option {
+	return func(config *Config) {
+		if config.health == nil {
+			config.health = &healthConfig{}
+		}
+		config.health.checkers = append(config.health.checkers, checkers...)
+	}
+}
+
+// WithHealthOptions set the paths and timeout of the health routes, the routes are mounted as WithHealthChecks
+// 设置健康检查路由的路径与超时时间, 同WithHealthChecks一样会挂载路由
+func WithHealthOptions(options ...health.Option) Option {
+	return func(config *Config) {
+		if config.health == nil {
+			config.health = &healthConfig{}
+		}
+		config.health.options = append(config.health.options, options...)
+	}
+}
+
+// WithMetrics record the metrics of all requests and serve them at "/metrics" by default,
+// they are served on the admin port instead of the server if WithAdminPort is set
+// 记录所有请求的指标, 并默认在"/metrics"提供, 设置WithAdminPort时在管理端口而非server上提供
+func WithMetrics(options ...metrics.Option) Option {
+	return func(config *Config) {
+		config.metrics = append(make([]metrics.Option, 0, len(options)), options...)
+	}
+}
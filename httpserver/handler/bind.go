@@ -0,0 +1,376 @@
+package handler
+
+import (
+	"encoding"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/pkg/errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	tagForm   = "form"
+	tagUri    = "uri"
+	tagHeader = "header"
+
+	defaultMemory = 32 << 20
+)
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	fileHeaderType      = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeadersType     = reflect.TypeOf([]*multipart.FileHeader(nil))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Bind Bind the request into obj in one pass and validate it once:
+// the JSON body is decoded first, then the fields are filled from the query or form by tag "form",
+// the path params by tag "uri" and the headers by tag "header", the latter overrides the former.
+// The tag supports a default value, example: `form:"page,default=1"`.
+// As gin does, the field without tag "form" is filled from the query or form by its name, and the field without
+// tag "uri" is filled from the path params by the name of tag "json" or its name, the headers are bound only by tag "header".
+// The tag "-" skips the source
+// 一次性将请求绑定到obj并只校验一次: 先解析JSON body, 再按tag "form"从query或表单, tag "uri"从路径参数,
+// tag "header"从请求头填充字段, 后者覆盖前者. tag支持默认值, 例: `form:"page,default=1"`.
+// 与gin一样, 没有tag "form"的字段按字段名从query或表单填充, 没有tag "uri"的字段按tag "json"的名称或字段名从路径参数填充,
+// 请求头只按tag "header"绑定. tag "-"表示跳过该来源
+func Bind(c *gin.Context, obj interface{}) error {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return errors.Errorf("bind object(%T) must be a non-nil pointer to struct", obj)
+	}
+
+	form, files, err := parseBody(c.Request, obj)
+	if err != nil {
+		return err
+	}
+	params := make(map[string][]string, len(c.Params))
+	for _, param := range c.Params {
+		params[param.Key] = []string{param.Value}
+	}
+	s := sources{form: form, files: files, uri: params, header: c.Request.Header}
+	if err = s.bindStruct(v.Elem()); err != nil {
+		return err
+	}
+	if binding.Validator == nil {
+		return nil
+	}
+	return binding.Validator.ValidateStruct(obj)
+}
+
+// parseBody Decode the JSON body into obj, or parse the form body and return it together with the query
+func parseBody(req *http.Request, obj interface{}) (map[string][]string, map[string][]*multipart.FileHeader, error) {
+	query := req.URL.Query()
+	if req.Body == nil || req.Method == http.MethodGet || req.Method == http.MethodHead {
+		return query, nil, nil
+	}
+	switch contentType := filterFlags(req.Header.Get("Content-Type")); contentType {
+	case binding.MIMEJSON:
+		decoder := json.NewDecoder(req.Body)
+		if binding.EnableDecoderUseNumber {
+			decoder.UseNumber()
+		}
+		if binding.EnableDecoderDisallowUnknownFields {
+			decoder.DisallowUnknownFields()
+		}
+		if err := decoder.Decode(obj); err != nil && err != io.EOF {
+			return nil, nil, err
+		}
+		return query, nil, nil
+	case binding.MIMEPOSTForm:
+		if err := req.ParseForm(); err != nil {
+			return nil, nil, err
+		}
+		return req.Form, nil, nil
+	case binding.MIMEMultipartPOSTForm:
+		if err := req.ParseMultipartForm(defaultMemory); err != nil {
+			return nil, nil, err
+		}
+		return req.Form, req.MultipartForm.File, nil
+	default:
+		return query, nil, nil
+	}
+}
+
+func filterFlags(content string) string {
+	for i, char := range content {
+		if char == ' ' || char == ';' {
+			return content[:i]
+		}
+	}
+	return content
+}
+
+type sources struct {
+	form   map[string][]string
+	files  map[string][]*multipart.FileHeader
+	uri    map[string][]string
+	header http.Header
+}
+
+func (s sources) bindStruct(v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		fieldV := v.Field(i)
+		if field.Anonymous && fieldV.Kind() == reflect.Struct && !hasBindTag(field) {
+			if err := s.bindStruct(fieldV); err != nil {
+				return err
+			}
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if err := s.bindField(fieldV, field); err != nil {
+			return errors.Wrapf(err, "bind field %s err", field.Name)
+		}
+	}
+	return nil
+}
+
+func hasBindTag(field reflect.StructField) bool {
+	for _, tag := range []string{tagForm, tagUri, tagHeader} {
+		if _, ok := field.Tag.Lookup(tag); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (s sources) bindField(v reflect.Value, field reflect.StructField) error {
+	var defaultValue *string
+	for _, tag := range []string{tagForm, tagUri, tagHeader} {
+		name, def, ok := fieldTag(field, tag)
+		if !ok {
+			continue
+		}
+		if def != nil && defaultValue == nil {
+			defaultValue = def
+		}
+		var vals []string
+		switch tag {
+		case tagForm:
+			if field.Type == fileHeaderType || field.Type == fileHeadersType {
+				if files := s.files[name]; len(files) > 0 {
+					if field.Type == fileHeaderType {
+						v.Set(reflect.ValueOf(files[0]))
+					} else {
+						v.Set(reflect.ValueOf(files))
+					}
+				}
+				continue
+			}
+			vals = s.form[name]
+		case tagUri:
+			vals = s.uri[name]
+		case tagHeader:
+			vals = s.header.Values(name)
+		}
+		if len(vals) == 0 {
+			continue
+		}
+		if err := setValues(v, field, vals); err != nil {
+			return err
+		}
+		defaultValue = nil
+	}
+	if defaultValue != nil && v.IsZero() {
+		return setValues(v, field, []string{*defaultValue})
+	}
+	return nil
+}
+
+// fieldTag The name of field in the source of tag, the field without the tag is bound from the form by its name,
+// and from the path params by the name of tag "json" or its name
+func fieldTag(field reflect.StructField, tag string) (name string, defaultValue *string, ok bool) {
+	if value, found := field.Tag.Lookup(tag); found {
+		return parseTag(value)
+	}
+	switch tag {
+	case tagForm:
+		return field.Name, nil, true
+	case tagUri:
+		if value, found := field.Tag.Lookup("json"); found {
+			if name = strings.Split(value, ",")[0]; name == "-" {
+				return "", nil, false
+			}
+			if name != "" {
+				return name, nil, true
+			}
+		}
+		return field.Name, nil, true
+	}
+	return "", nil, false
+}
+
+// parseTag Parse the tag like "name,default=value", "-" or empty tag means the field is not bound by this tag
+func parseTag(tag string) (name string, defaultValue *string, ok bool) {
+	if tag == "" || tag == "-" {
+		return "", nil, false
+	}
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	for _, part := range parts[1:] {
+		if strings.HasPrefix(part, "default=") {
+			def := strings.TrimPrefix(part, "default=")
+			defaultValue = &def
+		}
+	}
+	return name, defaultValue, name != ""
+}
+
+func setValues(v reflect.Value, field reflect.StructField, vals []string) error {
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 || reflect.PtrTo(v.Type()).Implements(textUnmarshalerType) {
+			break
+		}
+		slice := reflect.MakeSlice(v.Type(), len(vals), len(vals))
+		for i, val := range vals {
+			if err := setValue(slice.Index(i), field, val); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	case reflect.Array:
+		if len(vals) != v.Len() {
+			return errors.Errorf("%q is not valid value for %s", vals, v.Type())
+		}
+		for i, val := range vals {
+			if err := setValue(v.Index(i), field, val); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return setValue(v, field, vals[0])
+}
+
+func setValue(v reflect.Value, field reflect.StructField, val string) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setValue(v.Elem(), field, val)
+	}
+	switch v.Type() {
+	case durationType:
+		d, err := time.ParseDuration(val)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	case timeType:
+		return setTime(v, field, val)
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(val))
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(val)
+	case reflect.Bool:
+		if val == "" {
+			val = "false"
+		}
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if val == "" {
+			val = "0"
+		}
+		i, err := strconv.ParseInt(val, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if val == "" {
+			val = "0"
+		}
+		u, err := strconv.ParseUint(val, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		if val == "" {
+			val = "0"
+		}
+		f, err := strconv.ParseFloat(val, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.Uint8 {
+			return json.Unmarshal([]byte(val), v.Addr().Interface())
+		}
+		v.SetBytes([]byte(val))
+	case reflect.Struct, reflect.Map, reflect.Interface:
+		return json.Unmarshal([]byte(val), v.Addr().Interface())
+	default:
+		return errors.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// setTime Parse the time by tag "time_format" (default RFC3339, or "unix", "unixnano"), "time_utc" and "time_location",
+// the same as gin
+func setTime(v reflect.Value, field reflect.StructField, val string) error {
+	timeFormat := field.Tag.Get("time_format")
+	if timeFormat == "" {
+		timeFormat = time.RFC3339
+	}
+	switch tf := strings.ToLower(timeFormat); tf {
+	case "unix", "unixnano":
+		tv, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return err
+		}
+		if tf == "unix" {
+			v.Set(reflect.ValueOf(time.Unix(tv, 0)))
+		} else {
+			v.Set(reflect.ValueOf(time.Unix(0, tv)))
+		}
+		return nil
+	}
+	if val == "" {
+		v.Set(reflect.ValueOf(time.Time{}))
+		return nil
+	}
+	loc := time.Local
+	if isUTC, _ := strconv.ParseBool(field.Tag.Get("time_utc")); isUTC {
+		loc = time.UTC
+	}
+	if locTag := field.Tag.Get("time_location"); locTag != "" {
+		l, err := time.LoadLocation(locTag)
+		if err != nil {
+			return err
+		}
+		loc = l
+	}
+	t, err := time.ParseInLocation(timeFormat, val, loc)
+	if err != nil {
+		return err
+	}
+	v.Set(reflect.ValueOf(t))
+	return nil
+}
@@ -1,9 +1,17 @@
 package handler
 
+import "time"
+
 type config struct {
 	withCtx         bool
 	withoutResponse bool
 	withResult      bool
+	summary         string
+	description     string
+	tags            []string
+	deprecated      bool
+	timeout         time.Duration
+	heartbeat       time.Duration
 }
 
 type Option func(config *config)
@@ -63,3 +71,53 @@ func WithResult() Option {
 		conf.withResult = true
 	}
 }
+
+// WithSummary the summary of the operation in the API document
+// API文档中接口的摘要
+func WithSummary(summary string) Option {
+	return func(conf *config) {
+		conf.summary = summary
+	}
+}
+
+// WithDescription the description of the operation in the API document
+// API文档中接口的描述
+func WithDescription(description string) Option {
+	return func(conf *config) {
+		conf.description = description
+	}
+}
+
+// WithTags the tags used to group the operation in the API document
+// API文档中用于对接口分组的标签
+func WithTags(tags ...string) Option {
+	return func(conf *config) {
+		conf.tags = append(conf.tags, tags...)
+	}
+}
+
+// WithDeprecated mark the operation as deprecated in the API document
+// 在API文档中将接口标记为已废弃
+func WithDeprecated() Option {
+	return func(conf *config) {
+		conf.deprecated = true
+	}
+}
+
+// WithTimeout cancel the context passed to the handler method after d, and answer with 504 if the method overruns,
+// see ServeWithTimeout
+// 在d之后取消传给handler方法的context, 方法超时则以504响应, 参考ServeWithTimeout
+func WithTimeout(d time.Duration) Option {
+	return func(conf *config) {
+		conf.timeout = d
+	}
+}
+
+// WithHeartbeat send a comment line every d to keep the event stream of Stream alive through the proxies, default 15s,
+// no heartbeat is sent if d is negative
+// 每隔d发送一行注释以使Stream的事件流经过代理时保持连接, 默认15s, d为负数时不发送心跳
+func WithHeartbeat(d time.Duration) Option {
+	return func(conf *config) {
+		conf.heartbeat = d
+	}
+}
@@ -5,13 +5,14 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
 	"github.com/whereabouts/sdk/httpserver/handler/result"
+	"github.com/whereabouts/sdk/httpserver/handler/validation"
 	"github.com/whereabouts/sdk/logger"
-	"github.com/whereabouts/sdk/utils/mapper"
-	"net/http"
 	"reflect"
 )
 
 func init() {
+	// the field names in validation errors are taken from the tags, it must be set before any struct is validated
+	validation.Engine()
 }
 
 type requestKey struct{}
@@ -31,6 +32,7 @@ var (
 )
 
 //New
+// the signature of method is only checked at runtime, prefer Handle which is checked at compile time
 // example:
 // func Hello(ctx context.Context, req *proto.HelloHandlerReq) (*proto.HelloHandlerResp, error) {
 // 	 resp := proto.HelloHandlerResp{}
@@ -53,39 +55,20 @@ func newHandlerFuncWithLogger(method interface{}, conf config, l *logger.Logger)
 		panic(checkErr)
 	}
 
-	return func(c *gin.Context) {
-		ctx := c.Request.Context()
-		ctx = context.WithValue(ctx, RequestKey, c.Request)
-		ctx = context.WithValue(ctx, ResponseKey, c.Writer)
-		ctx = context.WithValue(ctx, GinContextKey, c)
-
-		var res *result.Result
+	meta := Meta{Request: reqT, WithoutResult: conf.withoutResponse}
+	if !conf.withResult && !conf.withoutResponse {
+		meta.Response = mV.Type().Out(0)
+	}
+	return describe(traced(timed(conf.timeout, func(c *gin.Context) {
+		ctx := newContext(c)
 
 		// bind request param
 		req := reflect.New(reqT)
-		if bindErr := c.ShouldBind(req.Interface()); bindErr != nil {
-			res = result.Failed(bindErr).WithStatusCode(http.StatusBadRequest)
-			c.JSON(res.StatusCode(), res)
+		if bindErr := Bind(c, req.Interface()); bindErr != nil {
+			renderBindErr(c, bindErr)
 			l.Errorf("method(%T) failed to bind: %v", method, bindErr)
 			return
 		}
-		// bind path param
-		reqM, convertErr := mapper.Struct2Map(req.Interface())
-		if convertErr != nil {
-			res = result.Failed(convertErr).WithStatusCode(http.StatusBadRequest)
-			c.JSON(res.StatusCode(), res)
-			l.Errorf("method(%T) failed to reconvert path param: %v", method, convertErr)
-			return
-		}
-		for _, param := range c.Params {
-			reqM[param.Key] = param.Value
-		}
-		if convertErr = mapper.Map2Struct(reqM, req.Interface()); convertErr != nil {
-			res = result.Failed(convertErr).WithStatusCode(http.StatusBadRequest)
-			c.JSON(res.StatusCode(), res)
-			l.Errorf("method(%T) failed to reconvert path param: %v", method, convertErr)
-			return
-		}
 
 		// handle request
 		var resultV []reflect.Value
@@ -97,29 +80,17 @@ func newHandlerFuncWithLogger(method interface{}, conf config, l *logger.Logger)
 
 		// do response
 		if conf.withResult {
-			res = resultV[0].Interface().(*result.Result)
+			res := resultV[0].Interface().(*result.Result)
 			if res == nil {
 				res = result.New()
 			}
-			c.PureJSON(res.StatusCode(), res)
+			renderResult(c, res)
 			return
 		}
 
-		var resp, err interface{}
-		if conf.withoutResponse {
-			err = resultV[0].Interface()
-		} else {
-			resp, err = resultV[0].Interface(), resultV[1].Interface()
-		}
-		// response contains err
-		if err != nil {
-			switch e := err.(type) {
-			case *result.Err:
-				res = result.Failed(e).WithCode(e.Code).WithStatusCode(e.StatusCode())
-			case error:
-				res = result.Failed(e)
-			}
-			c.JSON(res.StatusCode(), res)
+		// response contains err, the typed nil *result.Err is not an error
+		if errV := resultV[len(resultV)-1]; !errV.IsNil() {
+			renderErr(c, errV.Interface().(error))
 			return
 		}
 
@@ -127,9 +98,8 @@ func newHandlerFuncWithLogger(method interface{}, conf config, l *logger.Logger)
 			return
 		}
 
-		res = result.Succeed(resp)
-		c.PureJSON(res.StatusCode(), res)
-	}
+		renderResult(c, result.Succeed(resultV[0].Interface()))
+	})), meta, conf)
 }
 
 func checkMethod(method interface{}, conf config) (mV reflect.Value, reqT reflect.Type, err error) {
@@ -0,0 +1,324 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/whereabouts/sdk/httpserver/handler/result"
+	"github.com/whereabouts/sdk/httpserver/handler/validation"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type Page struct {
+	Page int `form:"page,default=1"`
+	Size int `form:"size,default=10"`
+}
+
+type typedReq struct {
+	Page
+	ID      int64         `uri:"id" binding:"required"`
+	Name    string        `json:"name" form:"name"`
+	Tags    []string      `form:"tag"`
+	Token   string        `header:"X-Token"`
+	Timeout time.Duration `form:"timeout"`
+	Since   time.Time     `form:"since" time_format:"2006-01-02"`
+	Enabled *bool         `form:"enabled"`
+}
+
+type typedResp struct {
+	Req typedReq `json:"req"`
+}
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func serve(h gin.HandlerFunc, method, path, target string, body string, header http.Header) *httptest.ResponseRecorder {
+	engine := gin.New()
+	engine.Handle(method, path, h)
+	req := httptest.NewRequest(method, target, bytes.NewReader([]byte(body)))
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func TestHandle(t *testing.T) {
+	var got typedReq
+	h := Handle(func(ctx context.Context, req *typedReq) (*typedResp, error) {
+		if GinContext(ctx) == nil {
+			t.Fatal("expect gin context in ctx")
+		}
+		got = *req
+		return &typedResp{Req: *req}, nil
+	})
+
+	header := http.Header{"Content-Type": {"application/json"}, "X-Token": {"secret"}}
+	w := serve(h, http.MethodPost, "/users/:id", "/users/7?size=20&tag=a&tag=b&timeout=3s&since=2021-08-01&enabled=true",
+		`{"name":"bob"}`, header)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expect 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got.ID != 7 || got.Name != "bob" || got.Token != "secret" || got.Page.Page != 1 || got.Size != 20 ||
+		strings.Join(got.Tags, ",") != "a,b" || got.Timeout != 3*time.Second ||
+		got.Since.Format("2006-01-02") != "2021-08-01" || got.Enabled == nil || !*got.Enabled {
+		t.Fatalf("unexpected bound request: %+v", got)
+	}
+	res := map[string]interface{}{}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if res["code"] != true {
+		t.Fatalf("expect code true, got %v", res["code"])
+	}
+
+	// the query overrides the body
+	w = serve(h, http.MethodPost, "/users/:id", "/users/7?name=tom", `{"name":"bob"}`, header)
+	if w.Code != http.StatusOK || got.Name != "tom" {
+		t.Fatalf("expect name tom, got %d %s", w.Code, got.Name)
+	}
+
+	// form body
+	w = serve(h, http.MethodPost, "/users/:id", "/users/7", "name=lisa&page=3",
+		http.Header{"Content-Type": {"application/x-www-form-urlencoded"}})
+	if w.Code != http.StatusOK || got.Name != "lisa" || got.Page.Page != 3 {
+		t.Fatalf("expect name lisa and page 3, got %d %+v", w.Code, got)
+	}
+}
+
+func TestHandleNotStruct(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expect panic when the request is not a struct")
+		}
+	}()
+	Handle(func(ctx context.Context, req *string) (*typedResp, error) {
+		return nil, nil
+	})
+}
+
+func TestDescribe(t *testing.T) {
+	hello := Handle(func(ctx context.Context, req *typedReq) (*typedResp, error) {
+		return &typedResp{Req: *req}, nil
+	}, WithSummary("hello"), WithTags("user"))
+	bye := HandleWithoutResponse(func(ctx context.Context, req *typedReq) error {
+		return nil
+	}, WithSummary("bye"))
+	meta, ok := Describe(hello)
+	if !ok || meta.Summary != "hello" || meta.Request != reflect.TypeOf(typedReq{}) || meta.Response != reflect.TypeOf(&typedResp{}) {
+		t.Fatalf("unexpected meta of hello: %+v", meta)
+	}
+	if meta, ok = Describe(bye); !ok || meta.Summary != "bye" || !meta.WithoutResult {
+		t.Fatalf("unexpected meta of bye: %+v", meta)
+	}
+	called := false
+	if _, ok = Describe(func(c *gin.Context) { called = true }); ok || called {
+		t.Fatal("expect the handler func of others not described and not called")
+	}
+	// the described handler func still serves
+	if w := serve(hello, http.MethodPost, "/users/:id", "/users/7", `{"name":"bob"}`, http.Header{"Content-Type": {"application/json"}, "X-Token": {"secret"}}); w.Code != http.StatusOK {
+		t.Fatalf("expect 200, got %d", w.Code)
+	}
+}
+
+func TestNewBind(t *testing.T) {
+	var got typedReq
+	h := New(func(ctx context.Context, req *typedReq) (*typedResp, error) {
+		got = *req
+		return &typedResp{Req: *req}, nil
+	})
+	// bound as Handle does
+	header := http.Header{"Content-Type": {"application/json"}, "X-Token": {"secret"}}
+	w := serve(h, http.MethodPost, "/users/:id", "/users/7?tag=a&tag=b", `{"name":"bob"}`, header)
+	if w.Code != http.StatusOK || got.ID != 7 || got.Name != "bob" || got.Token != "secret" || got.Page.Page != 1 ||
+		got.Size != 10 || strings.Join(got.Tags, ",") != "a,b" {
+		t.Fatalf("unexpected bound request: %d %+v", w.Code, got)
+	}
+	if w = serve(h, http.MethodGet, "/users", "/users", "", nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("expect 400, got %d", w.Code)
+	}
+}
+
+func TestBindFieldName(t *testing.T) {
+	var got struct {
+		Keyword string
+		UserID  int64  `json:"user_id"`
+		Slug    string `json:"-"`
+		Secret  string `form:"-" json:"secret"`
+	}
+	engine := gin.New()
+	engine.GET("/users/:user_id/:Slug", func(c *gin.Context) {
+		if err := Bind(c, &got); err != nil {
+			t.Fatal(err)
+		}
+	})
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/7/golang?Keyword=go&Secret=x&secret=y", nil))
+	// the field without tag is bound by its name as gin does, the path param by the json name,
+	// and the source skipped by "-" is not bound
+	if got.Keyword != "go" || got.UserID != 7 || got.Slug != "" || got.Secret != "" {
+		t.Fatalf("unexpected bound request: %+v", got)
+	}
+}
+
+func TestHandleBindErr(t *testing.T) {
+	h := Handle(func(ctx context.Context, req *typedReq) (*typedResp, error) {
+		return &typedResp{}, nil
+	})
+	// invalid uri param
+	if w := serve(h, http.MethodGet, "/users/:id", "/users/abc", "", nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("expect 400, got %d", w.Code)
+	}
+	// required uri param
+	w := serve(h, http.MethodGet, "/users", "/users", "", nil)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expect 400, got %d", w.Code)
+	}
+	res := struct {
+		Message string                  `json:"message"`
+		Data    []validation.FieldError `json:"data"`
+	}{}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Data) != 1 || res.Data[0].Field != "id" || res.Data[0].Rule != "required" || res.Message != res.Data[0].Message {
+		t.Fatalf("unexpected field errors: %s", w.Body.String())
+	}
+	// invalid json body
+	header := http.Header{"Content-Type": {"application/json"}}
+	if w := serve(h, http.MethodPost, "/users/:id", "/users/1", "{", header); w.Code != http.StatusBadRequest {
+		t.Fatalf("expect 400, got %d", w.Code)
+	}
+}
+
+func TestHandleErr(t *testing.T) {
+	h := Handle(func(ctx context.Context, req *Page) (*typedResp, error) {
+		if req.Page == 1 {
+			return nil, errors.New("unknown")
+		}
+		return nil, result.Error(1001, "forbidden").WithStatusCode(http.StatusForbidden)
+	})
+	if w := serve(h, http.MethodGet, "/", "/", "", nil); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"code":false`) {
+		t.Fatalf("unexpected response: %d %s", w.Code, w.Body.String())
+	}
+	if w := serve(h, http.MethodGet, "/", "/?page=2", "", nil); w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), `"code":1001`) {
+		t.Fatalf("unexpected response: %d %s", w.Code, w.Body.String())
+	}
+
+	hr := HandleResult(func(ctx context.Context, req *Page) *result.Result {
+		return result.Succeed(req.Size)
+	})
+	if w := serve(hr, http.MethodGet, "/", "/", "", nil); !strings.Contains(w.Body.String(), `"data":10`) {
+		t.Fatalf("unexpected response: %s", w.Body.String())
+	}
+
+	hw := HandleWithoutResponse(func(ctx context.Context, req *Page) error {
+		GinContext(ctx).String(http.StatusCreated, "created")
+		return nil
+	})
+	if w := serve(hw, http.MethodGet, "/", "/", "", nil); w.Code != http.StatusCreated || w.Body.String() != "created" {
+		t.Fatalf("unexpected response: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestEncoderAndRegistry(t *testing.T) {
+	registry := result.NewRegistry(result.LocaleEn)
+	errDenied := registry.Register(2001, http.StatusForbidden, map[string]string{result.LocaleEn: "denied"})
+	h := Handle(func(ctx context.Context, req *Page) (*typedResp, error) {
+		if req.Page == 2 {
+			return nil, errDenied
+		}
+		return &typedResp{}, nil
+	})
+	engine := gin.New()
+	engine.Use(UseEncoder(result.NewEncoder(result.WithKeys("errcode", "errmsg", "payload"), result.WithBoolCode(0, -1))))
+	engine.Use(UseRegistry(registry))
+	engine.GET("/", h)
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?page=2", nil))
+	if w.Code != http.StatusForbidden || w.Body.String() != `{"errcode":2001,"errmsg":"denied","payload":null}` {
+		t.Fatalf("unexpected response: %d %s", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Body.String(), `{"errcode":0,"errmsg":"","payload":{`) {
+		t.Fatalf("unexpected response: %d %s", w.Code, w.Body.String())
+	}
+
+	// typed nil *result.Err is not an error
+	hn := New(func(ctx context.Context, req *Page) (*typedResp, *result.Err) {
+		return &typedResp{}, nil
+	})
+	if w := serve(hn, http.MethodGet, "/", "/", "", nil); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"code":true`) {
+		t.Fatalf("unexpected response: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestLocale(t *testing.T) {
+	registry := result.NewRegistry(result.LocaleZhCN)
+	errDenied := registry.Register(3001, http.StatusForbidden, map[string]string{result.LocaleEn: "denied", result.LocaleZhCN: "拒绝"})
+	h := Handle(func(ctx context.Context, req *Page) (*typedResp, error) {
+		switch req.Page {
+		case 2:
+			return nil, errDenied
+		case 3:
+			return nil, result.Error(false, result.NotFound)
+		case 4:
+			return nil, errors.New(result.NotFound)
+		}
+		return nil, errors.New(result.Fail)
+	})
+	engine := gin.New()
+	engine.Use(UseRegistry(registry))
+	engine.GET("/", h)
+	engine.GET("/users", Handle(func(ctx context.Context, req *typedReq) (*typedResp, error) {
+		return &typedResp{}, nil
+	}))
+
+	cases := []struct {
+		target, acceptLanguage, message string
+	}{
+		{"/?page=2", "en-US,en;q=0.9", "denied"},
+		{"/?page=2", "zh-CN", "拒绝"},
+		{"/?page=3", "en", result.NotFound},
+		{"/?page=3", "zh", result.MessageNotFound},
+		{"/", "fr", result.Fail},
+		// the text of errors other than *result.Err is not translated
+		{"/?page=4", "zh-CN", result.NotFound},
+		{"/users", "en", "id is a required field"},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, c.target, nil)
+		req.Header.Set("Accept-Language", c.acceptLanguage)
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		res := struct {
+			Message string `json:"message"`
+		}{}
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatal(err)
+		}
+		if res.Message != c.message {
+			t.Fatalf("expect %q for %s in %s, got %q", c.message, c.target, c.acceptLanguage, res.Message)
+		}
+	}
+
+	// the locale in context takes precedence
+	req := httptest.NewRequest(http.MethodGet, "/?page=3", nil)
+	req = req.WithContext(result.WithLocale(req.Context(), result.LocaleEn))
+	req.Header.Set("Accept-Language", "zh-CN")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), result.NotFound) {
+		t.Fatalf("unexpected response %s", w.Body.String())
+	}
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"reflect"
+)
+
+// Meta The description of a handler func created by this package, used to generate the API document
+// 本包创建的handler func的描述信息, 用于生成API文档
+type Meta struct {
+	// Request the struct type of the request
+	// 请求的结构体类型
+	Request reflect.Type
+	// Response the type of the data in result.Result, nil if unknown
+	// result.Result中data的类型, 未知时为nil
+	Response reflect.Type
+	// WithoutResult the response is written by the method itself without the result.Result envelope
+	// 响应由方法自行写入, 没有result.Result包装
+	WithoutResult bool
+	Summary       string
+	Description   string
+	Tags          []string
+	Deprecated    bool
+}
+
+// describedKey the key of gin.Context.Keys to probe the described handler func, see Describe
+const describedKey = "github.com/whereabouts/sdk/httpserver/handler.described"
+
+// described The handler func created by this package with its description
+type described struct {
+	h    gin.HandlerFunc
+	meta Meta
+}
+
+// servePC the code pointer shared by all the method values of described.serve
+var servePC = reflect.ValueOf((&described{}).serve).Pointer()
+
+// Describe Get the description of the handler func, return false if it is not created by this package
+// 获取handler func的描述信息, 不是由本包创建时返回false
+func Describe(h gin.HandlerFunc) (Meta, bool) {
+	d, ok := describedOf(h)
+	if !ok {
+		return Meta{}, false
+	}
+	return d.meta, true
+}
+
+// Apply Apply the options to the handler func created by this package, such as the one of Handle or Stream,
+// the options of document are merged and WithTimeout wraps it. The options changing how the method is called,
+// such as WithContext, WithResult, WithoutResponse and WithHeartbeat, are fixed when it is created,
+// so an error is returned for them and for the handler funcs not created by this package
+// 将选项应用于本包创建的handler func, 例如Handle或Stream创建的, 文档相关的选项会被合并, WithTimeout会包装该handler func.
+// 改变方法调用方式的选项(例如WithContext, WithResult, WithoutResponse与WithHeartbeat)在创建时已确定,
+// 因此对这些选项以及非本包创建的handler func返回错误
+func Apply(h gin.HandlerFunc, options ...Option) (gin.HandlerFunc, error) {
+	d, ok := describedOf(h)
+	if !ok {
+		return nil, errors.New("the options can only be applied to the handler func created by package handler")
+	}
+	conf := newConfig(options...)
+	if conf.withCtx || conf.withResult || conf.withoutResponse || conf.heartbeat != 0 {
+		return nil, errors.New("the options changing how the method is called can not be applied to the handler func created")
+	}
+	meta := d.meta
+	if conf.summary != "" {
+		meta.Summary = conf.summary
+	}
+	if conf.description != "" {
+		meta.Description = conf.description
+	}
+	meta.Tags = append(append([]string{}, meta.Tags...), conf.tags...)
+	meta.Deprecated = meta.Deprecated || conf.deprecated
+	return (&described{h: timed(conf.timeout, d.h), meta: meta}).serve, nil
+}
+
+func describe(h gin.HandlerFunc, meta Meta, conf config) gin.HandlerFunc {
+	meta.Summary = conf.summary
+	meta.Description = conf.description
+	meta.Tags = conf.tags
+	meta.Deprecated = conf.deprecated
+	return (&described{h: h, meta: meta}).serve
+}
+
+func (d *described) serve(c *gin.Context) {
+	// the probe of describedOf never comes with a request
+	if c.Request == nil {
+		if probe, ok := c.Keys[describedKey].(**described); ok {
+			*probe = d
+			return
+		}
+	}
+	d.h(c)
+}
+
+// describedOf Get the described behind h, h is probed only if it is a method value of described.serve,
+// so that the handler funcs of others are never called
+func describedOf(h gin.HandlerFunc) (*described, bool) {
+	if h == nil || reflect.ValueOf(h).Pointer() != servePC {
+		return nil, false
+	}
+	var d *described
+	h(&gin.Context{Keys: map[string]interface{}{describedKey: &d}})
+	return d, d != nil
+}
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/whereabouts/sdk/httpserver/handler/result"
+	"github.com/whereabouts/sdk/httpserver/handler/validation"
+	"net/http"
+)
+
+const (
+	encoderKey  = "handler.encoder"
+	registryKey = "handler.registry"
+	bundleKey   = "handler.bundle"
+)
+
+// UseEncoder The middleware to set the encoder of result for the handlers after it, result.DefaultEncoder is used if not set
+// 为其后的handler设置result编码器的中间件, 未设置时使用result.DefaultEncoder
+func UseEncoder(encoder result.Encoder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(encoderKey, encoder)
+	}
+}
+
+// UseRegistry The middleware to set the registry of error codes for the handlers after it, result.DefaultRegistry is used if not set
+// 为其后的handler设置错误码registry的中间件, 未设置时使用result.DefaultRegistry
+func UseRegistry(registry *result.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(registryKey, registry)
+	}
+}
+
+// UseBundle The middleware to set the message bundle for the handlers after it, result.DefaultBundle is used if not set
+// 为其后的handler设置信息bundle的中间件, 未设置时使用result.DefaultBundle
+func UseBundle(bundle *result.Bundle) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(bundleKey, bundle)
+	}
+}
+
+func encoderOf(c *gin.Context) result.Encoder {
+	if encoder, ok := c.Value(encoderKey).(result.Encoder); ok && encoder != nil {
+		return encoder
+	}
+	return result.DefaultEncoder
+}
+
+func registryOf(c *gin.Context) *result.Registry {
+	if registry, ok := c.Value(registryKey).(*result.Registry); ok && registry != nil {
+		return registry
+	}
+	return result.DefaultRegistry
+}
+
+func bundleOf(c *gin.Context) *result.Bundle {
+	if bundle, ok := c.Value(bundleKey).(*result.Bundle); ok && bundle != nil {
+		return bundle
+	}
+	return result.DefaultBundle
+}
+
+// localeOf The locale set by result.WithLocale in the context of request, or the best match of Accept-Language
+// 请求context中由result.WithLocale设置的语言, 或与Accept-Language最匹配的语言
+func localeOf(c *gin.Context) string {
+	if locale := result.LocaleFrom(c.Request.Context()); locale != "" {
+		return locale
+	}
+	return bundleOf(c).Match(c.GetHeader("Accept-Language"))
+}
+
+// translate Replace the message of res by its translation if the message is a message id of the bundle,
+// it is used for the results and *result.Err made by the code, but not the text of other errors
+func translate(c *gin.Context, res *result.Result) *result.Result {
+	if res.Message == "" {
+		return res
+	}
+	if message, ok := bundleOf(c).Translate(localeOf(c), res.Message); ok {
+		res.WithMessage(message)
+	}
+	return res
+}
+
+func renderResult(c *gin.Context, res *result.Result) {
+	res = translate(c, res)
+	c.PureJSON(res.StatusCode(), encoderOf(c).Encode(res))
+}
+
+func renderFailure(c *gin.Context, res *result.Result) {
+	c.JSON(res.StatusCode(), encoderOf(c).Encode(res))
+}
+
+// renderBindErr Render the validation failures as a list of validation.FieldError in Data,
+// and the message of the first failure as Message
+// 将校验失败渲染为Data中的validation.FieldError列表, Message为第一个失败的信息
+func renderBindErr(c *gin.Context, err error) {
+	renderFailure(c, bindFailureOf(c, err))
+}
+
+func bindFailureOf(c *gin.Context, err error) *result.Result {
+	res := result.Failed(err).WithStatusCode(http.StatusBadRequest)
+	if fieldErrs, ok := validation.Translate(err, localeOf(c)); ok && len(fieldErrs) > 0 {
+		res.WithMessage(fieldErrs[0].Message).WithData(fieldErrs)
+	}
+	return res
+}
+
+// renderErr Render *result.Err by the registry with the message translated into the locale,
+// and other errors by result.Failed with the text of error as it is
+// 通过registry渲染*result.Err, 信息被翻译为对应语言, 其他错误通过result.Failed渲染, 错误文本保持原样
+func renderErr(c *gin.Context, err error) {
+	renderFailure(c, failureOf(c, err))
+}
+
+// failureOf The result rendering err, only the message of *result.Err is translated
+func failureOf(c *gin.Context, err error) *result.Result {
+	if e, ok := err.(*result.Err); ok {
+		return translate(c, registryOf(c).Render(e, localeOf(c)))
+	}
+	return result.Failed(err)
+}
+
+// AbortWithErr Render err as the handler methods do, and abort the handlers after it, it is used by the middlewares
+// 以handler方法的方式渲染err, 并中止其后的handler, 供中间件使用
+func AbortWithErr(c *gin.Context, err error) {
+	renderErr(c, err)
+	c.Abort()
+}
+
+// AbortWithResult Render res as the handler methods do, and abort the handlers after it, it is used by the middlewares
+// 以handler方法的方式渲染res, 并中止其后的handler, 供中间件使用
+func AbortWithResult(c *gin.Context, res *result.Result) {
+	renderFailure(c, translate(c, res))
+	c.Abort()
+}
+
+// Failure The body rendered for err as the handler methods do, it is used by the responses not written
+// by the handlers, such as the websocket messages
+// 以handler方法的方式渲染err得到的body, 供非handler写入的响应使用, 例如websocket消息
+func Failure(c *gin.Context, err error) interface{} {
+	return encoderOf(c).Encode(failureOf(c, err))
+}
+
+// BindFailure The body rendered for the error of binding or validation as the handler methods do
+// 以handler方法的方式渲染绑定或校验错误得到的body
+func BindFailure(c *gin.Context, err error) interface{} {
+	return encoderOf(c).Encode(bindFailureOf(c, err))
+}
@@ -19,6 +19,13 @@ const (
 	Forbidden    = "Forbidden"
 	NotFound     = "Not Found"
 
+	TokenMissing = "Token Missing"
+	TokenInvalid = "Token Invalid"
+	TokenExpired = "Token Expired"
+
+	RequestEntityTooLarge = "Request Entity Too Large"
+	TooManyRequests       = "Too Many Requests"
+
 	InternalServerError   = "Internal Server Error"
 	InternalServerTimeout = "Internal Server Processing Timeout"
 )
@@ -36,6 +43,13 @@ const (
 	MessageForbidden    = "没有权限, 请求被服务器拒绝了"
 	MessageNotFound     = "所请求的资源不存在"
 
+	MessageTokenMissing = "缺少身份令牌"
+	MessageTokenInvalid = "身份令牌无效"
+	MessageTokenExpired = "身份令牌已过期"
+
+	MessageRequestEntityTooLarge = "请求体过大"
+	MessageTooManyRequests       = "请求过于频繁, 请稍后再试"
+
 	MessageInternalServerError   = "服务器内部错误, 无法完成请求"
 	MessageInternalServerTimeout = "服务器处理超时"
 )
@@ -0,0 +1,110 @@
+package result
+
+const (
+	keyCode    = "code"
+	keyMessage = "message"
+	keyData    = "data"
+)
+
+// Encoder Convert the result into the body to render, so that the envelope can be customized
+// 将result转换为待渲染的body, 用于自定义响应的包装格式
+type Encoder interface {
+	Encode(res *Result) interface{}
+}
+
+// Envelope The encoder rendering the result as an object of code, message and data tells their keys,
+// so that the document of the responses follows it
+// 将result渲染为code, message, data对象的编码器提供其键名, 使响应的文档与之一致
+type Envelope interface {
+	Keys() (codeKey, messageKey, dataKey string)
+}
+
+type EncoderFunc func(res *Result) interface{}
+
+func (f EncoderFunc) Encode(res *Result) interface{} {
+	return f(res)
+}
+
+// DefaultEncoder render the result as {"code": true, "message": "", "data": {}}
+// 将result渲染为{"code": true, "message": "", "data": {}}
+var DefaultEncoder Encoder = defaultEncoder{}
+
+type defaultEncoder struct{}
+
+func (defaultEncoder) Encode(res *Result) interface{} {
+	return res
+}
+
+func (defaultEncoder) Keys() (codeKey, messageKey, dataKey string) {
+	return keyCode, keyMessage, keyData
+}
+
+type encoderConfig struct {
+	codeKey    string
+	messageKey string
+	dataKey    string
+	okCode     interface{}
+	failCode   interface{}
+	boolCode   bool
+}
+
+type EncoderOption func(config *encoderConfig)
+
+// NewEncoder Create the encoder with the keys renamed and the bool code replaced,
+// example: {"errcode": 0, "errmsg": "", "payload": {}}
+// 创建可重命名字段并替换bool类型code的编码器, 例: {"errcode": 0, "errmsg": "", "payload": {}}
+//
+//	result.NewEncoder(result.WithKeys("errcode", "errmsg", "payload"), result.WithBoolCode(0, -1))
+func NewEncoder(options ...EncoderOption) Encoder {
+	config := encoderConfig{codeKey: keyCode, messageKey: keyMessage, dataKey: keyData}
+	for _, option := range options {
+		option(&config)
+	}
+	return &encoder{config: config}
+}
+
+type encoder struct {
+	config encoderConfig
+}
+
+func (e *encoder) Encode(res *Result) interface{} {
+	code := res.Code
+	if b, ok := code.(bool); ok && e.config.boolCode {
+		if b {
+			code = e.config.okCode
+		} else {
+			code = e.config.failCode
+		}
+	}
+	return Json{e.config.codeKey: code, e.config.messageKey: res.Message, e.config.dataKey: res.Data}
+}
+
+func (e *encoder) Keys() (codeKey, messageKey, dataKey string) {
+	return e.config.codeKey, e.config.messageKey, e.config.dataKey
+}
+
+// WithKeys rename the keys of code, message and data, the empty key keeps the default
+// 重命名code, message, data的键名, 为空时保持默认
+func WithKeys(codeKey, messageKey, dataKey string) EncoderOption {
+	return func(config *encoderConfig) {
+		if codeKey != "" {
+			config.codeKey = codeKey
+		}
+		if messageKey != "" {
+			config.messageKey = messageKey
+		}
+		if dataKey != "" {
+			config.dataKey = dataKey
+		}
+	}
+}
+
+// WithBoolCode replace the bool code set by Succeed and Failed with okCode and failCode
+// 将Succeed与Failed设置的bool类型code替换为okCode与failCode
+func WithBoolCode(okCode, failCode interface{}) EncoderOption {
+	return func(config *encoderConfig) {
+		config.okCode = okCode
+		config.failCode = failCode
+		config.boolCode = true
+	}
+}
@@ -0,0 +1,210 @@
+package result
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/whereabouts/sdk/config"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type localeKey struct{}
+
+// WithLocale Set the locale into ctx, which takes precedence over Accept-Language in handler
+// 将语言设置到ctx中, 在handler中优先于Accept-Language
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// LocaleFrom Get the locale set by WithLocale, return empty string if not set
+// 获取WithLocale设置的语言, 未设置时返回空字符串
+func LocaleFrom(ctx context.Context) string {
+	locale, _ := ctx.Value(localeKey{}).(string)
+	return locale
+}
+
+// Bundle The messages keyed by locale and message id, the message id is case-insensitive,
+// usually the message itself in English is used as the id, such as BadRequest
+// 按语言与信息id索引的信息集合, 信息id不区分大小写, 通常使用英文信息本身作为id, 例如BadRequest
+type Bundle struct {
+	mu            sync.RWMutex
+	defaultLocale string
+	messages      map[string]map[string]string
+}
+
+// DefaultBundle the bundle used by handler unless another one is set for the server, the builtin messages in code.go are loaded
+// handler默认使用的bundle, 除非为server设置了其他bundle, 已加载code.go中的内置信息
+var DefaultBundle = NewBundle(LocaleZhCN)
+
+func init() {
+	en := make(map[string]string, len(builtinMessages))
+	for id := range builtinMessages {
+		en[id] = id
+	}
+	DefaultBundle.AddMessages(LocaleEn, en)
+	DefaultBundle.AddMessages(LocaleZhCN, builtinMessages)
+}
+
+var builtinMessages = map[string]string{
+	OK:                    MessageOK,
+	Fail:                  MessageFail,
+	Created:               MessageCreated,
+	Accepted:              MessageAccepted,
+	NoContent:             MessageNoContent,
+	ResetContent:          MessageResetContent,
+	BadRequest:            MessageBadRequest,
+	Unauthorized:          MessageUnauthorized,
+	Forbidden:             MessageForbidden,
+	NotFound:              MessageNotFound,
+	TokenMissing:          MessageTokenMissing,
+	TokenInvalid:          MessageTokenInvalid,
+	TokenExpired:          MessageTokenExpired,
+	RequestEntityTooLarge: MessageRequestEntityTooLarge,
+	TooManyRequests:       MessageTooManyRequests,
+	InternalServerError:   MessageInternalServerError,
+	InternalServerTimeout: MessageInternalServerTimeout,
+}
+
+func NewBundle(defaultLocale string) *Bundle {
+	return &Bundle{defaultLocale: defaultLocale, messages: make(map[string]map[string]string)}
+}
+
+func (b *Bundle) DefaultLocale() string {
+	return b.defaultLocale
+}
+
+// Locales The locales which have messages
+// 有信息的语言
+func (b *Bundle) Locales() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	locales := make([]string, 0, len(b.messages))
+	for locale := range b.messages {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// AddMessages Add the messages of locale keyed by message id, the existing messages are overwritten
+// 添加语言下按信息id索引的信息, 已存在的信息会被覆盖
+func (b *Bundle) AddMessages(locale string, messages map[string]string) *Bundle {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	m, ok := b.messages[locale]
+	if !ok {
+		m = make(map[string]string, len(messages))
+		b.messages[locale] = m
+	}
+	for id, message := range messages {
+		m[strings.ToLower(id)] = message
+	}
+	return b
+}
+
+// LoadFile Load the messages of locale from a flat JSON or YAML file, the type is decided by the file extension,
+// the message id in file must not contain "." which is treated as the separator of nested keys
+// 从扁平的JSON或YAML文件加载语言的信息, 文件类型由扩展名决定, 文件中的信息id不能包含"."(会被当作嵌套键的分隔符)
+//
+// example of zh-CN.yaml:
+//
+//	user not found: 用户不存在
+func (b *Bundle) LoadFile(locale string, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "open message file err")
+	}
+	defer file.Close()
+	return b.LoadReader(locale, strings.TrimPrefix(filepath.Ext(path), "."), file)
+}
+
+// LoadReader Load the messages of locale from reader, configType is "json" or "yaml"
+// 从reader加载语言的信息, configType为"json"或"yaml"
+func (b *Bundle) LoadReader(locale string, configType string, reader io.Reader) error {
+	messages := make(map[string]string)
+	if err := config.New().SetConfigType(configType).LoadWithReader(reader, &messages); err != nil {
+		return err
+	}
+	b.AddMessages(locale, messages)
+	return nil
+}
+
+// Translate Get the message of id in locale, fall back to the default locale, return false if not found
+// 获取id在指定语言下的信息, 不存在时回退到默认语言, 都不存在时返回false
+func (b *Bundle) Translate(locale string, id string) (string, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	id = strings.ToLower(id)
+	if message, ok := b.messages[locale][id]; ok {
+		return message, true
+	}
+	message, ok := b.messages[b.defaultLocale][id]
+	return message, ok
+}
+
+// Match Choose the best locale of the bundle for the Accept-Language header, example: "zh-CN,zh;q=0.9,en;q=0.8".
+// The language without region matches the locale with region and vice versa, such as "zh" and "zh-CN",
+// return the default locale if none matches.
+// 根据Accept-Language请求头选择bundle中最合适的语言, 例: "zh-CN,zh;q=0.9,en;q=0.8".
+// 不带地区的语言与带地区的语言互相匹配, 例如"zh"与"zh-CN", 都不匹配时返回默认语言
+func (b *Bundle) Match(acceptLanguage string) string {
+	locales := b.Locales()
+	for _, tag := range parseAcceptLanguage(acceptLanguage) {
+		for _, locale := range locales {
+			if strings.EqualFold(tag, locale) {
+				return locale
+			}
+		}
+		for _, locale := range locales {
+			if strings.EqualFold(baseLanguage(tag), baseLanguage(locale)) {
+				return locale
+			}
+		}
+	}
+	return b.defaultLocale
+}
+
+// parseAcceptLanguage Parse the language tags sorted by quality, the tags with quality 0 and "*" are dropped
+func parseAcceptLanguage(acceptLanguage string) []string {
+	type tag struct {
+		name    string
+		quality float64
+	}
+	tags := make([]tag, 0)
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		name := strings.TrimSpace(fields[0])
+		if name == "" || name == "*" {
+			continue
+		}
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if quality > 0 {
+			tags = append(tags, tag{name: strings.Replace(name, "_", "-", -1), quality: quality})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].quality > tags[j].quality
+	})
+	names := make([]string, 0, len(tags))
+	for _, t := range tags {
+		names = append(names, t.name)
+	}
+	return names
+}
+
+func baseLanguage(tag string) string {
+	return strings.SplitN(tag, "-", 2)[0]
+}
@@ -0,0 +1,127 @@
+package result
+
+import (
+	"github.com/pkg/errors"
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+const (
+	LocaleEn   = "en"
+	LocaleZhCN = "zh-CN"
+)
+
+// Entry The declaration of a business error code
+// 业务错误码的声明
+type Entry struct {
+	Code       int
+	StatusCode int
+	// Messages the messages keyed by locale
+	// 按语言索引的错误信息
+	Messages map[string]string
+}
+
+// Message Get the message in locale, fall back to the default locale of registry and then any message
+// 获取指定语言的信息, 不存在时依次回退到registry的默认语言与任意语言
+func (e Entry) Message(locale string, defaultLocale string) string {
+	if msg, ok := e.Messages[locale]; ok {
+		return msg
+	}
+	if msg, ok := e.Messages[defaultLocale]; ok {
+		return msg
+	}
+	for _, msg := range e.Messages {
+		return msg
+	}
+	return ""
+}
+
+// Registry The central declarations of business error codes with http status and messages in different locales
+// 集中声明业务错误码及其http状态码与多语言信息
+type Registry struct {
+	defaultLocale string
+	entries       sync.Map
+}
+
+// DefaultRegistry the registry used by handler unless another one is set for the server
+// handler默认使用的registry, 除非为server设置了其他registry
+var DefaultRegistry = NewRegistry(LocaleZhCN)
+
+func NewRegistry(defaultLocale string) *Registry {
+	return &Registry{defaultLocale: defaultLocale}
+}
+
+func (r *Registry) DefaultLocale() string {
+	return r.defaultLocale
+}
+
+// Register Declare the code and return an *Err with the message in the default locale,
+// panic if the code is declared repeatedly, the returned *Err is shared so do not modify it.
+// 声明错误码并返回默认语言信息的*Err, 重复声明时panic, 返回的*Err是共享的, 不要修改
+//
+// example:
+//
+//	var ErrUserNotFound = result.Register(10001, http.StatusNotFound, map[string]string{
+//		result.LocaleEn:   "user not found",
+//		result.LocaleZhCN: "用户不存在",
+//	})
+func (r *Registry) Register(code int, statusCode int, messages map[string]string) *Err {
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+	entry := Entry{Code: code, StatusCode: statusCode, Messages: messages}
+	if _, loaded := r.entries.LoadOrStore(code, entry); loaded {
+		panic(errors.Errorf("error code %d is registered repeatedly", code))
+	}
+	return Error(code, entry.Message(r.defaultLocale, r.defaultLocale)).WithStatusCode(statusCode)
+}
+
+// Lookup Get the declaration of code, the code can be any integer type
+// 获取错误码的声明, code可以为任意整数类型
+func (r *Registry) Lookup(code interface{}) (Entry, bool) {
+	c, ok := toInt(code)
+	if !ok {
+		return Entry{}, false
+	}
+	entry, ok := r.entries.Load(c)
+	if !ok {
+		return Entry{}, false
+	}
+	return entry.(Entry), true
+}
+
+// Render Convert err into the failed result, the status and message of the registered code are used
+// unless they are changed on err, the message is in locale.
+// 将err转换为失败的result, 若err未修改, 则使用已注册错误码的状态码与指定语言的信息
+func (r *Registry) Render(err *Err, locale string) *Result {
+	res := Failed(err).WithCode(err.Code).WithStatusCode(err.StatusCode())
+	entry, ok := r.Lookup(err.Code)
+	if !ok {
+		return res
+	}
+	if err.statusCode == 0 {
+		res.WithStatusCode(entry.StatusCode)
+	}
+	if err.Message == entry.Message(r.defaultLocale, r.defaultLocale) {
+		res.WithMessage(entry.Message(locale, r.defaultLocale))
+	}
+	return res
+}
+
+// Register Declare the code in DefaultRegistry
+// 在DefaultRegistry中声明错误码
+func Register(code int, statusCode int, messages map[string]string) *Err {
+	return DefaultRegistry.Register(code, statusCode, messages)
+}
+
+func toInt(code interface{}) (int, bool) {
+	v := reflect.ValueOf(code)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int(v.Uint()), true
+	}
+	return 0, false
+}
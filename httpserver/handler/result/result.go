@@ -35,6 +35,11 @@ func (res *Result) WithMessage(msg string) *Result {
 	return res
 }
 
+func (res *Result) WithData(data interface{}) *Result {
+	res.Data = data
+	return res
+}
+
 func New() *Result {
 	return &Result{}
 }
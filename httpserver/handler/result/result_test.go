@@ -0,0 +1,99 @@
+package result
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestEncoder(t *testing.T) {
+	encoder := NewEncoder(WithKeys("errcode", "errmsg", "payload"), WithBoolCode(0, -1))
+	data, _ := json.Marshal(encoder.Encode(Succeed("ok")))
+	if string(data) != `{"errcode":0,"errmsg":"","payload":"ok"}` {
+		t.Fatalf("unexpected body %s", data)
+	}
+	data, _ = json.Marshal(encoder.Encode(Failed(Error(1001, "fail")).WithCode(1001)))
+	if string(data) != `{"errcode":1001,"errmsg":"fail","payload":null}` {
+		t.Fatalf("unexpected body %s", data)
+	}
+	data, _ = json.Marshal(DefaultEncoder.Encode(Failed(Error(false, "fail"))))
+	if string(data) != `{"code":false,"message":"fail","data":null}` {
+		t.Fatalf("unexpected body %s", data)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry(LocaleEn)
+	errNotFound := registry.Register(10001, http.StatusNotFound, map[string]string{LocaleEn: "not found", LocaleZhCN: "不存在"})
+	if errNotFound.Error() != "not found" || errNotFound.StatusCode() != http.StatusNotFound {
+		t.Fatalf("unexpected err %+v", errNotFound)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expect panic of repeated code")
+			}
+		}()
+		registry.Register(10001, 0, nil)
+	}()
+
+	if entry, ok := registry.Lookup(int64(10001)); !ok || entry.StatusCode != http.StatusNotFound {
+		t.Fatalf("unexpected entry %+v", entry)
+	}
+	if _, ok := registry.Lookup("10001"); ok {
+		t.Fatal("expect string code not found")
+	}
+
+	res := registry.Render(errNotFound, LocaleZhCN)
+	if res.Code != 10001 || res.Message != "不存在" || res.StatusCode() != http.StatusNotFound {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	// the changed message is kept
+	res = registry.Render(Error(10001, "user 1 not found"), LocaleZhCN)
+	if res.Message != "user 1 not found" || res.StatusCode() != http.StatusNotFound {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	res = registry.Render(Error(10002, "unknown"), LocaleZhCN)
+	if res.Message != "unknown" || res.StatusCode() != http.StatusOK {
+		t.Fatalf("unexpected result %+v", res)
+	}
+}
+
+func TestBundle(t *testing.T) {
+	bundle := NewBundle(LocaleEn)
+	bundle.AddMessages(LocaleEn, map[string]string{"User Not Found": "user not found"})
+	if err := bundle.LoadReader(LocaleZhCN, "yaml", strings.NewReader("user not found: 用户不存在\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := bundle.LoadReader("ja", "json", strings.NewReader(`{"user not found": "ユーザーが見つかりません"}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]string{
+		"":                         LocaleEn,
+		"zh-CN,zh;q=0.9,en;q=0.8":  LocaleZhCN,
+		"zh-TW;q=0.5, en-US;q=0.8": LocaleEn,
+		"fr, ja;q=0.1":             "ja",
+		"fr, *":                    LocaleEn,
+		"en;q=0, zh":               LocaleZhCN,
+	}
+	for acceptLanguage, expect := range cases {
+		if locale := bundle.Match(acceptLanguage); locale != expect {
+			t.Fatalf("expect %s for %q, got %s", expect, acceptLanguage, locale)
+		}
+	}
+
+	if msg, ok := bundle.Translate(LocaleZhCN, "User Not Found"); !ok || msg != "用户不存在" {
+		t.Fatalf("unexpected translation %s", msg)
+	}
+	if msg, ok := bundle.Translate("fr", "user not found"); !ok || msg != "user not found" {
+		t.Fatalf("expect fall back to default locale, got %s", msg)
+	}
+	if _, ok := bundle.Translate(LocaleZhCN, "unknown"); ok {
+		t.Fatal("expect unknown message not found")
+	}
+	if msg, _ := DefaultBundle.Translate(LocaleZhCN, BadRequest); msg != MessageBadRequest {
+		t.Fatalf("unexpected builtin translation %s", msg)
+	}
+}
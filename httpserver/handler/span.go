@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/whereabouts/sdk/trace"
+	"net/http"
+)
+
+// traced Run h in a server span named by the method and route template, such as "GET /users/:id",
+// the trace is extracted from the request here if middleware.Trace is not used
+// 在以方法与路由模板(例如"GET /users/:id")命名的server span中执行h, 未使用middleware.Trace时在此从请求中提取trace
+func traced(h gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		if !trace.SpanContextFrom(ctx).IsValid() {
+			ctx = trace.Extract(ctx, c.Request.Header)
+		}
+		route := c.FullPath()
+		ctx, span := trace.Start(ctx, c.Request.Method+" "+route,
+			trace.WithKind(trace.KindServer),
+			trace.WithAttribute("http.method", c.Request.Method),
+			trace.WithAttribute("http.route", route),
+			trace.WithAttribute("http.target", c.Request.URL.RequestURI()),
+		)
+		c.Request = c.Request.WithContext(ctx)
+		defer func() {
+			status := c.Writer.Status()
+			span.SetAttribute("http.status_code", status)
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(trace.StatusError, http.StatusText(status))
+			}
+			span.End()
+		}()
+		h(c)
+	}
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"context"
+	"github.com/whereabouts/sdk/httpc"
+	"github.com/whereabouts/sdk/trace"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type spanReq struct {
+	ID int64 `uri:"id"`
+}
+
+func TestServerSpan(t *testing.T) {
+	exporter := trace.NewMemoryExporter()
+	p := trace.NewProvider(exporter)
+	trace.SetProvider(p)
+	defer trace.SetProvider(nil)
+
+	var downstream http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downstream = r.Header.Clone()
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+	client, err := httpc.NewClient(httpc.WithHost(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := Handle(func(ctx context.Context, req *spanReq) (*typedResp, error) {
+		_, err := client.NewRequest(ctx).Get("/profile")
+		return &typedResp{}, err
+	})
+	header := http.Header{}
+	header.Set(trace.HeaderTraceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	serve(h, http.MethodGet, "/users/:id", "/users/1", "", header)
+	if err = p.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := exporter.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("expect client and server spans, got %d", len(spans))
+	}
+	clientSpan, server := spans[0], spans[1]
+	if server.Name != "GET /users/:id" || server.Kind != trace.KindServer || server.Attributes["http.status_code"] != http.StatusOK {
+		t.Fatalf("unexpected server span %+v", server)
+	}
+	if server.SpanContext.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || server.ParentSpanID.String() != "00f067aa0ba902b7" {
+		t.Fatalf("expect the server span continues the incoming trace, got %+v", server)
+	}
+	if clientSpan.Kind != trace.KindClient || clientSpan.ParentSpanID != server.SpanContext.SpanID ||
+		clientSpan.Attributes["http.url"] != srv.URL+"/profile" || clientSpan.StatusCode != trace.StatusError {
+		t.Fatalf("unexpected client span %+v", clientSpan)
+	}
+	if downstream.Get(trace.HeaderTraceparent) != clientSpan.SpanContext.Traceparent() {
+		t.Fatalf("expect the client span forwarded, got %v", downstream)
+	}
+}
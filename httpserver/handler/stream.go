@@ -0,0 +1,292 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/whereabouts/sdk/logger"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	defaultHeartbeat = 15 * time.Second
+	// EventError the name of the event sent when the method of Stream fails after the stream is started,
+	// the data is the result.Result of the error
+	// Stream的方法在流开始后失败时发送的事件名, data为错误的result.Result
+	EventError = "error"
+)
+
+// Event A Server-Sent Event, Data of string or []byte is sent as it is, other types are encoded as JSON
+// 一个Server-Sent Event, string或[]byte类型的Data原样发送, 其他类型编码为JSON
+type Event struct {
+	ID    string
+	Event string
+	Data  interface{}
+	// Retry the milliseconds for the client to wait before reconnecting, not sent if zero
+	// 客户端重连前等待的毫秒数, 为0时不发送
+	Retry int
+}
+
+var fieldReplacer = strings.NewReplacer("\r", "", "\n", "")
+
+// encode Format the event in the text/event-stream format
+func (e Event) encode() (string, error) {
+	var data string
+	switch d := e.Data.(type) {
+	case string:
+		data = d
+	case []byte:
+		data = string(d)
+	default:
+		b, err := json.Marshal(d)
+		if err != nil {
+			return "", errors.Wrap(err, "encode event data err")
+		}
+		data = string(b)
+	}
+	var b strings.Builder
+	if e.ID != "" {
+		b.WriteString("id: " + fieldReplacer.Replace(e.ID) + "\n")
+	}
+	if e.Event != "" {
+		b.WriteString("event: " + fieldReplacer.Replace(e.Event) + "\n")
+	}
+	if e.Retry > 0 {
+		b.WriteString(fmt.Sprintf("retry: %d\n", e.Retry))
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		b.WriteString("data: " + line + "\n")
+	}
+	b.WriteString("\n")
+	return b.String(), nil
+}
+
+// Stream Create a handler func sending the events of method as Server-Sent Events, Req is bound as Handle does.
+// The channel is closed after method returns, the ctx is canceled when the client is gone, so method should
+// return when the ctx is done. A comment line is sent as heartbeat, see WithHeartbeat. If method fails before
+// sending any event, the error is rendered as Handle does, otherwise it is sent as the EventError event.
+// WithTimeout is ignored since the response is not buffered
+// 创建将方法的事件以Server-Sent Events发送的handler func, Req与Handle一样绑定. 方法返回后channel被关闭,
+// 客户端断开时ctx被取消, 因此方法应在ctx结束时返回. 以注释行作为心跳, 参考WithHeartbeat. 方法在发送任何事件前失败时
+// 与Handle一样渲染错误, 否则以EventError事件发送. 响应不被缓冲, 因此忽略WithTimeout
+//
+// example:
+//
+//	func Progress(ctx context.Context, req *proto.ProgressReq, events chan<- handler.Event) error {
+//		for i := 1; i <= 100; i++ {
+//			select {
+//			case events <- handler.Event{Event: "progress", Data: i}:
+//			case <-ctx.Done():
+//				return ctx.Err()
+//			}
+//		}
+//		return nil
+//	}
+//
+//	router.GET("/progress", handler.Stream(Progress))
+func Stream[Req any](method func(ctx context.Context, req *Req, events chan<- Event) error, options ...Option) gin.HandlerFunc {
+	conf := newConfig(options...)
+	heartbeat := conf.heartbeat
+	if heartbeat == 0 {
+		heartbeat = defaultHeartbeat
+	}
+	conf.timeout = 0
+	return newTypedHandlerFunc(logger.StandardLogger(), Meta{WithoutResult: true}, conf, func(c *gin.Context, ctx context.Context, req *Req) {
+		serveEvents(c, heartbeat, func(events chan<- Event) error {
+			return method(ctx, req, events)
+		})
+	})
+}
+
+type produced struct {
+	err      error
+	panicked interface{}
+}
+
+// serveEvents Write the events of produce until it returns, the writes stop when the client is gone,
+// but the events are still drained so that produce is never blocked
+func serveEvents(c *gin.Context, heartbeat time.Duration, produce func(events chan<- Event) error) {
+	events := make(chan Event)
+	done := make(chan produced, 1)
+	go func() {
+		var p produced
+		defer func() {
+			if r := recover(); r != nil {
+				p.panicked = r
+			}
+			done <- p
+			close(events)
+		}()
+		p.err = produce(events)
+	}()
+
+	w := &eventWriter{c: c}
+	var tick <-chan time.Time
+	if heartbeat > 0 {
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	gone, closed := c.Request.Context().Done(), false
+	for running := true; running; {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				running = false
+				break
+			}
+			if closed {
+				continue
+			}
+			data, err := e.encode()
+			if err != nil {
+				logger.WithContext(c.Request.Context()).Errorf("stream event %s err: %v", e.Event, err)
+				continue
+			}
+			closed = w.write(data) != nil
+		case <-tick:
+			closed = closed || w.write(": ping\n\n") != nil
+		case <-gone:
+			closed, gone = true, nil
+		}
+	}
+
+	p := <-done
+	if p.panicked != nil {
+		panic(p.panicked)
+	}
+	if p.err == nil || closed {
+		return
+	}
+	if !w.started {
+		renderErr(c, p.err)
+		return
+	}
+	res := failureOf(c, p.err)
+	if data, err := (Event{Event: EventError, Data: encoderOf(c).Encode(res)}).encode(); err == nil {
+		_ = w.write(data)
+	}
+}
+
+// eventWriter Write the text/event-stream response, the headers are sent on the first write
+type eventWriter struct {
+	c       *gin.Context
+	started bool
+}
+
+func (w *eventWriter) write(data string) error {
+	if !w.started {
+		header := w.c.Writer.Header()
+		header.Set("Content-Type", "text/event-stream")
+		header.Set("Cache-Control", "no-cache")
+		// disable the response buffering of nginx
+		header.Set("X-Accel-Buffering", "no")
+		w.c.Status(http.StatusOK)
+		w.c.Writer.WriteHeaderNow()
+		w.started = true
+	}
+	if _, err := w.c.Writer.WriteString(data); err != nil {
+		return err
+	}
+	w.c.Writer.Flush()
+	return nil
+}
+
+// Attachment The writer of the file sent by Download, the headers are sent on the first write or Flush,
+// so Name, ContentType and the headers got by Header should be set before. The response is chunked
+// 由Download发送的文件的writer, header在第一次写入或Flush时发送, 因此Name, ContentType与Header获取的header
+// 应在此之前设置. 响应以chunked编码发送
+type Attachment struct {
+	// Name the file name in Content-Disposition
+	// Content-Disposition中的文件名
+	Name string
+	// ContentType default application/octet-stream
+	// 默认application/octet-stream
+	ContentType string
+	w           gin.ResponseWriter
+	started     bool
+}
+
+func (a *Attachment) Header() http.Header {
+	return a.w.Header()
+}
+
+func (a *Attachment) Write(data []byte) (int, error) {
+	a.start()
+	return a.w.Write(data)
+}
+
+// Flush Send the data written to the client
+// 将已写入的数据发送给客户端
+func (a *Attachment) Flush() {
+	a.start()
+	a.w.Flush()
+}
+
+func (a *Attachment) start() {
+	if a.started {
+		return
+	}
+	a.started = true
+	header := a.w.Header()
+	contentType := a.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	header.Set("Content-Type", contentType)
+	disposition := "attachment"
+	if a.Name != "" {
+		if formatted := mime.FormatMediaType(disposition, map[string]string{"filename": a.Name}); formatted != "" {
+			disposition = formatted
+		}
+	}
+	header.Set("Content-Disposition", disposition)
+	header.Set("X-Content-Type-Options", "nosniff")
+	a.w.WriteHeader(http.StatusOK)
+	a.w.WriteHeaderNow()
+}
+
+// Download Create a handler func sending the file written by method as an attachment, Req is bound as Handle does.
+// If method fails before writing, the error is rendered as Handle does, otherwise the connection is aborted
+// so that the client does not take the truncated file as complete. WithTimeout is ignored since the response
+// is not buffered
+// 创建将方法写入的文件作为附件发送的handler func, Req与Handle一样绑定. 方法在写入前失败时与Handle一样渲染错误,
+// 否则中断连接, 使客户端不会将截断的文件当作完整文件. 响应不被缓冲, 因此忽略WithTimeout
+//
+// example:
+//
+//	func Export(ctx context.Context, req *proto.ExportReq, file *handler.Attachment) error {
+//		file.Name = "users.csv"
+//		file.ContentType = "text/csv"
+//		for _, user := range users {
+//			if _, err := fmt.Fprintf(file, "%d,%s\n", user.ID, user.Name); err != nil {
+//				return err
+//			}
+//		}
+//		return nil
+//	}
+//
+//	router.GET("/export", handler.Download(Export))
+func Download[Req any](method func(ctx context.Context, req *Req, file *Attachment) error, options ...Option) gin.HandlerFunc {
+	conf := newConfig(options...)
+	conf.timeout = 0
+	return newTypedHandlerFunc(logger.StandardLogger(), Meta{WithoutResult: true}, conf, func(c *gin.Context, ctx context.Context, req *Req) {
+		file := &Attachment{w: c.Writer}
+		err := method(ctx, req, file)
+		if err == nil {
+			file.start()
+			return
+		}
+		if !file.started {
+			renderErr(c, err)
+			return
+		}
+		logger.WithContext(ctx).Errorf("download %s aborted: %v", file.Name, err)
+		panic(http.ErrAbortHandler)
+	})
+}
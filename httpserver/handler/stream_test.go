@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type streamReq struct {
+	Count int  `form:"count"`
+	Fail  bool `form:"fail"`
+}
+
+func TestStream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/", Stream(func(ctx context.Context, req *streamReq, events chan<- Event) error {
+		for i := 0; i < req.Count; i++ {
+			events <- Event{ID: "1", Event: "progress", Data: map[string]int{"done": i}}
+		}
+		if req.Count > 0 {
+			events <- Event{Data: "line1\nline2", Retry: 1000}
+		}
+		if req.Fail {
+			return errors.New("stream failed")
+		}
+		return nil
+	}))
+	request := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?"+query, nil))
+		return w
+	}
+
+	w := request("count=2")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("unexpected response %d %v", w.Code, w.Header())
+	}
+	expect := "id: 1\nevent: progress\ndata: {\"done\":0}\n\n" +
+		"id: 1\nevent: progress\ndata: {\"done\":1}\n\n" +
+		"retry: 1000\ndata: line1\ndata: line2\n\n"
+	if w.Body.String() != expect {
+		t.Fatalf("unexpected events %q", w.Body.String())
+	}
+
+	// failed before any event, rendered as the other handlers
+	w = request("fail=true")
+	if w.Header().Get("Content-Type") == "text/event-stream" || !strings.Contains(w.Body.String(), `"message":"stream failed"`) {
+		t.Fatalf("expect the error rendered, got %d %s", w.Code, w.Body.String())
+	}
+
+	// failed after started, sent as the error event
+	w = request("count=1&fail=true")
+	if body := w.Body.String(); w.Code != http.StatusOK || !strings.Contains(body, "event: error\ndata: {") || !strings.Contains(body, "stream failed") {
+		t.Fatalf("expect the error event, got %q", w.Body.String())
+	}
+}
+
+func TestStreamHeartbeatAndDisconnect(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	canceled := make(chan struct{})
+	engine := gin.New()
+	engine.GET("/", Stream(func(ctx context.Context, req *streamReq, events chan<- Event) error {
+		events <- Event{Data: "start"}
+		<-ctx.Done()
+		close(canceled)
+		return ctx.Err()
+	}, WithHeartbeat(10*time.Millisecond)))
+	server := httptest.NewServer(engine)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	request, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	resp, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+	var lines []string
+	for len(lines) < 4 {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line != "\n" {
+			lines = append(lines, line)
+		}
+	}
+	if lines[0] != "data: start\n" || lines[1] != ": ping\n" {
+		t.Fatalf("expect the event and heartbeats, got %q", lines)
+	}
+
+	cancel()
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("expect the context of method canceled after the client is gone")
+	}
+}
+
+func TestDownload(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/", Download(func(ctx context.Context, req *streamReq, file *Attachment) error {
+		if req.Count == 0 {
+			return errors.New("nothing to export")
+		}
+		file.Name = "报表.csv"
+		file.ContentType = "text/csv"
+		for i := 0; i < req.Count; i++ {
+			if _, err := file.Write([]byte("row\n")); err != nil {
+				return err
+			}
+			file.Flush()
+		}
+		if req.Fail {
+			return errors.New("export failed")
+		}
+		return nil
+	}))
+	server := httptest.NewServer(engine)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/?count=3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if string(body) != "row\nrow\nrow\n" || resp.Header.Get("Content-Type") != "text/csv" ||
+		resp.Header.Get("Content-Disposition") != "attachment; filename*=utf-8''%E6%8A%A5%E8%A1%A8.csv" ||
+		len(resp.TransferEncoding) == 0 || resp.TransferEncoding[0] != "chunked" {
+		t.Fatalf("unexpected download %v %v %q", resp.Header, resp.TransferEncoding, body)
+	}
+
+	resp, err = http.Get(server.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if resp.Header.Get("Content-Disposition") != "" || !strings.Contains(string(body), `"message":"nothing to export"`) {
+		t.Fatalf("expect the error rendered, got %d %s", resp.StatusCode, body)
+	}
+
+	// the truncated file is not taken as complete
+	resp, err = http.Get(server.URL + "/?count=1&fail=true")
+	if err == nil {
+		_, err = ioutil.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+	}
+	if err == nil {
+		t.Fatal("expect the download aborted")
+	}
+}
@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/whereabouts/sdk/httpserver/handler/result"
+	"github.com/whereabouts/sdk/logger"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ServeWithTimeout Run next with a deadline of d in the context of request, and answer with 504 InternalServerTimeout
+// if next overruns. The response of next is buffered, and discarded if it is late. It returns after next returns,
+// so next should return when the context is done. It is used by WithTimeout and middleware.Timeout,
+// and not suitable for the streaming responses
+// 以d为期限的请求context执行next, 超时则以504 InternalServerTimeout响应. next的响应被缓冲, 超时后写入的响应被丢弃.
+// 在next返回后才返回, 因此next应在context结束时返回. 供WithTimeout与middleware.Timeout使用, 不适用于流式响应
+func ServeWithTimeout(c *gin.Context, d time.Duration, next gin.HandlerFunc) {
+	if d <= 0 {
+		next(c)
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+	defer cancel()
+	c.Request = c.Request.WithContext(ctx)
+	// the timeout response is prepared before next runs, because the context is not safe to read concurrently
+	res := translate(c, registryOf(c).Render(result.Error(result.CodeBoolFail, result.InternalServerTimeout).
+		WithStatusCode(http.StatusGatewayTimeout), localeOf(c)))
+	body := encoderOf(c).Encode(res)
+
+	original := c.Writer
+	w := &timeoutWriter{ResponseWriter: original, header: original.Header().Clone(), status: http.StatusOK, size: -1}
+	c.Writer = w
+	var (
+		panicked interface{}
+		late     bool
+	)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() {
+			panicked = recover()
+			late = ctx.Err() != nil
+		}()
+		next(c)
+	}()
+
+	answered := false
+	select {
+	case <-done:
+	case <-ctx.Done():
+		select {
+		case <-done:
+		default:
+			// answer without waiting next, the writes of next are discarded from now on
+			w.timeout()
+			writeTimeout(ctx, original, res.StatusCode(), body)
+			answered = true
+			<-done
+		}
+	}
+	c.Writer = original
+	if !answered && !late {
+		if panicked != nil {
+			panic(panicked)
+		}
+		w.flush()
+		return
+	}
+	if !answered {
+		writeTimeout(ctx, original, res.StatusCode(), body)
+	}
+	if panicked != nil {
+		logger.WithContext(ctx).Errorf("panic after timeout: %v", panicked)
+	}
+	c.Abort()
+}
+
+// writeTimeout Write the timeout response, nothing is written if the context is canceled because the client is gone
+func writeTimeout(ctx context.Context, w gin.ResponseWriter, status int, body interface{}) {
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		w.WriteHeader(status)
+		w.WriteHeaderNow()
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(status)
+	_, _ = w.Write(data)
+	w.Flush()
+}
+
+// timeoutWriter Buffer the response until the handler returns, the writes after timeout are discarded
+type timeoutWriter struct {
+	gin.ResponseWriter
+	mu       sync.Mutex
+	header   http.Header
+	buf      bytes.Buffer
+	status   int
+	size     int
+	timedOut bool
+}
+
+// timeout Mark the writer timed out, the writes after it are discarded
+func (w *timeoutWriter) timeout() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.timedOut = true
+}
+
+// flush Write the buffered response to the original writer
+func (w *timeoutWriter) flush() {
+	dst := w.ResponseWriter.Header()
+	for key := range dst {
+		if _, ok := w.header[key]; !ok {
+			dst.Del(key)
+		}
+	}
+	for key, values := range w.header {
+		dst[key] = values
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.size < 0 {
+		return
+	}
+	w.ResponseWriter.WriteHeaderNow()
+	_, _ = w.ResponseWriter.Write(w.buf.Bytes())
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if code > 0 && w.size < 0 && !w.timedOut {
+		w.status = code
+	}
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.size < 0 {
+		w.size = 0
+	}
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if w.size < 0 {
+		w.size = 0
+	}
+	n, err := w.buf.Write(data)
+	w.size += n
+	return n, err
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *timeoutWriter) Status() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.status
+}
+
+func (w *timeoutWriter) Size() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.size
+}
+
+func (w *timeoutWriter) Written() bool {
+	return w.Size() >= 0
+}
+
+// Flush the response is sent after the handler returns
+func (w *timeoutWriter) Flush() {}
+
+func (w *timeoutWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, errors.New("hijack is not supported with timeout")
+}
+
+// timed Run h by ServeWithTimeout if d is positive
+func timed(d time.Duration, h gin.HandlerFunc) gin.HandlerFunc {
+	if d <= 0 {
+		return h
+	}
+	return func(c *gin.Context) {
+		ServeWithTimeout(c, d, h)
+	}
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type timeoutReq struct {
+	Delay time.Duration `form:"delay"`
+}
+
+type timeoutResp struct {
+	Done bool `json:"done"`
+}
+
+func TestWithTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	late := make(chan struct{})
+	engine := gin.New()
+	engine.GET("/", Handle(func(ctx context.Context, req *timeoutReq) (*timeoutResp, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("expect the context has a deadline")
+		}
+		GinContext(ctx).Header("X-Handler", "1")
+		select {
+		case <-time.After(req.Delay):
+			return &timeoutResp{Done: true}, nil
+		case <-ctx.Done():
+			// the late response must be discarded
+			defer close(late)
+			return &timeoutResp{Done: true}, nil
+		}
+	}, WithTimeout(50*time.Millisecond)))
+	request := func(delay string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?delay="+delay, nil))
+		return w
+	}
+
+	w := request("1ms")
+	if w.Code != http.StatusOK || w.Header().Get("X-Handler") != "1" || !strings.Contains(w.Body.String(), `"done":true`) {
+		t.Fatalf("unexpected response %d %v %s", w.Code, w.Header(), w.Body.String())
+	}
+
+	w = request("1s")
+	<-late
+	if w.Code != http.StatusGatewayTimeout || w.Header().Get("X-Handler") != "" {
+		t.Fatalf("expect 504, got %d %v", w.Code, w.Header())
+	}
+	if body := w.Body.String(); !strings.Contains(body, "服务器处理超时") || strings.Contains(body, "done") {
+		t.Fatalf("unexpected timeout body %s", body)
+	}
+}
+
+func TestServeWithTimeoutPanic(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) {
+		defer func() {
+			if recover() != nil {
+				c.AbortWithStatus(http.StatusInternalServerError)
+			}
+		}()
+		c.Next()
+	})
+	engine.GET("/", func(c *gin.Context) {
+		ServeWithTimeout(c, time.Second, func(c *gin.Context) {
+			c.String(http.StatusOK, "partial")
+			panic("boom")
+		})
+	})
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusInternalServerError || w.Body.String() != "" {
+		t.Fatalf("expect the panic propagated and the partial response discarded, got %d %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/whereabouts/sdk/httpserver/handler/result"
+	"github.com/whereabouts/sdk/logger"
+	"reflect"
+)
+
+// Handle Create a handler func from a typed method, the signature is checked at compile time,
+// Req must be a struct and is bound by Bind, the response is wrapped by result.Succeed.
+// 根据带类型的方法创建handler func, 签名在编译期检查, Req必须为结构体并由Bind绑定, 响应由result.Succeed包装
+//
+// example:
+//
+//	func Hello(ctx context.Context, req *proto.HelloReq) (*proto.HelloResp, error) {
+//		resp := proto.HelloResp{}
+//		resp.Welcome = fmt.Sprintf("hello, %s!", req.Name)
+//		return &resp, nil
+//	}
+//
+//	router.GET("/hello", handler.Handle(Hello))
+func Handle[Req, Resp any](method func(ctx context.Context, req *Req) (*Resp, error), options ...Option) gin.HandlerFunc {
+	meta := Meta{Response: reflect.TypeOf((*Resp)(nil))}
+	return newTypedHandlerFunc(logger.StandardLogger(), meta, newConfig(options...), func(c *gin.Context, ctx context.Context, req *Req) {
+		resp, err := method(ctx, req)
+		if err != nil {
+			renderErr(c, err)
+			return
+		}
+		renderResult(c, result.Succeed(resp))
+	})
+}
+
+// HandleWithoutResponse The method deals with the response by itself, only the error is rendered,
+// the *gin.Context can be got by GinContext(ctx)
+// 方法自行处理响应, 只渲染错误, 可通过GinContext(ctx)获取*gin.Context
+func HandleWithoutResponse[Req any](method func(ctx context.Context, req *Req) error, options ...Option) gin.HandlerFunc {
+	meta := Meta{WithoutResult: true}
+	return newTypedHandlerFunc(logger.StandardLogger(), meta, newConfig(options...), func(c *gin.Context, ctx context.Context, req *Req) {
+		if err := method(ctx, req); err != nil {
+			renderErr(c, err)
+		}
+	})
+}
+
+// HandleResult The method returns the *result.Result to render directly
+// 方法直接返回用于渲染的*result.Result
+func HandleResult[Req any](method func(ctx context.Context, req *Req) *result.Result, options ...Option) gin.HandlerFunc {
+	return newTypedHandlerFunc(logger.StandardLogger(), Meta{}, newConfig(options...), func(c *gin.Context, ctx context.Context, req *Req) {
+		res := method(ctx, req)
+		if res == nil {
+			res = result.New()
+		}
+		renderResult(c, res)
+	})
+}
+
+// GinContext Get the *gin.Context from the ctx passed to the handler method
+// 从传给handler方法的ctx中获取*gin.Context
+func GinContext(ctx context.Context) *gin.Context {
+	c, _ := ctx.Value(GinContextKey).(*gin.Context)
+	return c
+}
+
+// newTypedHandlerFunc the options changing the signature of method, such as WithContext, WithoutResponse and WithResult,
+// are ignored, the variants of Handle are used instead. It panics if Req is not a struct, as New does
+func newTypedHandlerFunc[Req any](l *logger.Logger, meta Meta, conf config, handle func(c *gin.Context, ctx context.Context, req *Req)) gin.HandlerFunc {
+	meta.Request = reflect.TypeOf((*Req)(nil)).Elem()
+	if meta.Request.Kind() != reflect.Struct {
+		checkErr := errors.Errorf("the request(%s) of handler must be a struct to bind param", meta.Request)
+		l.Errorf("checkMethod err: %v", checkErr)
+		panic(checkErr)
+	}
+	return describe(traced(timed(conf.timeout, func(c *gin.Context) {
+		req := new(Req)
+		if err := Bind(c, req); err != nil {
+			renderBindErr(c, err)
+			l.Errorf("handler(%T) failed to bind: %v", req, err)
+			return
+		}
+		handle(c, newContext(c), req)
+	})), meta, conf)
+}
+
+func newContext(c *gin.Context) context.Context {
+	ctx := c.Request.Context()
+	ctx = context.WithValue(ctx, RequestKey, c.Request)
+	ctx = context.WithValue(ctx, ResponseKey, c.Writer)
+	ctx = context.WithValue(ctx, GinContextKey, c)
+	ctx = result.WithLocale(ctx, localeOf(c))
+	return ctx
+}
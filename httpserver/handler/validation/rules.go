@@ -0,0 +1,55 @@
+package validation
+
+import (
+	"github.com/go-playground/validator/v10"
+	"regexp"
+	"strings"
+)
+
+const (
+	RulePhone  = "phone"
+	RuleIDCard = "idcard"
+)
+
+type rule struct {
+	tag      string
+	fn       validator.Func
+	messages map[string]string
+}
+
+var builtinRules = []rule{
+	{
+		tag: RulePhone, fn: isPhone,
+		messages: map[string]string{LocaleEn: "{0} must be a valid phone number", LocaleZhCN: "{0}必须是有效的手机号码"},
+	},
+	{
+		tag: RuleIDCard, fn: isIDCard,
+		messages: map[string]string{LocaleEn: "{0} must be a valid ID card number", LocaleZhCN: "{0}必须是有效的身份证号码"},
+	},
+}
+
+var (
+	phoneRegexp  = regexp.MustCompile(`^1[3-9]\d{9}$`)
+	idCardRegexp = regexp.MustCompile(`^[1-9]\d{5}(18|19|20)\d{2}(0[1-9]|1[0-2])(0[1-9]|[12]\d|3[01])\d{3}[\dXx]$`)
+
+	idCardWeights = []int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+	idCardChecks  = "10X98765432"
+)
+
+// isPhone Mobile phone number of mainland China, example: 13800138000
+func isPhone(fl validator.FieldLevel) bool {
+	return phoneRegexp.MatchString(fl.Field().String())
+}
+
+// isIDCard 18-digit resident ID card number of mainland China with the check digit verified
+func isIDCard(fl validator.FieldLevel) bool {
+	id := strings.ToUpper(fl.Field().String())
+	if !idCardRegexp.MatchString(id) {
+		return false
+	}
+	sum := 0
+	for i, weight := range idCardWeights {
+		sum += int(id[i]-'0') * weight
+	}
+	return idCardChecks[sum%11] == id[17]
+}
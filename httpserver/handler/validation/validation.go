@@ -0,0 +1,173 @@
+package validation
+
+import (
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/zh"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	zhTranslations "github.com/go-playground/validator/v10/translations/zh"
+	"github.com/pkg/errors"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+const (
+	LocaleEn   = "en"
+	LocaleZhCN = "zh-CN"
+)
+
+// FieldError The validation failure of a field, Field is the name seen by the client, taken from the tag json, form, uri or header
+// 字段的校验失败信息, Field为客户端可见的名称, 取自tag json, form, uri或header
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+var (
+	once          sync.Once
+	validate      *validator.Validate
+	translators   = make(map[string]ut.Translator)
+	defaultLocale = LocaleZhCN
+
+	nameTags = []string{"json", "form", "uri", "header"}
+)
+
+// Engine Get the validator engine used by gin binding, the field names are taken from the tags and
+// the default translations and builtin rules are registered
+// 获取gin binding使用的校验引擎, 字段名取自tag, 并已注册默认翻译与内置规则
+func Engine() *validator.Validate {
+	once.Do(func() {
+		v, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			panic(errors.Errorf("unsupported validator engine %T", binding.Validator.Engine()))
+		}
+		v.RegisterTagNameFunc(fieldName)
+
+		uni := ut.New(en.New(), en.New(), zh.New())
+		enTrans, _ := uni.GetTranslator("en")
+		zhTrans, _ := uni.GetTranslator("zh")
+		if err := enTranslations.RegisterDefaultTranslations(v, enTrans); err != nil {
+			panic(err)
+		}
+		if err := zhTranslations.RegisterDefaultTranslations(v, zhTrans); err != nil {
+			panic(err)
+		}
+		translators[LocaleEn] = enTrans
+		translators[LocaleZhCN] = zhTrans
+		validate = v
+
+		for _, rule := range builtinRules {
+			if err := register(rule.tag, rule.fn, rule.messages); err != nil {
+				panic(err)
+			}
+		}
+	})
+	return validate
+}
+
+// SetDefaultLocale Set the locale used when the requested one is not supported, default "zh-CN"
+// 设置请求的语言不支持时使用的语言, 默认"zh-CN"
+func SetDefaultLocale(locale string) {
+	defaultLocale = normalizeLocale(locale)
+}
+
+// Register Register a custom rule with its messages keyed by locale, "{0}" in the message is replaced by the field name
+// and "{1}" by the param of the rule.
+// 注册自定义规则及按语言区分的提示信息, 信息中的"{0}"替换为字段名, "{1}"替换为规则参数
+//
+// example:
+//
+//	validation.Register("even", func(fl validator.FieldLevel) bool {
+//		return fl.Field().Int()%2 == 0
+//	}, map[string]string{validation.LocaleEn: "{0} must be even", validation.LocaleZhCN: "{0}必须为偶数"})
+func Register(tag string, fn validator.Func, messages map[string]string) error {
+	Engine()
+	return register(tag, fn, messages)
+}
+
+func register(tag string, fn validator.Func, messages map[string]string) error {
+	if err := validate.RegisterValidation(tag, fn); err != nil {
+		return err
+	}
+	for locale, message := range messages {
+		trans, ok := translators[normalizeLocale(locale)]
+		if !ok {
+			return errors.Errorf("unsupported locale %s of rule %s", locale, tag)
+		}
+		message := message
+		err := validate.RegisterTranslation(tag, trans, func(trans ut.Translator) error {
+			return trans.Add(tag, message, true)
+		}, func(trans ut.Translator, fe validator.FieldError) string {
+			t, err := trans.T(fe.Tag(), fe.Field(), fe.Param())
+			if err != nil {
+				return fe.Error()
+			}
+			return t
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Translate Convert the validation errors into field errors with messages in the locale,
+// the second return is false if err is not caused by validation
+// 将校验错误转换为指定语言的字段错误, err不是校验错误时第二个返回值为false
+func Translate(err error, locale string) ([]FieldError, bool) {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return nil, false
+	}
+	Engine()
+	trans, ok := translators[normalizeLocale(locale)]
+	if !ok {
+		trans = translators[defaultLocale]
+	}
+	fieldErrs := make([]FieldError, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		fieldErrs = append(fieldErrs, FieldError{
+			Field:   fieldPath(fe),
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: fe.Translate(trans),
+		})
+	}
+	return fieldErrs, true
+}
+
+// fieldPath The namespace of the field without the root struct, example: "address.city"
+func fieldPath(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+// fieldName The first name of nameTags, the tag ignoring the field, such as json:"-", falls through to the next one,
+// since the field may be bound from the others
+func fieldName(field reflect.StructField) string {
+	for _, tag := range nameTags {
+		name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+func normalizeLocale(locale string) string {
+	switch strings.ToLower(strings.Replace(locale, "_", "-", -1)) {
+	case "zh", "zh-cn", "zh-hans", "zh-hans-cn":
+		return LocaleZhCN
+	case "en", "en-us", "en-gb":
+		return LocaleEn
+	}
+	return locale
+}
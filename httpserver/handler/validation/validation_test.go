@@ -0,0 +1,68 @@
+package validation
+
+import (
+	"github.com/go-playground/validator/v10"
+	"testing"
+)
+
+type address struct {
+	City string `json:"city" binding:"required"`
+}
+
+type user struct {
+	Name    string  `json:"name" binding:"required"`
+	Age     int     `form:"age" binding:"gte=18"`
+	Phone   string  `json:"phone" binding:"omitempty,phone"`
+	IDCard  string  `json:"id_card" binding:"omitempty,idcard"`
+	Even    int     `json:"even" binding:"even"`
+	Token   string  `json:"-" header:"X-Token" binding:"required"`
+	Address address `json:"address"`
+}
+
+func TestTranslate(t *testing.T) {
+	err := Register("even", func(fl validator.FieldLevel) bool {
+		return fl.Field().Int()%2 == 0
+	}, map[string]string{LocaleEn: "{0} must be even", LocaleZhCN: "{0}必须为偶数"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = Register("odd", nil, map[string]string{"fr": "impair"}); err == nil {
+		t.Fatal("expect err of unsupported locale, got nil")
+	}
+
+	u := user{Age: 10, Phone: "12345", IDCard: "110105194912310021", Even: 1}
+	fieldErrs, ok := Translate(Engine().Struct(u), "en-US")
+	if !ok {
+		t.Fatal("expect validation errors")
+	}
+	expect := []FieldError{
+		{Field: "name", Rule: "required", Message: "name is a required field"},
+		{Field: "age", Rule: "gte", Param: "18", Message: "age must be 18 or greater"},
+		{Field: "phone", Rule: "phone", Message: "phone must be a valid phone number"},
+		{Field: "id_card", Rule: "idcard", Message: "id_card must be a valid ID card number"},
+		{Field: "even", Rule: "even", Message: "even must be even"},
+		{Field: "X-Token", Rule: "required", Message: "X-Token is a required field"},
+		{Field: "address.city", Rule: "required", Message: "city is a required field"},
+	}
+	if len(fieldErrs) != len(expect) {
+		t.Fatalf("expect %d errors, got %+v", len(expect), fieldErrs)
+	}
+	for i := range expect {
+		if fieldErrs[i] != expect[i] {
+			t.Fatalf("expect %+v, got %+v", expect[i], fieldErrs[i])
+		}
+	}
+
+	fieldErrs, _ = Translate(Engine().Struct(u), "zh")
+	if fieldErrs[0].Message != "name为必填字段" || fieldErrs[4].Message != "even必须为偶数" {
+		t.Fatalf("unexpected zh messages: %+v", fieldErrs)
+	}
+
+	u = user{Name: "bob", Age: 18, Phone: "13800138000", IDCard: "11010519491231002x", Token: "secret", Address: address{City: "beijing"}}
+	if err = Engine().Struct(u); err != nil {
+		t.Fatalf("expect valid, got %v", err)
+	}
+	if _, ok = Translate(nil, LocaleEn); ok {
+		t.Fatal("expect not validation errors")
+	}
+}
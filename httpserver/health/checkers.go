@@ -0,0 +1,62 @@
+package health
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/whereabouts/sdk/db/mongoc"
+	"github.com/whereabouts/sdk/db/mysqlc"
+	"github.com/whereabouts/sdk/db/redisc"
+	"github.com/whereabouts/sdk/emailc"
+	"github.com/whereabouts/sdk/httpc"
+)
+
+// Mongo Ping the primary of mongodb
+// ping mongodb的主节点
+func Mongo(name string, client *mongoc.Client) Checker {
+	return NewChecker(name, func(ctx context.Context) error {
+		return client.Kernel().Ping(ctx, nil)
+	})
+}
+
+// MySQL Ping the mysql
+// ping mysql
+func MySQL(name string, client *mysqlc.Client) Checker {
+	return NewChecker(name, func(ctx context.Context) error {
+		return client.Kernel().PingContext(ctx)
+	})
+}
+
+// Redis Ping the redis
+// ping redis
+func Redis(name string, client *redisc.Client) Checker {
+	return NewChecker(name, func(ctx context.Context) error {
+		return client.Kernel().Ping(ctx).Err()
+	})
+}
+
+// HTTP Send HEAD to url, which is relative to the base url of client if it is a path, the status >= 400 is unhealthy
+// 向url发送HEAD请求, url为路径时相对于client的base url, 状态码>=400视为不健康
+func HTTP(name string, client httpc.Client, url string) Checker {
+	return NewChecker(name, func(ctx context.Context) error {
+		resp, err := client.Kernel().R().SetContext(ctx).Head(url)
+		if err != nil {
+			return err
+		}
+		if resp.IsError() {
+			return errors.Errorf("unexpected status %s", resp.Status())
+		}
+		return nil
+	})
+}
+
+// Email Dial and authenticate to the smtp server
+// 连接并认证smtp服务器
+func Email(name string, client *emailc.Client) Checker {
+	return NewChecker(name, func(ctx context.Context) error {
+		closer, err := client.Kernel().Dial()
+		if err != nil {
+			return err
+		}
+		return closer.Close()
+	})
+}
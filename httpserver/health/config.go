@@ -0,0 +1,51 @@
+package health
+
+const (
+	defaultLivenessPath  = "/healthz"
+	defaultReadinessPath = "/readyz"
+	defaultTimeout       = 3
+)
+
+type Config struct {
+	// LivenessPath the path of liveness probe, which reports up as long as the process serves, default "/healthz"
+	// 存活探针的路径, 只要进程在服务就报告正常, 默认"/healthz"
+	LivenessPath string `mapstructure:"liveness_path" json:"liveness_path"`
+	// ReadinessPath the path of readiness probe, which runs the checkers, default "/readyz"
+	// 就绪探针的路径, 会执行所有检查, 默认"/readyz"
+	ReadinessPath string `mapstructure:"readiness_path" json:"readiness_path"`
+	// Timeout the seconds to wait each checker, default 3
+	// 每个检查的等待秒数, 默认3
+	Timeout int `mapstructure:"timeout" json:"timeout"`
+}
+
+type Option func(config *Config)
+
+func newConfig(options ...Option) Config {
+	config := Config{
+		LivenessPath:  defaultLivenessPath,
+		ReadinessPath: defaultReadinessPath,
+		Timeout:       defaultTimeout,
+	}
+	for _, option := range options {
+		option(&config)
+	}
+	return config
+}
+
+func WithLivenessPath(path string) Option {
+	return func(config *Config) {
+		config.LivenessPath = path
+	}
+}
+
+func WithReadinessPath(path string) Option {
+	return func(config *Config) {
+		config.ReadinessPath = path
+	}
+}
+
+func WithTimeout(timeout int) Option {
+	return func(config *Config) {
+		config.Timeout = timeout
+	}
+}
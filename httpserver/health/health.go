@@ -0,0 +1,143 @@
+package health
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Checker Check the health of a dependency, such as a database or a downstream service
+// 检查依赖的健康状况, 例如数据库或下游服务
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+type checker struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// NewChecker Create a checker with the check func
+// 使用检查函数创建checker
+func NewChecker(name string, check func(ctx context.Context) error) Checker {
+	return &checker{name: name, check: check}
+}
+
+func (c *checker) Name() string {
+	return c.name
+}
+
+func (c *checker) Check(ctx context.Context) error {
+	return c.check(ctx)
+}
+
+type Result struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+type Status struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks,omitempty"`
+}
+
+type Health struct {
+	config   Config
+	checkers []Checker
+	ready    func() bool
+}
+
+// New Create the health with checkers, ready reports whether the server is ready, nil means always ready
+// 使用checker创建health, ready报告server是否就绪, 为nil时总是就绪
+func New(ready func() bool, checkers []Checker, options ...Option) *Health {
+	return &Health{config: newConfig(options...), checkers: checkers, ready: ready}
+}
+
+// Check Run the checkers concurrently with the timeout, the status is down if any checker fails
+// 带超时地并发执行所有检查, 任一检查失败时状态为down
+func (h *Health) Check(ctx context.Context) Status {
+	status := Status{Status: StatusUp, Checks: make(map[string]Result, len(h.checkers))}
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, c := range h.checkers {
+		wg.Add(1)
+		go func(c Checker) {
+			defer wg.Done()
+			start := time.Now()
+			err := h.run(ctx, c)
+			res := Result{Status: StatusUp, Duration: time.Since(start).String()}
+			if err != nil {
+				res.Status, res.Error = StatusDown, err.Error()
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			status.Checks[c.Name()] = res
+			if err != nil {
+				status.Status = StatusDown
+			}
+		}(c)
+	}
+	wg.Wait()
+	return status
+}
+
+// run Run the checker and return when it returns or the timeout is reached
+func (h *Health) run(ctx context.Context, c Checker) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(h.config.Timeout)*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- errors.Errorf("panic: %v", r)
+			}
+		}()
+		done <- c.Check(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "check timeout")
+	}
+}
+
+// Liveness Always report up, the dependencies are not checked so that the process is not restarted by their failures
+// 总是报告up, 不检查依赖, 避免依赖故障导致进程被重启
+func (h *Health) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, Status{Status: StatusUp})
+}
+
+// Readiness Report 503 if the server is not ready, such as shutting down, or any checker fails
+// server未就绪(例如正在关闭)或任一检查失败时报告503
+func (h *Health) Readiness(c *gin.Context) {
+	if h.ready != nil && !h.ready() {
+		c.JSON(http.StatusServiceUnavailable, Status{Status: StatusDown})
+		return
+	}
+	status := h.Check(c.Request.Context())
+	code := http.StatusOK
+	if status.Status != StatusUp {
+		code = http.StatusServiceUnavailable
+	}
+	c.JSON(code, status)
+}
+
+// Register Mount the liveness and readiness routes
+// 挂载存活与就绪路由
+func (h *Health) Register(router gin.IRoutes) {
+	router.GET(h.config.LivenessPath, h.Liveness)
+	router.GET(h.config.ReadinessPath, h.Readiness)
+}
@@ -0,0 +1,130 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/whereabouts/sdk/db/mysqlc"
+	"github.com/whereabouts/sdk/db/redisc"
+	"github.com/whereabouts/sdk/httpc"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func get(engine *gin.Engine, path string) (int, Status) {
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	status := Status{}
+	_ = json.Unmarshal(w.Body.Bytes(), &status)
+	return w.Code, status
+}
+
+func TestHealth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ready := true
+	healthy := true
+	checkers := []Checker{
+		NewChecker("ok", func(ctx context.Context) error {
+			return nil
+		}),
+		NewChecker("flaky", func(ctx context.Context) error {
+			if healthy {
+				return nil
+			}
+			return errors.New("connection refused")
+		}),
+	}
+	engine := gin.New()
+	New(func() bool { return ready }, checkers, WithReadinessPath("/ready")).Register(engine)
+
+	if code, status := get(engine, "/healthz"); code != http.StatusOK || status.Status != StatusUp {
+		t.Fatalf("unexpected liveness %d %+v", code, status)
+	}
+	if code, status := get(engine, "/ready"); code != http.StatusOK || len(status.Checks) != 2 {
+		t.Fatalf("unexpected readiness %d %+v", code, status)
+	}
+
+	healthy = false
+	code, status := get(engine, "/ready")
+	if code != http.StatusServiceUnavailable || status.Checks["flaky"].Error != "connection refused" || status.Checks["ok"].Status != StatusUp {
+		t.Fatalf("unexpected readiness %d %+v", code, status)
+	}
+
+	healthy, ready = true, false
+	if code, _ = get(engine, "/ready"); code != http.StatusServiceUnavailable {
+		t.Fatalf("expect 503 when not ready, got %d", code)
+	}
+	if code, _ = get(engine, "/healthz"); code != http.StatusOK {
+		t.Fatalf("expect liveness up when not ready, got %d", code)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	h := New(nil, []Checker{
+		NewChecker("slow", func(ctx context.Context) error {
+			time.Sleep(3 * time.Second)
+			return nil
+		}),
+		NewChecker("panic", func(ctx context.Context) error {
+			panic("oops")
+		}),
+	}, WithTimeout(1))
+	start := time.Now()
+	status := h.Check(context.Background())
+	if time.Since(start) > 2*time.Second {
+		t.Fatal("expect the check to return at the timeout")
+	}
+	if status.Status != StatusDown || status.Checks["slow"].Status != StatusDown || status.Checks["panic"].Error != "panic: oops" {
+		t.Fatalf("unexpected status %+v", status)
+	}
+}
+
+func TestCheckers(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisClient, err := redisc.NewClientWithOptions(context.Background(), redisc.WithAddrs(mr.Addr()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, mock, err := sqlmock.NewWithDSN("health_mysql", sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.ExpectPing()
+	mysqlClient, err := mysqlc.NewClient(context.Background(), mysqlc.Config{Driver: "sqlmock", DSN: "health_mysql"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.ExpectPing().WillReturnError(errors.New("mysql is down"))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ok" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+	httpClient, err := httpc.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status := New(nil, []Checker{
+		Redis("redis", redisClient),
+		MySQL("mysql", mysqlClient),
+		HTTP("downstream", httpClient, srv.URL+"/ok"),
+		HTTP("broken", httpClient, srv.URL+"/broken"),
+	}).Check(context.Background())
+	expect := map[string]string{
+		"redis": StatusUp, "mysql": StatusDown, "downstream": StatusUp, "broken": StatusDown,
+	}
+	for name, s := range expect {
+		if status.Checks[name].Status != s {
+			t.Fatalf("expect %s %s, got %+v", name, s, status.Checks[name])
+		}
+	}
+}
@@ -1,4 +1,38 @@
 package hook
 
+import (
+	"context"
+	"strings"
+)
+
 type RunHook func()
-type ShutdownHook func()
+
+// ShutdownHook run in reverse registration order after the server stops accepting connections,
+// ctx is done when the timeout of the hook is reached
+// 在server停止接收连接后按注册的逆序执行, 达到该hook的超时时间时ctx结束
+type ShutdownHook func(ctx context.Context) error
+
+// ReloadHook run in registration order when the server receives SIGHUP
+// 在server收到SIGHUP时按注册顺序执行
+type ReloadHook func(ctx context.Context) error
+
+// Errors The errors aggregated from hooks and the server
+// 从hook与server中汇总的错误
+type Errors []error
+
+func (errs Errors) Error() string {
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Err Return nil if there is no error, otherwise errs itself
+// 没有错误时返回nil, 否则返回errs本身
+func (errs Errors) Err() error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
@@ -0,0 +1,114 @@
+package httpserver
+
+import (
+	"crypto/tls"
+	"github.com/pkg/errors"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+)
+
+const (
+	NetworkTCP  = "tcp"
+	NetworkUnix = "unix"
+	// NetworkFD the listener inherited as a file descriptor, such as by the socket activation of systemd,
+	// the address is the number of descriptor, example: "3"
+	// 以文件描述符继承的listener, 例如systemd的socket activation, address为描述符的编号, 例: "3"
+	NetworkFD = "fd"
+)
+
+type ListenerConfig struct {
+	// Network tcp, unix or fd, default tcp
+	// tcp, unix或fd, 默认tcp
+	Network string `mapstructure:"network" json:"network"`
+	// Address example: ":8443", "/var/run/app.sock" or "3"
+	// 例: ":8443", "/var/run/app.sock"或"3"
+	Address string `mapstructure:"address" json:"address"`
+	// TLS serve HTTPS and HTTP/2 if set
+	// 设置时提供HTTPS与HTTP/2
+	TLS *TLSConfig `mapstructure:"tls" json:"tls"`
+	// H2C serve HTTP/2 without TLS
+	// 不使用TLS提供HTTP/2
+	H2C bool `mapstructure:"h2c" json:"h2c"`
+	// listener the pre-opened listener set by WithListener, Network and Address are ignored if set
+	listener net.Listener
+}
+
+func (config ListenerConfig) String() string {
+	if config.listener != nil {
+		return config.listener.Addr().Network() + " " + config.listener.Addr().String()
+	}
+	if config.Network == "" {
+		return NetworkTCP + " " + config.Address
+	}
+	return config.Network + " " + config.Address
+}
+
+func (config ListenerConfig) listen() (net.Listener, error) {
+	if config.listener != nil {
+		return config.listener, nil
+	}
+	switch config.Network {
+	case "", NetworkTCP:
+		return net.Listen(NetworkTCP, config.Address)
+	case NetworkUnix:
+		// remove the socket file left by the last run
+		if info, err := os.Stat(config.Address); err == nil && info.Mode()&os.ModeSocket != 0 {
+			if err = os.Remove(config.Address); err != nil {
+				return nil, errors.Wrap(err, "remove stale unix socket err")
+			}
+		}
+		return net.Listen(NetworkUnix, config.Address)
+	case NetworkFD:
+		fd, err := strconv.Atoi(config.Address)
+		if err != nil || fd < 0 {
+			return nil, errors.Errorf("invalid file descriptor %q", config.Address)
+		}
+		file := os.NewFile(uintptr(fd), "listener-"+config.Address)
+		if file == nil {
+			return nil, errors.Errorf("invalid file descriptor %d", fd)
+		}
+		defer file.Close()
+		return net.FileListener(file)
+	default:
+		return nil, errors.Errorf("unsupported network %s, available: tcp unix fd", config.Network)
+	}
+}
+
+// binding A listener config with the http.Server serving it
+type binding struct {
+	config   ListenerConfig
+	server   *http.Server
+	reloader *certReloader
+}
+
+// newHandler Wrap the handler to serve HTTP/2 without TLS if h2c is set
+func newHandler(handler http.Handler, config ListenerConfig) http.Handler {
+	if !config.H2C {
+		return handler
+	}
+	return h2c.NewHandler(handler, &http2.Server{})
+}
+
+// listen Open the listener, which is wrapped by TLS if configured
+func (b *binding) listen() (net.Listener, error) {
+	var tlsConfig *tls.Config
+	if b.config.TLS != nil {
+		var err error
+		if tlsConfig, b.reloader, err = newTLSConfig(*b.config.TLS); err != nil {
+			return nil, err
+		}
+		tlsConfig.NextProtos = []string{http2.NextProtoTLS, "http/1.1"}
+	}
+	listener, err := b.config.listen()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+	return listener, nil
+}
@@ -0,0 +1,182 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/http2"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCert Write a self-signed certificate for 127.0.0.1, which can be used as the server, client and CA certificate
+func writeCert(t *testing.T, dir, name string) (certFile, keyFile string, cert tls.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	certFile, keyFile = filepath.Join(dir, name+".crt"), filepath.Join(dir, name+".key")
+	if err = ioutil.WriteFile(certFile, certPem, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(keyFile, keyPem, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if cert, err = tls.X509KeyPair(certPem, keyPem); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile, cert
+}
+
+func TestMultipleListeners(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, _ := writeCert(t, dir, "server")
+	_, _, clientCert := writeCert(t, dir, "client")
+	tlsListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h2cListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	socket := filepath.Join(dir, "server.sock")
+
+	s := NewServer(WithPort(-1), WithMode(ModeTest),
+		WithListener(tlsListener, ListenerConfig{TLS: &TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientCAFile: filepath.Join(dir, "client.crt")}}),
+		WithListener(h2cListener, ListenerConfig{H2C: true}),
+		WithListeners(ListenerConfig{Network: NetworkUnix, Address: socket}),
+	).Routes(func(engine *gin.Engine) {
+		engine.GET("/proto", func(c *gin.Context) {
+			c.String(http.StatusOK, c.Request.Proto)
+		})
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Run(ctx)
+	}()
+	waitReady(t, s)
+
+	get := func(client *http.Client, url string) (string, error) {
+		resp, err := client.Get(url)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		return string(body), err
+	}
+
+	// mTLS with HTTP/2
+	tlsClient := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: true, Certificates: []tls.Certificate{clientCert}},
+		ForceAttemptHTTP2: true,
+	}}
+	if proto, err := get(tlsClient, "https://"+tlsListener.Addr().String()+"/proto"); err != nil || proto != "HTTP/2.0" {
+		t.Fatalf("expect HTTP/2.0 over tls, got %s, err: %v", proto, err)
+	}
+	noCertClient := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	if _, err = get(noCertClient, "https://"+tlsListener.Addr().String()+"/proto"); err == nil {
+		t.Fatal("expect the client without certificate rejected")
+	}
+
+	// h2c
+	h2cClient := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLS: func(network, addr string, cfg *tls.Config) (net.Conn, error) {
+			return net.Dial(network, addr)
+		},
+	}}
+	if proto, err := get(h2cClient, "http://"+h2cListener.Addr().String()+"/proto"); err != nil || proto != "HTTP/2.0" {
+		t.Fatalf("expect HTTP/2.0 over h2c, got %s, err: %v", proto, err)
+	}
+
+	// unix socket
+	unixClient := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, NetworkUnix, socket)
+		},
+	}}
+	if proto, err := get(unixClient, "http://unix/proto"); err != nil || proto != "HTTP/1.1" {
+		t.Fatalf("expect HTTP/1.1 over unix socket, got %s, err: %v", proto, err)
+	}
+
+	cancel()
+	if err = <-done; err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(socket); !os.IsNotExist(err) {
+		t.Fatalf("expect the socket file removed, got %v", err)
+	}
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, first := writeCert(t, dir, "server")
+	r, err := newCertReloader(certFile, keyFile, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.interval = 0
+	cert, _ := r.GetCertificate(nil)
+	if string(cert.Certificate[0]) != string(first.Certificate[0]) {
+		t.Fatal("expect the first certificate")
+	}
+
+	// rotate the files, the mod time is changed explicitly because the resolution may be coarse
+	_, _, second := writeCert(t, dir, "server")
+	later := time.Now().Add(time.Minute)
+	if err = os.Chtimes(certFile, later, later); err != nil {
+		t.Fatal(err)
+	}
+	cert, _ = r.GetCertificate(nil)
+	if string(cert.Certificate[0]) != string(second.Certificate[0]) {
+		t.Fatal("expect the rotated certificate")
+	}
+
+	// the certificate in use is kept if the new files are broken
+	if err = ioutil.WriteFile(keyFile, []byte("broken"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	later = later.Add(time.Minute)
+	if err = os.Chtimes(keyFile, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if cert, _ = r.GetCertificate(nil); string(cert.Certificate[0]) != string(second.Certificate[0]) {
+		t.Fatal("expect the certificate kept")
+	}
+}
@@ -0,0 +1,39 @@
+package metrics
+
+const (
+	defaultPath = "/metrics"
+)
+
+type Config struct {
+	// Path the path to serve the metrics, default "/metrics"
+	// 指标的访问路径, 默认"/metrics"
+	Path     string `mapstructure:"path" json:"path"`
+	registry *Registry
+}
+
+type Option func(config *Config)
+
+func newConfig(options ...Option) Config {
+	config := Config{
+		Path:     defaultPath,
+		registry: DefaultRegistry,
+	}
+	for _, option := range options {
+		option(&config)
+	}
+	return config
+}
+
+func WithPath(path string) Option {
+	return func(config *Config) {
+		config.Path = path
+	}
+}
+
+// WithRegistry record and serve the metrics by registry instead of DefaultRegistry
+// 使用registry而非DefaultRegistry记录与提供指标
+func WithRegistry(registry *Registry) Option {
+	return func(config *Config) {
+		config.registry = registry
+	}
+}
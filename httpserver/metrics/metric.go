@@ -0,0 +1,175 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	TypeCounter   = "counter"
+	TypeGauge     = "gauge"
+	TypeHistogram = "histogram"
+)
+
+var (
+	// DefaultBuckets the default buckets of latency in seconds
+	// 默认的耗时分桶, 单位秒
+	DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+	// DefaultSizeBuckets the default buckets of size in bytes
+	// 默认的大小分桶, 单位字节
+	DefaultSizeBuckets = []float64{64, 256, 1024, 4096, 16384, 65536, 262144, 1048576, 4194304}
+
+	nameRegexp  = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// series One time series of a metric, identified by the label values
+type series struct {
+	labelValues []string
+	value       float64
+	// buckets the non-cumulative counts of each bucket of histogram
+	buckets []uint64
+	count   uint64
+}
+
+// vec The labeled time series of a metric, the label values must match the labels in number,
+// otherwise it panics as a programming error
+type vec struct {
+	name    string
+	help    string
+	typ     string
+	labels  []string
+	buckets []float64
+	mu      sync.Mutex
+	series  map[string]*series
+}
+
+func newVec(name, help, typ string, labels []string, buckets []float64) *vec {
+	if !nameRegexp.MatchString(name) {
+		panic(fmt.Sprintf("metrics: invalid metric name %q", name))
+	}
+	for _, label := range labels {
+		if !labelRegexp.MatchString(label) || label == "le" {
+			panic(fmt.Sprintf("metrics: invalid label name %q of metric %s", label, name))
+		}
+	}
+	return &vec{name: name, help: help, typ: typ, labels: labels, buckets: buckets, series: make(map[string]*series)}
+}
+
+// with Run f with the series of label values, the series is created if not exists
+func (v *vec) with(labelValues []string, f func(s *series)) {
+	if len(labelValues) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: metric %s expects %d label values, got %d", v.name, len(v.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	s, ok := v.series[key]
+	if !ok {
+		s = &series{labelValues: append([]string(nil), labelValues...)}
+		if v.typ == TypeHistogram {
+			s.buckets = make([]uint64, len(v.buckets))
+		}
+		v.series[key] = s
+	}
+	f(s)
+}
+
+// snapshot Copy the series sorted by label values
+func (v *vec) snapshot() []series {
+	v.mu.Lock()
+	list := make([]series, 0, len(v.series))
+	for _, s := range v.series {
+		cp := *s
+		cp.buckets = append([]uint64(nil), s.buckets...)
+		list = append(list, cp)
+	}
+	v.mu.Unlock()
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i].labelValues, list[j].labelValues
+		for k := range a {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return false
+	})
+	return list
+}
+
+// Counter A cumulative metric that only increases, such as the count of requests
+// 只增不减的累计指标, 例如请求数
+type Counter struct {
+	*vec
+}
+
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *Counter) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		panic(fmt.Sprintf("metrics: counter %s can not decrease", c.name))
+	}
+	c.with(labelValues, func(s *series) {
+		s.value += delta
+	})
+}
+
+// Gauge A metric that can go up and down, such as the count of in-flight requests
+// 可增可减的指标, 例如处理中的请求数
+type Gauge struct {
+	*vec
+}
+
+func (g *Gauge) Set(value float64, labelValues ...string) {
+	g.with(labelValues, func(s *series) {
+		s.value = value
+	})
+}
+
+func (g *Gauge) Inc(labelValues ...string) {
+	g.Add(1, labelValues...)
+}
+
+func (g *Gauge) Dec(labelValues ...string) {
+	g.Add(-1, labelValues...)
+}
+
+func (g *Gauge) Add(delta float64, labelValues ...string) {
+	g.with(labelValues, func(s *series) {
+		s.value += delta
+	})
+}
+
+// Histogram Count the observations in buckets, such as the latency of requests
+// 按分桶统计观测值, 例如请求耗时
+type Histogram struct {
+	*vec
+}
+
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	i := sort.SearchFloat64s(h.buckets, value)
+	h.with(labelValues, func(s *series) {
+		if i < len(s.buckets) {
+			s.buckets[i]++
+		}
+		s.count++
+		s.value += value
+	})
+}
+
+func normalizeBuckets(buckets []float64) []float64 {
+	list := make([]float64, 0, len(buckets))
+	for _, b := range buckets {
+		if !math.IsInf(b, 1) {
+			list = append(list, b)
+		}
+	}
+	sort.Float64s(list)
+	return list
+}
@@ -0,0 +1,191 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultRegistry the registry used by middleware.Metrics and served by WithMetrics of httpserver
+// middleware.Metrics使用以及httpserver的WithMetrics提供的registry
+var DefaultRegistry = NewRegistry()
+
+// Registry Hold the metrics and expose them in the Prometheus text format
+// 持有所有指标并以Prometheus文本格式暴露
+type Registry struct {
+	mu      sync.RWMutex
+	metrics map[string]*vec
+}
+
+func NewRegistry() *Registry {
+	return &Registry{metrics: make(map[string]*vec)}
+}
+
+// Counter Get the counter of name, it is created if not exists.
+// It panics if the name is registered as another type or with different labels
+// 获取name对应的counter, 不存在时创建. 若name已注册为其他类型或不同的label则panic
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	return &Counter{r.getOrCreate(name, help, TypeCounter, labels, nil)}
+}
+
+// Gauge Get the gauge of name, it is created if not exists.
+// It panics if the name is registered as another type or with different labels
+// 获取name对应的gauge, 不存在时创建. 若name已注册为其他类型或不同的label则panic
+func (r *Registry) Gauge(name, help string, labels ...string) *Gauge {
+	return &Gauge{r.getOrCreate(name, help, TypeGauge, labels, nil)}
+}
+
+// Histogram Get the histogram of name, it is created with the buckets if not exists, the bucket +Inf is implied.
+// It panics if the name is registered as another type or with different labels
+// 获取name对应的histogram, 不存在时以buckets创建, 隐含+Inf分桶. 若name已注册为其他类型或不同的label则panic
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	return &Histogram{r.getOrCreate(name, help, TypeHistogram, labels, normalizeBuckets(buckets))}
+}
+
+func (r *Registry) getOrCreate(name, help, typ string, labels []string, buckets []float64) *vec {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if v, ok := r.metrics[name]; ok {
+		if v.typ != typ || strings.Join(v.labels, ",") != strings.Join(labels, ",") {
+			panic(fmt.Sprintf("metrics: metric %s is registered as %s%v", name, v.typ, v.labels))
+		}
+		return v
+	}
+	v := newVec(name, help, typ, labels, buckets)
+	r.metrics[name] = v
+	return v
+}
+
+// WriteTo Write all metrics sorted by name in the Prometheus text format
+// 以Prometheus文本格式按名称顺序写出所有指标
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.RLock()
+	list := make([]*vec, 0, len(r.metrics))
+	for _, v := range r.metrics {
+		list = append(list, v)
+	}
+	r.mu.RUnlock()
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].name < list[j].name
+	})
+
+	cw := &countWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	for _, v := range list {
+		writeVec(bw, v)
+	}
+	err := bw.Flush()
+	return cw.n, err
+}
+
+// ServeHTTP Serve the metrics to the scraper, the registry can be mounted by gin.WrapH
+// 向采集端提供指标, 可通过gin.WrapH挂载
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	_, _ = r.WriteTo(w)
+}
+
+func writeVec(w *bufio.Writer, v *vec) {
+	if v.help != "" {
+		_, _ = fmt.Fprintf(w, "# HELP %s %s\n", v.name, escapeHelp(v.help))
+	}
+	_, _ = fmt.Fprintf(w, "# TYPE %s %s\n", v.name, v.typ)
+	for _, s := range v.snapshot() {
+		if v.typ != TypeHistogram {
+			writeSample(w, v.name, v.labels, s.labelValues, "", s.value)
+			continue
+		}
+		var cumulative uint64
+		for i, upper := range v.buckets {
+			cumulative += s.buckets[i]
+			writeSample(w, v.name+"_bucket", v.labels, s.labelValues, formatFloat(upper), float64(cumulative))
+		}
+		writeSample(w, v.name+"_bucket", v.labels, s.labelValues, "+Inf", float64(s.count))
+		writeSample(w, v.name+"_sum", v.labels, s.labelValues, "", s.value)
+		writeSample(w, v.name+"_count", v.labels, s.labelValues, "", float64(s.count))
+	}
+}
+
+func writeSample(w *bufio.Writer, name string, labels, labelValues []string, le string, value float64) {
+	_, _ = w.WriteString(name)
+	if len(labels) > 0 || le != "" {
+		_ = w.WriteByte('{')
+		for i, label := range labels {
+			if i > 0 {
+				_ = w.WriteByte(',')
+			}
+			_, _ = fmt.Fprintf(w, `%s="%s"`, label, escapeLabelValue(labelValues[i]))
+		}
+		if le != "" {
+			if len(labels) > 0 {
+				_ = w.WriteByte(',')
+			}
+			_, _ = fmt.Fprintf(w, `le="%s"`, le)
+		}
+		_ = w.WriteByte('}')
+	}
+	_ = w.WriteByte(' ')
+	_, _ = w.WriteString(formatFloat(value))
+	_ = w.WriteByte('\n')
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+var (
+	helpReplacer  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpReplacer.Replace(s)
+}
+
+func escapeLabelValue(s string) string {
+	return labelReplacer.Replace(s)
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+// Register Mount the metrics route on router and return the registry to record, which is DefaultRegistry by default
+// 在router上挂载指标路由, 并返回用于记录的registry, 默认为DefaultRegistry
+func Register(router gin.IRoutes, options ...Option) *Registry {
+	config := newConfig(options...)
+	router.GET(config.Path, gin.WrapH(config.registry))
+	return config.registry
+}
+
+// Route Get the path to serve the metrics and the registry to record, to mount the route on another server,
+// such as the admin port by admin.WithHandler
+// 获取提供指标的路径与用于记录的registry, 以便在其他server上挂载路由, 例如通过admin.WithHandler挂载到管理端口
+func Route(options ...Option) (string, *Registry) {
+	config := newConfig(options...)
+	return config.Path, config.registry
+}
@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteTo(t *testing.T) {
+	r := NewRegistry()
+	counter := r.Counter("jobs_total", "Total jobs.\nDone or failed.", "queue", "result")
+	counter.Inc("mail", "ok")
+	counter.Add(2, "mail", "ok")
+	counter.Inc(`a"b\c`, "failed")
+	r.Gauge("workers", "").Set(3)
+	h := r.Histogram("latency_seconds", "Latency.", []float64{1, 0.1}, "queue")
+	h.Observe(0.05, "mail")
+	h.Observe(0.5, "mail")
+	h.Observe(5, "mail")
+	// get the registered counter by name
+	r.Counter("jobs_total", "", "queue", "result").Inc("mail", "ok")
+
+	buf := new(bytes.Buffer)
+	n, err := r.WriteTo(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `# HELP jobs_total Total jobs.\nDone or failed.
+# TYPE jobs_total counter
+jobs_total{queue="a\"b\\c",result="failed"} 1
+jobs_total{queue="mail",result="ok"} 4
+# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{queue="mail",le="0.1"} 1
+latency_seconds_bucket{queue="mail",le="1"} 2
+latency_seconds_bucket{queue="mail",le="+Inf"} 3
+latency_seconds_sum{queue="mail"} 5.55
+latency_seconds_count{queue="mail"} 3
+# TYPE workers gauge
+workers 3
+`
+	if buf.String() != expect {
+		t.Fatalf("expect:\n%s\ngot:\n%s", expect, buf.String())
+	}
+	if n != int64(buf.Len()) {
+		t.Fatalf("expect %d bytes written, got %d", buf.Len(), n)
+	}
+}
+
+func TestRegisterConflict(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("requests_total", "", "method")
+	for _, f := range []func(){
+		func() { r.Gauge("requests_total", "", "method") },
+		func() { r.Counter("requests_total", "", "route") },
+		func() { r.Counter("requests_total", "").Inc("GET") },
+		func() { r.Counter("invalid-name", "") },
+		func() { r.Counter("requests_total", "", "method").Add(-1, "GET") },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expect panic")
+				}
+			}()
+			f()
+		}()
+	}
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/whereabouts/sdk/httpserver/handler"
+	"github.com/whereabouts/sdk/httpserver/handler/result"
+	"net/http"
+)
+
+// BodyLimit Limit the size of request body to maxBytes, the request with a larger Content-Length is aborted with 413,
+// reading beyond the limit from a body of unknown length fails
+// 限制请求体大小为maxBytes, Content-Length超出的请求以413中止, 未知长度的请求体读取超出限制时失败
+func BodyLimit(maxBytes int64) Middleware {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > maxBytes {
+			handler.AbortWithErr(c, result.Error(result.CodeBoolFail, result.RequestEntityTooLarge).
+				WithStatusCode(http.StatusRequestEntityTooLarge))
+			return
+		}
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBodyLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(BodyLimit(8))
+	engine.POST("/", func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+		c.String(http.StatusOK, string(body))
+	})
+	request := func(body string, chunked bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		if chunked {
+			req.ContentLength = -1
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := request("12345678", false); w.Code != http.StatusOK {
+		t.Fatalf("expect 200, got %d", w.Code)
+	}
+	if w := request("123456789", false); w.Code != http.StatusRequestEntityTooLarge || !strings.Contains(w.Body.String(), "请求体过大") {
+		t.Fatalf("expect 413, got %d %s", w.Code, w.Body.String())
+	}
+	if w := request("123456789", true); w.Code != http.StatusBadRequest {
+		t.Fatalf("expect reading the chunked body beyond the limit fails, got %d", w.Code)
+	}
+}
@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var defaultCORSMethods = []string{
+	http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodHead, http.MethodOptions,
+}
+
+type CORSConfig struct {
+	// AllowOrigins the origins allowed, "*" allows all, and a wildcard matches a part of origin,
+	// example: "https://*.example.com"
+	// 允许的源, "*"允许所有, 通配符匹配源的一部分, 例: "https://*.example.com"
+	AllowOrigins []string `mapstructure:"allow_origins" json:"allow_origins"`
+	// AllowMethods default GET, POST, PUT, PATCH, DELETE, HEAD and OPTIONS
+	// 默认GET, POST, PUT, PATCH, DELETE, HEAD与OPTIONS
+	AllowMethods []string `mapstructure:"allow_methods" json:"allow_methods"`
+	// AllowHeaders the headers requested by the preflight are all allowed if empty
+	// 为空时允许预检请求的所有请求头
+	AllowHeaders  []string `mapstructure:"allow_headers" json:"allow_headers"`
+	ExposeHeaders []string `mapstructure:"expose_headers" json:"expose_headers"`
+	// AllowCredentials the origin is echoed instead of "*" if true
+	// 为true时回写请求的源而不是"*"
+	AllowCredentials bool `mapstructure:"allow_credentials" json:"allow_credentials"`
+	// MaxAge the seconds the preflight result can be cached, not set if 0
+	// 预检结果可缓存的秒数, 为0时不设置
+	MaxAge int `mapstructure:"max_age" json:"max_age"`
+}
+
+// CORS Handle the cross-origin requests, the preflight is answered with 204 and not passed to the handlers,
+// the preflight from a disallowed origin is answered with 403
+// 处理跨域请求, 预检请求以204响应且不传给handler, 来自不允许的源的预检请求以403响应
+func CORS(config CORSConfig) Middleware {
+	allowAll := false
+	for _, origin := range config.AllowOrigins {
+		if origin == "*" {
+			allowAll = true
+		}
+	}
+	methods := config.AllowMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+	allowMethods := strings.ToUpper(strings.Join(methods, ", "))
+	allowHeaders := strings.Join(config.AllowHeaders, ", ")
+	exposeHeaders := strings.Join(config.ExposeHeaders, ", ")
+	maxAge := ""
+	if config.MaxAge > 0 {
+		maxAge = strconv.Itoa(config.MaxAge)
+	}
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		if !allowAll && !matchOrigin(config.AllowOrigins, origin) {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		header := c.Writer.Header()
+		if allowAll && !config.AllowCredentials {
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+			header.Add("Vary", "Origin")
+		}
+		if config.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+		if !preflight {
+			if exposeHeaders != "" {
+				header.Set("Access-Control-Expose-Headers", exposeHeaders)
+			}
+			c.Next()
+			return
+		}
+
+		header.Set("Access-Control-Allow-Methods", allowMethods)
+		if allowHeaders != "" {
+			header.Set("Access-Control-Allow-Headers", allowHeaders)
+		} else if requested := c.GetHeader("Access-Control-Request-Headers"); requested != "" {
+			header.Set("Access-Control-Allow-Headers", requested)
+			header.Add("Vary", "Access-Control-Request-Headers")
+		}
+		if maxAge != "" {
+			header.Set("Access-Control-Max-Age", maxAge)
+		}
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+func matchOrigin(patterns []string, origin string) bool {
+	for _, pattern := range patterns {
+		if matchWildcard(strings.ToLower(pattern), strings.ToLower(origin)) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchWildcard Match s by the pattern in which "*" matches any sequence of characters
+func matchWildcard(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == s
+	}
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+	return strings.HasSuffix(s, parts[len(parts)-1])
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMatchWildcard(t *testing.T) {
+	cases := []struct {
+		pattern, s string
+		match      bool
+	}{
+		{"https://example.com", "https://example.com", true},
+		{"https://*.example.com", "https://api.example.com", true},
+		{"https://*.example.com", "https://example.com", false},
+		{"https://*.example.com", "https://api.example.com.evil.com", false},
+		{"http://localhost:*", "http://localhost:3000", true},
+		{"*://*.example.com", "http://a.b.example.com", true},
+	}
+	for i, cs := range cases {
+		if got := matchWildcard(cs.pattern, cs.s); got != cs.match {
+			t.Errorf("#%d match %s by %s, expect %v, got %v", i, cs.s, cs.pattern, cs.match, got)
+		}
+	}
+}
+
+func TestCORS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(CORS(CORSConfig{
+		AllowOrigins:     []string{"https://*.example.com"},
+		ExposeHeaders:    []string{"X-Request-Id"},
+		AllowCredentials: true,
+		MaxAge:           600,
+	}))
+	engine.POST("/users", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	request := func(method, origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/users", nil)
+		req.Header.Set("Origin", origin)
+		if method == http.MethodOptions {
+			req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			req.Header.Set("Access-Control-Request-Headers", "Authorization")
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request(http.MethodOptions, "https://app.example.com")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expect preflight 204, got %d", w.Code)
+	}
+	for key, expect := range map[string]string{
+		"Access-Control-Allow-Origin":      "https://app.example.com",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Allow-Headers":     "Authorization",
+		"Access-Control-Max-Age":           "600",
+	} {
+		if got := w.Header().Get(key); got != expect {
+			t.Errorf("expect %s %q, got %q", key, expect, got)
+		}
+	}
+
+	w = request(http.MethodPost, "https://app.example.com")
+	if w.Code != http.StatusOK || w.Header().Get("Access-Control-Expose-Headers") != "X-Request-Id" {
+		t.Fatalf("unexpected response %d %v", w.Code, w.Header())
+	}
+
+	if w = request(http.MethodOptions, "https://evil.com"); w.Code != http.StatusForbidden {
+		t.Fatalf("expect preflight of disallowed origin 403, got %d", w.Code)
+	}
+	if w = request(http.MethodPost, "https://evil.com"); w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatalf("expect no cors headers for disallowed origin, got %v", w.Header())
+	}
+}
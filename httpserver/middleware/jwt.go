@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/whereabouts/sdk/httpserver/handler"
+	"github.com/whereabouts/sdk/httpserver/handler/result"
+	"github.com/whereabouts/sdk/jwt"
+	"net/http"
+	"strings"
+)
+
+const (
+	defaultJWTHeader = "Authorization"
+	bearerPrefix     = "Bearer "
+)
+
+// tokenSource Get the token from the request, empty if not found
+type tokenSource func(c *gin.Context) string
+
+type jwtConfig struct {
+	sources    []tokenSource
+	purpose    string
+	recipients []string
+}
+
+type JWTOption func(config *jwtConfig)
+
+// JWTFromHeader look up the token in the header, the prefix "Bearer " is trimmed if exists,
+// the header "Authorization" is used if no source is set
+// 从请求头查找token, 存在"Bearer "前缀时将其去除, 未设置任何来源时使用请求头"Authorization"
+func JWTFromHeader(name string) JWTOption {
+	return func(config *jwtConfig) {
+		config.sources = append(config.sources, func(c *gin.Context) string {
+			token := strings.TrimSpace(c.GetHeader(name))
+			if len(token) > len(bearerPrefix) && strings.EqualFold(token[:len(bearerPrefix)], bearerPrefix) {
+				token = strings.TrimSpace(token[len(bearerPrefix):])
+			}
+			return token
+		})
+	}
+}
+
+// JWTFromCookie look up the token in the cookie
+// 从cookie查找token
+func JWTFromCookie(name string) JWTOption {
+	return func(config *jwtConfig) {
+		config.sources = append(config.sources, func(c *gin.Context) string {
+			token, _ := c.Cookie(name)
+			return token
+		})
+	}
+}
+
+// JWTFromQuery look up the token in the query
+// 从query查找token
+func JWTFromQuery(name string) JWTOption {
+	return func(config *jwtConfig) {
+		config.sources = append(config.sources, func(c *gin.Context) string {
+			return c.Query(name)
+		})
+	}
+}
+
+// JWTPurpose require the purpose of the token
+// 要求token的用途
+func JWTPurpose(purpose string) JWTOption {
+	return func(config *jwtConfig) {
+		config.purpose = purpose
+	}
+}
+
+// JWTRecipient require the recipient of the token to be one of recipients
+// 要求token的接受方为recipients之一
+func JWTRecipient(recipients ...string) JWTOption {
+	return func(config *jwtConfig) {
+		config.recipients = append(config.recipients, recipients...)
+	}
+}
+
+// JWTAuth Verify the token signed by secret, the sources are looked up in order until a token is found.
+// The payload of the valid token is stored in the context of request, which can be got by jwt.PayloadFrom(ctx)
+// in the handler methods, otherwise the request is aborted with 401
+// 校验以secret签名的token, 按顺序查找各来源直到找到token. 有效token的payload存入request的context,
+// 可在handler方法中通过jwt.PayloadFrom(ctx)获取, 否则以401中止请求
+//
+// example:
+//
+//	router.Use(middleware.JWTAuth(secret, middleware.JWTFromHeader("Authorization"), middleware.JWTFromCookie("token")))
+func JWTAuth(secret string, options ...JWTOption) Middleware {
+	config := &jwtConfig{}
+	for _, option := range options {
+		option(config)
+	}
+	if len(config.sources) == 0 {
+		JWTFromHeader(defaultJWTHeader)(config)
+	}
+	return func(c *gin.Context) {
+		payload, message := verifyJWT(c, secret, config)
+		if message != "" {
+			handler.AbortWithErr(c, result.Error(result.CodeBoolFail, message).WithStatusCode(http.StatusUnauthorized))
+			return
+		}
+		c.Request = c.Request.WithContext(jwt.WithPayload(c.Request.Context(), payload))
+		c.Next()
+	}
+}
+
+// verifyJWT Return the payload of the valid token, or the message id of the failure
+func verifyJWT(c *gin.Context, secret string, config *jwtConfig) (*jwt.Payload, string) {
+	var raw string
+	for _, source := range config.sources {
+		if raw = source(c); raw != "" {
+			break
+		}
+	}
+	if raw == "" {
+		return nil, result.TokenMissing
+	}
+	token, err := jwt.Parse(raw)
+	if err != nil || token.Payload == nil || token.Valid(secret) != nil {
+		return nil, result.TokenInvalid
+	}
+	// the zero expire means the token never expires
+	if !token.Payload.Expire.IsZero() && token.Expired() {
+		return nil, result.TokenExpired
+	}
+	if config.purpose != "" && token.Payload.Purpose != config.purpose {
+		return nil, result.TokenInvalid
+	}
+	if len(config.recipients) > 0 && !contains(config.recipients, token.Payload.Recipient) {
+		return nil, result.TokenInvalid
+	}
+	return token.Payload, ""
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
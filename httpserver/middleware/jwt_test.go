@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/whereabouts/sdk/httpserver/handler"
+	"github.com/whereabouts/sdk/jwt"
+	"github.com/whereabouts/sdk/jwt/alg"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type ownerResp struct {
+	Owner string `json:"owner"`
+}
+
+func sign(t *testing.T, secret string, claims ...jwt.Claim) string {
+	token, err := jwt.NewWithClaims(alg.HSA256(), claims...).Sign(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestJWTAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const secret = "secret"
+	engine := gin.New()
+	engine.Use(JWTAuth(secret, JWTFromHeader("Authorization"), JWTFromQuery("token"), JWTPurpose("authentication"), JWTRecipient(jwt.RecipientBrowser)))
+	engine.GET("/me", handler.Handle(func(ctx context.Context, req *struct{}) (*ownerResp, error) {
+		payload, ok := jwt.PayloadFrom(ctx)
+		if !ok {
+			t.Fatal("expect payload in ctx")
+		}
+		return &ownerResp{Owner: payload.Owner}, nil
+	}))
+
+	valid := sign(t, secret, jwt.WithOwner("tom"), jwt.WithRecipient(jwt.RecipientBrowser), jwt.WithDuration(time.Hour))
+	cases := []struct {
+		name    string
+		header  string
+		query   string
+		status  int
+		message string
+	}{
+		{name: "missing", status: http.StatusUnauthorized, message: "缺少身份令牌"},
+		{name: "bearer", header: "Bearer " + valid, status: http.StatusOK},
+		{name: "query", query: valid, status: http.StatusOK},
+		{name: "malformed", header: "Bearer abc", status: http.StatusUnauthorized, message: "身份令牌无效"},
+		{name: "fake", header: sign(t, "other", jwt.WithRecipient(jwt.RecipientBrowser)), status: http.StatusUnauthorized, message: "身份令牌无效"},
+		{name: "expired", header: sign(t, secret, jwt.WithRecipient(jwt.RecipientBrowser), jwt.WithExpire(time.Now().Add(-time.Minute))),
+			status: http.StatusUnauthorized, message: "身份令牌已过期"},
+		{name: "purpose", header: sign(t, secret, jwt.WithRecipient(jwt.RecipientBrowser), jwt.WithPurpose("reset password")),
+			status: http.StatusUnauthorized, message: "身份令牌无效"},
+		{name: "recipient", header: sign(t, secret, jwt.WithRecipient(jwt.RecipientIOS)), status: http.StatusUnauthorized, message: "身份令牌无效"},
+	}
+	for _, tc := range cases {
+		target := "/me"
+		if tc.query != "" {
+			target += "?token=" + tc.query
+		}
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("Authorization", tc.header)
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		body := struct {
+			Message string    `json:"message"`
+			Data    ownerResp `json:"data"`
+		}{}
+		_ = json.Unmarshal(w.Body.Bytes(), &body)
+		if w.Code != tc.status || body.Message != tc.message {
+			t.Fatalf("%s: expect %d %q, got %d %s", tc.name, tc.status, tc.message, w.Code, w.Body.String())
+		}
+		if tc.status == http.StatusOK && body.Data.Owner != "tom" {
+			t.Fatalf("%s: expect the owner of payload, got %s", tc.name, w.Body.String())
+		}
+	}
+}
@@ -6,101 +6,240 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/whereabouts/sdk/logger"
 	"github.com/whereabouts/sdk/logger/field"
+	"github.com/whereabouts/sdk/logger/redact"
 	"github.com/whereabouts/sdk/utils/mapper"
+	"io"
 	"io/ioutil"
+	"math/rand"
 	"net/http"
 	"strings"
 )
 
 const (
 	maxBodyLen = 1024
+	// captureMargin the bytes captured beyond the max body len, so that the value cut by the truncation is still masked,
+	// the truncated JSON is masked by the name of fields instead of decoding
+	captureMargin = 256
+
+	sampledKey = "logging.sampled"
 )
 
+type loggingConfig struct {
+	simply     bool
+	redactor   *redact.Redactor
+	maxBodyLen int
+	skipPaths  map[string]struct{}
+	sampleRate float64
+}
+
+type LoggingOption func(config *loggingConfig)
+
+func newLoggingConfig(options ...LoggingOption) loggingConfig {
+	config := loggingConfig{redactor: redact.Default, maxBodyLen: maxBodyLen, skipPaths: map[string]struct{}{}, sampleRate: 1}
+	for _, option := range options {
+		option(&config)
+	}
+	return config
+}
+
+// LoggingSimply do not log the headers
+// 不记录headers
+func LoggingSimply() LoggingOption {
+	return func(config *loggingConfig) {
+		config.simply = true
+	}
+}
+
+// LoggingRedactor mask the sensitive data by redactor, redact.Default by default
+// 使用redactor对敏感数据脱敏, 默认为redact.Default
+func LoggingRedactor(redactor *redact.Redactor) LoggingOption {
+	return func(config *loggingConfig) {
+		config.redactor = redactor
+	}
+}
+
+// LoggingMaxBodyLen the max bytes of body to log, default 1024, only the head of body is buffered for logging,
+// the whole body is buffered if it is negative
+// 记录的body的最大字节数, 默认1024, 只缓存用于记录的body头部, 为负数时缓存整个body
+func LoggingMaxBodyLen(maxBodyLen int) LoggingOption {
+	return func(config *loggingConfig) {
+		config.maxBodyLen = maxBodyLen
+	}
+}
+
+// LoggingSkipPaths do not log the requests of the route templates or paths, such as "/users/:id" or "/users/1"
+// 不记录这些路由模板或路径的请求, 例如"/users/:id"或"/users/1"
+func LoggingSkipPaths(paths ...string) LoggingOption {
+	return func(config *loggingConfig) {
+		for _, path := range paths {
+			config.skipPaths[path] = struct{}{}
+		}
+	}
+}
+
+// LoggingSampleRate log the rate of requests in [0, 1], the failed responses with status 4xx or 5xx are always logged,
+// the request and response middlewares with the same rate log the same requests
+// 按[0, 1]内的比例记录请求, 状态码为4xx或5xx的失败响应总是被记录, 比例相同的请求与响应中间件记录相同的请求
+func LoggingSampleRate(rate float64) LoggingOption {
+	return func(config *loggingConfig) {
+		config.sampleRate = rate
+	}
+}
+
+// captureLen The max bytes of body to capture for logging, -1 if not limited
+func (config loggingConfig) captureLen() int {
+	if config.maxBodyLen < 0 {
+		return -1
+	}
+	return config.maxBodyLen + captureMargin
+}
+
+func (config loggingConfig) skip(c *gin.Context) bool {
+	if _, ok := config.skipPaths[c.FullPath()]; ok {
+		return true
+	}
+	_, ok := config.skipPaths[c.Request.URL.Path]
+	return ok
+}
+
+// sampled Make the sampling decision once per request, so that the request and response are logged together
+func (config loggingConfig) sampled(c *gin.Context) bool {
+	if config.sampleRate >= 1 {
+		return true
+	}
+	if sampled, ok := c.Get(sampledKey); ok {
+		return sampled.(bool)
+	}
+	sampled := rand.Float64() < config.sampleRate
+	c.Set(sampledKey, sampled)
+	return sampled
+}
+
 func LoggingRequest() Middleware {
-	return LoggingRequestWithLogger(logger.StandardLogger(), false)
+	return LoggingRequestWithOptions(logger.StandardLogger())
 }
 
 func LoggingResponse() Middleware {
-	return LoggingResponseWithLogger(logger.StandardLogger(), false)
+	return LoggingResponseWithOptions(logger.StandardLogger())
 }
 
 func LoggingSimplyRequest() Middleware {
-	return LoggingRequestWithLogger(logger.StandardLogger(), true)
+	return LoggingRequestWithOptions(logger.StandardLogger(), LoggingSimply())
 }
 
 func LoggingSimplyResponse() Middleware {
-	return LoggingResponseWithLogger(logger.StandardLogger(), true)
+	return LoggingResponseWithOptions(logger.StandardLogger(), LoggingSimply())
 }
 
 func LoggingRequestWithLogger(l *logger.Logger, simply bool) Middleware {
+	if simply {
+		return LoggingRequestWithOptions(l, LoggingSimply())
+	}
+	return LoggingRequestWithOptions(l)
+}
+
+func LoggingResponseWithLogger(l *logger.Logger, simply bool) Middleware {
+	if simply {
+		return LoggingResponseWithOptions(l, LoggingSimply())
+	}
+	return LoggingResponseWithOptions(l)
+}
+
+// LoggingRequestWithOptions Log the incoming requests with the sensitive data masked
+// 记录敏感数据已脱敏的请求
+func LoggingRequestWithOptions(l *logger.Logger, options ...LoggingOption) Middleware {
+	config := newLoggingConfig(options...)
 	return func(c *gin.Context) {
+		if config.skip(c) || !config.sampled(c) {
+			return
+		}
 		fields := field.Fields{
 			"method": c.Request.Method,
-			"uri":    c.Request.URL.RequestURI(),
+			"uri":    config.redactor.URL(c.Request.URL),
 			"remote": c.Request.RemoteAddr,
-			"body":   requestBody(c),
+			"body":   truncate(config.redactor.Body(requestBody(c, config.captureLen())), config.maxBodyLen),
 		}
-		if !simply {
-			fields["headers"] = convertHeaders2JSON(c.Request.Header)
+		if !config.simply {
+			fields["headers"] = convertHeaders2JSON(config.redactor.Headers(c.Request.Header))
 		}
-		l.WithFields(fields).Info("incoming http request")
+		l.WithContext(c.Request.Context()).WithFields(fields).Info("incoming http request")
 	}
 }
 
-func LoggingResponseWithLogger(l *logger.Logger, simply bool) Middleware {
+// LoggingResponseWithOptions Log the outgoing responses with the sensitive data masked
+// 记录敏感数据已脱敏的响应
+func LoggingResponseWithOptions(l *logger.Logger, options ...LoggingOption) Middleware {
+	config := newLoggingConfig(options...)
 	return func(c *gin.Context) {
-		rw := &responseWriter{Body: new(bytes.Buffer), ResponseWriter: c.Writer}
+		if config.skip(c) {
+			return
+		}
+		rw := &responseWriter{Body: new(bytes.Buffer), ResponseWriter: c.Writer, limit: config.captureLen()}
 		c.Writer = rw
 
 		c.Next()
 
+		status := c.Writer.Status()
+		if status < http.StatusBadRequest && !config.sampled(c) {
+			return
+		}
 		fields := field.Fields{
-			"status": fmt.Sprintf("%v %s", c.Writer.Status(), http.StatusText(c.Writer.Status())),
-			"body":   responseBody(rw),
+			"status": fmt.Sprintf("%v %s", status, http.StatusText(status)),
+			"body":   truncate(config.redactor.Body(rw.Body.Bytes()), config.maxBodyLen),
 		}
-		if !simply {
-			fields["headers"] = convertHeaders2JSON(c.Writer.Header())
+		if !config.simply {
+			fields["headers"] = convertHeaders2JSON(config.redactor.Headers(c.Writer.Header()))
 		}
-		l.WithFields(fields).Info("outgoing http response")
+		l.WithContext(c.Request.Context()).WithFields(fields).Info("outgoing http response")
 	}
 }
 
-func requestBody(c *gin.Context) string {
+// requestBody Read the head of body up to limit bytes, or the whole body if limit is negative,
+// the head is put back before the rest, so that the large upload is not held in memory
+func requestBody(c *gin.Context, limit int) []byte {
 	if c.Request.Body == nil || c.Request.Body == http.NoBody {
-		return ""
+		return nil
 	}
-	body, err := ioutil.ReadAll(c.Request.Body)
-	if err != nil {
-		return fmt.Sprintf("read request body err: %s", err.Error())
+	reader := io.Reader(c.Request.Body)
+	if limit >= 0 {
+		reader = io.LimitReader(reader, int64(limit))
 	}
-	_ = c.Request.Body.Close()
-	// create a new buffer and replace the original request.Body, put the read byte stream back into the request.Body
-	c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
-	bodySize := len(body)
-	if bodySize > maxBodyLen {
-		bodySize = maxBodyLen
+	body, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return []byte(fmt.Sprintf("read request body err: %s", err.Error()))
 	}
-	return string(body[:bodySize])
+	// put the read byte stream back before the rest of the original request.Body
+	c.Request.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), c.Request.Body), Closer: c.Request.Body}
+	return body
 }
 
-func responseBody(rw *responseWriter) string {
-	body := rw.Body.Bytes()
-	bodyLen := len(body)
-	if bodyLen > maxBodyLen {
-		bodyLen = maxBodyLen
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func truncate(body string, maxLen int) string {
+	if maxLen >= 0 && len(body) > maxLen {
+		return body[:maxLen]
 	}
-	return string(body[:bodyLen])
+	return body
 }
 
 type responseWriter struct {
 	gin.ResponseWriter
 	Body *bytes.Buffer
+	// limit the max bytes to store, not limited if negative
+	limit int
 }
 
-// Write rewrite gin.ResponseWriter to store body before write
+// Write rewrite gin.ResponseWriter to store the head of body up to limit before write
 func (w responseWriter) Write(body []byte) (int, error) {
 	// store body
-	w.Body.Write(body)
+	if remain := w.limit - w.Body.Len(); w.limit < 0 || remain >= len(body) {
+		w.Body.Write(body)
+	} else if remain > 0 {
+		w.Body.Write(body[:remain])
+	}
 	// write
 	return w.ResponseWriter.Write(body)
 }
@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"github.com/whereabouts/sdk/logger"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLoggingRedaction(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	buf := new(bytes.Buffer)
+	l := logger.New().SetOutput(buf)
+	engine := gin.New()
+	engine.Use(LoggingRequestWithOptions(l, LoggingSkipPaths("/healthz")), LoggingResponseWithOptions(l))
+	engine.POST("/login", func(c *gin.Context) {
+		c.Header("Set-Cookie", "session=secret")
+		c.String(http.StatusOK, `{"token":"abc","phone":"13812345678"}`)
+	})
+	engine.GET("/healthz", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/login?token=abc", strings.NewReader(`{"name":"tom","password":"123456"}`))
+	req.Header.Set("Authorization", "Bearer abc")
+	engine.ServeHTTP(httptest.NewRecorder(), req)
+	logs := buf.String()
+	for _, secret := range []string{"Bearer abc", "123456", "session=secret", "13812345678", "token=abc", `"token":"abc"`} {
+		if strings.Contains(logs, secret) {
+			t.Errorf("expect %q masked, got %s", secret, logs)
+		}
+	}
+	if !strings.Contains(logs, "tom") || strings.Count(logs, "******") < 6 {
+		t.Errorf("expect the other fields logged, got %s", logs)
+	}
+
+	buf.Reset()
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if strings.Contains(buf.String(), "incoming http request") {
+		t.Fatalf("expect the skipped path not logged, got %s", buf.String())
+	}
+}
+
+func TestLoggingSampleRate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	buf := new(bytes.Buffer)
+	l := logger.New().SetOutput(buf)
+	engine := gin.New()
+	engine.Use(LoggingRequestWithOptions(l, LoggingSampleRate(0)), LoggingResponseWithOptions(l, LoggingSampleRate(0)))
+	engine.GET("/:status", func(c *gin.Context) {
+		if c.Param("status") == "ok" {
+			c.Status(http.StatusOK)
+			return
+		}
+		c.Status(http.StatusInternalServerError)
+	})
+
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ok", nil))
+	if buf.Len() != 0 {
+		t.Fatalf("expect the success not logged, got %s", buf.String())
+	}
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+	if !strings.Contains(buf.String(), "outgoing http response") {
+		t.Fatalf("expect the failure logged, got %s", buf.String())
+	}
+}
+
+func TestLoggingLargeBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	buf := new(bytes.Buffer)
+	l := logger.New().SetOutput(buf)
+	large := `{"password":"123456","data":"` + strings.Repeat("x", 100*1024) + `"}`
+	var captured *responseWriter
+	engine := gin.New()
+	engine.Use(LoggingRequestWithOptions(l, LoggingMaxBodyLen(64)), LoggingResponseWithOptions(l, LoggingMaxBodyLen(64)),
+		func(c *gin.Context) {
+			captured, _ = c.Writer.(*responseWriter)
+		})
+	engine.POST("/echo", func(c *gin.Context) {
+		body, _ := ioutil.ReadAll(c.Request.Body)
+		for i := 0; i < len(body); i += 1024 {
+			end := i + 1024
+			if end > len(body) {
+				end = len(body)
+			}
+			_, _ = c.Writer.Write(body[i:end])
+		}
+	})
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(large)))
+	if w.Body.String() != large {
+		t.Fatalf("expect the whole body echoed, got %d bytes", w.Body.Len())
+	}
+	if captured == nil || captured.Body.Len() != 64+captureMargin {
+		t.Fatalf("expect the response captured up to the max body len with margin, got %+v", captured)
+	}
+	// the truncated JSON is masked by the name of fields
+	if logs := buf.String(); strings.Contains(logs, "123456") || strings.Count(logs, "incoming http request") != 1 {
+		t.Fatalf("expect the password masked, got %s", logs)
+	}
+}
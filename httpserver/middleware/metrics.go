@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/whereabouts/sdk/httpserver/metrics"
+	"strconv"
+	"time"
+)
+
+const (
+	MetricRequestsTotal   = "http_server_requests_total"
+	MetricRequestDuration = "http_server_request_duration_seconds"
+	MetricRequestsFlight  = "http_server_requests_in_flight"
+	MetricResponseSize    = "http_server_response_size_bytes"
+
+	// unmatchedRoute the route label of the requests matching no route, so that the 404s of random paths
+	// do not create unbounded series
+	unmatchedRoute = "<unmatched>"
+)
+
+// Metrics Record the metrics of requests into metrics.DefaultRegistry
+// 将请求的指标记录到metrics.DefaultRegistry
+func Metrics() Middleware {
+	return MetricsWithRegistry(metrics.DefaultRegistry)
+}
+
+// MetricsWithRegistry Record the count, latency, in-flight count and response size of requests into registry,
+// labeled by the route template of gin, such as "/users/:id", rather than the raw uri
+// 将请求数, 耗时, 处理中请求数与响应大小记录到registry, 以gin的路由模板(例如"/users/:id")而非原始uri作为label
+func MetricsWithRegistry(registry *metrics.Registry) Middleware {
+	requests := registry.Counter(MetricRequestsTotal, "Total number of http requests.", "method", "route", "status")
+	duration := registry.Histogram(MetricRequestDuration, "Latency of http requests in seconds.", metrics.DefaultBuckets, "method", "route")
+	inFlight := registry.Gauge(MetricRequestsFlight, "Number of http requests being served.", "method", "route")
+	size := registry.Histogram(MetricResponseSize, "Size of http responses in bytes.", metrics.DefaultSizeBuckets, "method", "route")
+	return func(c *gin.Context) {
+		method, route := c.Request.Method, c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		start := time.Now()
+		inFlight.Inc(method, route)
+		defer inFlight.Dec(method, route)
+
+		c.Next()
+
+		requests.Inc(method, route, strconv.Itoa(c.Writer.Status()))
+		duration.Observe(time.Since(start).Seconds(), method, route)
+		written := c.Writer.Size()
+		if written < 0 {
+			written = 0
+		}
+		size.Observe(float64(written), method, route)
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/whereabouts/sdk/httpserver/metrics"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	registry := metrics.Register(engine, metrics.WithRegistry(metrics.NewRegistry()))
+	engine.Use(MetricsWithRegistry(registry))
+	engine.GET("/users/:id", func(c *gin.Context) {
+		c.String(http.StatusOK, "hello")
+	})
+	for _, path := range []string{"/users/1", "/users/2", "/none"} {
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != metrics.ContentType {
+		t.Fatalf("unexpected scrape response %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	body := w.Body.String()
+	for _, line := range []string{
+		`http_server_requests_total{method="GET",route="/users/:id",status="200"} 2`,
+		`http_server_requests_total{method="GET",route="<unmatched>",status="404"} 1`,
+		`http_server_request_duration_seconds_count{method="GET",route="/users/:id"} 2`,
+		`http_server_requests_in_flight{method="GET",route="/users/:id"} 0`,
+		`http_server_response_size_bytes_sum{method="GET",route="/users/:id"} 10`,
+		`http_server_response_size_bytes_bucket{method="GET",route="/users/:id",le="64"} 2`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Fatalf("expect line %s in:\n%s", line, body)
+		}
+	}
+	if strings.Contains(body, `route="/metrics"`) {
+		t.Fatal("expect the scrapes not recorded")
+	}
+}
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/whereabouts/sdk/httpserver/handler"
+	"github.com/whereabouts/sdk/httpserver/handler/result"
+	"github.com/whereabouts/sdk/jwt"
+	"github.com/whereabouts/sdk/logger"
+	"github.com/whereabouts/sdk/ratelimit"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	HeaderRateLimitLimit     = "X-RateLimit-Limit"
+	HeaderRateLimitRemaining = "X-RateLimit-Remaining"
+	HeaderRateLimitReset     = "X-RateLimit-Reset"
+	HeaderRetryAfter         = "Retry-After"
+)
+
+// LimitKey Get the key to limit of the request, the request is not limited if the key is empty
+// 获取请求的限流key, key为空时不限流
+type LimitKey func(c *gin.Context) string
+
+// LimitByIP limit per client ip
+// 按客户端ip限流
+func LimitByIP() LimitKey {
+	return func(c *gin.Context) string {
+		return "ip:" + c.ClientIP()
+	}
+}
+
+// LimitByUser limit per owner of the token verified by JWTAuth, the client ip is used if there is no token
+// 按JWTAuth校验的token的所有者限流, 没有token时使用客户端ip
+func LimitByUser() LimitKey {
+	return func(c *gin.Context) string {
+		if payload, ok := jwt.PayloadFrom(c.Request.Context()); ok && payload.Owner != "" {
+			return "user:" + payload.Owner
+		}
+		return "ip:" + c.ClientIP()
+	}
+}
+
+// LimitByRoute limit per route template, such as "GET /users/:id", for all clients together
+// 按路由模板(例如"GET /users/:id")对所有客户端共同限流
+func LimitByRoute() LimitKey {
+	return func(c *gin.Context) string {
+		return "route:" + c.Request.Method + " " + c.FullPath()
+	}
+}
+
+// LimitBy Join the keys, for example, LimitBy(LimitByRoute(), LimitByIP()) limits per route and ip
+// 拼接多个key, 例如LimitBy(LimitByRoute(), LimitByIP())按路由与ip限流
+func LimitBy(keys ...LimitKey) LimitKey {
+	return func(c *gin.Context) string {
+		parts := make([]string, 0, len(keys))
+		for _, key := range keys {
+			part := key(c)
+			if part == "" {
+				return ""
+			}
+			parts = append(parts, part)
+		}
+		return strings.Join(parts, "|")
+	}
+}
+
+// RateLimit Limit the requests by limiter per key, the rate limit headers are set on every response,
+// the request over the limit is aborted with 429 and Retry-After.
+// The request is allowed if the limiter fails, such as the redis is down
+// 按key通过limiter限流, 每个响应都会设置限流相关的响应头, 超出限制的请求以429与Retry-After中止.
+// limiter出错(例如redis不可用)时放行请求
+func RateLimit(limiter ratelimit.Limiter, key LimitKey) Middleware {
+	return func(c *gin.Context) {
+		k := key(c)
+		if k == "" {
+			c.Next()
+			return
+		}
+		res, err := limiter.Allow(c.Request.Context(), k)
+		if err != nil {
+			logger.WithContext(c.Request.Context()).Errorf("rate limit of %s err: %v", k, err)
+			c.Next()
+			return
+		}
+		c.Header(HeaderRateLimitLimit, strconv.Itoa(res.Limit))
+		c.Header(HeaderRateLimitRemaining, strconv.Itoa(res.Remaining))
+		c.Header(HeaderRateLimitReset, strconv.Itoa(ceilSeconds(res.ResetAfter)))
+		if !res.Allowed {
+			c.Header(HeaderRetryAfter, strconv.Itoa(ceilSeconds(res.RetryAfter)))
+			handler.AbortWithErr(c, result.Error(result.CodeBoolFail, result.TooManyRequests).WithStatusCode(http.StatusTooManyRequests))
+			return
+		}
+		c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
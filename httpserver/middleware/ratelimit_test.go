@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/whereabouts/sdk/ratelimit"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limiter, err := ratelimit.NewTokenBucket(2, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine := gin.New()
+	engine.Use(RateLimit(limiter, LimitBy(LimitByRoute(), LimitByIP())))
+	engine.GET("/users/:id", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	request := func(target, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.RemoteAddr = ip + ":1234"
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	for i, remaining := range []string{"1", "0"} {
+		if w := request("/users/1", "10.0.0.1"); w.Code != http.StatusOK || w.Header().Get(HeaderRateLimitRemaining) != remaining {
+			t.Fatalf("#%d unexpected response %d %v", i, w.Code, w.Header())
+		}
+	}
+	w := request("/users/3", "10.0.0.1")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get(HeaderRetryAfter) != "30" || w.Header().Get(HeaderRateLimitLimit) != "2" {
+		t.Fatalf("expect 429, got %d %v", w.Code, w.Header())
+	}
+	if !strings.Contains(w.Body.String(), "请求过于频繁") {
+		t.Fatalf("expect the result rendered, got %s", w.Body.String())
+	}
+	if w = request("/users/1", "10.0.0.2"); w.Code != http.StatusOK {
+		t.Fatalf("expect the other ip allowed, got %d", w.Code)
+	}
+}
@@ -1,23 +1,66 @@
 package middleware
 
 import (
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/whereabouts/sdk/httpserver/handler"
+	"github.com/whereabouts/sdk/httpserver/handler/result"
+	"github.com/whereabouts/sdk/httpserver/panics"
 	"github.com/whereabouts/sdk/logger"
+	"github.com/whereabouts/sdk/logger/field"
+	"github.com/whereabouts/sdk/trace"
 	"net/http"
 	"runtime/debug"
+	"time"
 )
 
-// Recovery global exception handling middleware
-func Recovery() Middleware {
+// Recovery global exception handling middleware, the panic is logged with the request and stack, reported by reporters,
+// and answered with 500 InternalServerError carrying the request id
+// 全局异常处理中间件, panic会与请求及堆栈一起记录, 由reporters上报, 并以携带request id的500 InternalServerError响应
+//
+// example:
+//
+//	middleware.Recovery(panics.Dedup(panics.Email(emailClient, "alert@example.com", "dev@example.com"), 10*time.Minute))
+func Recovery(reporters ...panics.Reporter) Middleware {
 	return func(c *gin.Context) {
 		defer func() {
-			if err := recover(); err != nil {
-				// Print error and stack information
-				logger.Errorf("panic: %v", err)
-				logger.Errorf("stack: %s", debug.Stack())
-				// Terminate subsequent interface calls
-				c.AbortWithStatus(http.StatusInternalServerError)
+			err := recover()
+			if err == nil {
+				return
+			}
+			// the sentinel to abort the response, it must be handled by net/http
+			if err == http.ErrAbortHandler {
+				panic(err)
+			}
+			ctx := c.Request.Context()
+			p := &panics.Panic{
+				Value:     fmt.Sprint(err),
+				Stack:     string(debug.Stack()),
+				Method:    c.Request.Method,
+				Path:      c.Request.URL.Path,
+				Route:     c.FullPath(),
+				RequestID: trace.RequestIDFrom(ctx),
+				Time:      time.Now(),
+			}
+			logger.WithContext(ctx).WithFields(field.Fields{
+				"panic":  p.Value,
+				"method": p.Method,
+				"path":   p.Path,
+				"stack":  p.Stack,
+			}).Error("panic recovered")
+			panics.Report(ctx, p, reporters...)
+
+			// the response can not be changed if it has been written
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			res := result.Failed(result.Error(result.CodeBoolFail, result.InternalServerError)).
+				WithStatusCode(http.StatusInternalServerError)
+			if p.RequestID != "" {
+				res.WithData(result.Json{"request_id": p.RequestID})
 			}
+			handler.AbortWithResult(c, res)
 		}()
 		c.Next()
 	}
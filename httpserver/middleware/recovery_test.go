@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/whereabouts/sdk/httpserver/panics"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRecovery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	reported := make(chan *panics.Panic, 1)
+	engine := gin.New()
+	engine.Use(Trace(), Recovery(panics.ReporterFunc(func(ctx context.Context, p *panics.Panic) error {
+		reported <- p
+		return nil
+	})))
+	engine.GET("/users/:id", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set("X-Request-Id", "req-1")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expect 500, got %d", w.Code)
+	}
+	var body struct {
+		Code    bool              `json:"code"`
+		Message string            `json:"message"`
+		Data    map[string]string `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Code || body.Message == "" || body.Data["request_id"] != "req-1" {
+		t.Fatalf("unexpected body %s", w.Body.String())
+	}
+
+	select {
+	case p := <-reported:
+		if p.Value != "boom" || p.Route != "/users/:id" || p.Path != "/users/1" || p.RequestID != "req-1" || p.Stack == "" {
+			t.Fatalf("unexpected panic reported %+v", p)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expect the panic reported")
+	}
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"strconv"
+)
+
+type SecureConfig struct {
+	// HSTSMaxAge the seconds of Strict-Transport-Security, only set for the https requests, including the ones
+	// forwarded with "X-Forwarded-Proto: https", not set if 0
+	// Strict-Transport-Security的秒数, 只对https请求(包括以"X-Forwarded-Proto: https"转发的请求)设置, 为0时不设置
+	HSTSMaxAge            int  `mapstructure:"hsts_max_age" json:"hsts_max_age"`
+	HSTSIncludeSubdomains bool `mapstructure:"hsts_include_subdomains" json:"hsts_include_subdomains"`
+	HSTSPreload           bool `mapstructure:"hsts_preload" json:"hsts_preload"`
+	// ContentSecurityPolicy example: "default-src 'self'"
+	ContentSecurityPolicy string `mapstructure:"content_security_policy" json:"content_security_policy"`
+	// FrameOptions DENY or SAMEORIGIN
+	FrameOptions       string `mapstructure:"frame_options" json:"frame_options"`
+	ContentTypeNosniff bool   `mapstructure:"content_type_nosniff" json:"content_type_nosniff"`
+	// ReferrerPolicy example: "strict-origin-when-cross-origin"
+	ReferrerPolicy string `mapstructure:"referrer_policy" json:"referrer_policy"`
+	// CrossOriginOpenerPolicy example: "same-origin"
+	CrossOriginOpenerPolicy string `mapstructure:"cross_origin_opener_policy" json:"cross_origin_opener_policy"`
+}
+
+// DefaultSecureConfig HSTS for one year including subdomains, SAMEORIGIN frames, nosniff
+// and strict-origin-when-cross-origin referrer
+// HSTS一年且包括子域名, 只允许同源frame, nosniff与strict-origin-when-cross-origin的referrer策略
+func DefaultSecureConfig() SecureConfig {
+	return SecureConfig{
+		HSTSMaxAge:            365 * 24 * 60 * 60,
+		HSTSIncludeSubdomains: true,
+		FrameOptions:          "SAMEORIGIN",
+		ContentTypeNosniff:    true,
+		ReferrerPolicy:        "strict-origin-when-cross-origin",
+	}
+}
+
+// SecureHeaders Set the security headers of config on every response, the empty ones are not set
+// 为每个响应设置config中的安全相关响应头, 为空的不设置
+func SecureHeaders(config SecureConfig) Middleware {
+	hsts := ""
+	if config.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(config.HSTSMaxAge)
+		if config.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		if config.HSTSPreload {
+			hsts += "; preload"
+		}
+	}
+	return func(c *gin.Context) {
+		header := c.Writer.Header()
+		if hsts != "" && (c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https") {
+			header.Set("Strict-Transport-Security", hsts)
+		}
+		if config.ContentSecurityPolicy != "" {
+			header.Set("Content-Security-Policy", config.ContentSecurityPolicy)
+		}
+		if config.FrameOptions != "" {
+			header.Set("X-Frame-Options", config.FrameOptions)
+		}
+		if config.ContentTypeNosniff {
+			header.Set("X-Content-Type-Options", "nosniff")
+		}
+		if config.ReferrerPolicy != "" {
+			header.Set("Referrer-Policy", config.ReferrerPolicy)
+		}
+		if config.CrossOriginOpenerPolicy != "" {
+			header.Set("Cross-Origin-Opener-Policy", config.CrossOriginOpenerPolicy)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/whereabouts/sdk/httpserver/handler"
+	"time"
+)
+
+// Timeout Cancel the context of request after d, and answer with 504 if the handlers after it overrun,
+// it can be used per route, see handler.ServeWithTimeout
+// 在d之后取消请求的context, 其后的handler超时则以504响应, 可用于单个路由, 参考handler.ServeWithTimeout
+//
+// example:
+//
+//	router.GET("/report", middleware.Timeout(3*time.Second), handler.Handle(Report))
+func Timeout(d time.Duration) Middleware {
+	return func(c *gin.Context) {
+		handler.ServeWithTimeout(c, d, func(c *gin.Context) {
+			c.Next()
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/whereabouts/sdk/trace"
+)
+
+// Trace Accept the X-Request-ID and W3C traceparent of the request or generate them, store them in the context
+// of request and echo the request id in the response header. Use it before the logging middlewares,
+// so that the logs of a request, including those of httpc, carry the same trace
+// 接收或生成请求的X-Request-ID与W3C traceparent, 存入request的context, 并在响应头中返回request id.
+// 需在日志中间件之前使用, 使一个请求的日志(包括httpc的日志)带有相同的trace
+func Trace() Middleware {
+	return func(c *gin.Context) {
+		ctx := trace.Extract(c.Request.Context(), c.Request.Header)
+		c.Request = c.Request.WithContext(ctx)
+		c.Header(trace.HeaderRequestID, trace.RequestIDFrom(ctx))
+		c.Next()
+	}
+}
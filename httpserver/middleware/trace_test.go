@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/whereabouts/sdk/httpc"
+	"github.com/whereabouts/sdk/httpc/hook"
+	"github.com/whereabouts/sdk/logger"
+	"github.com/whereabouts/sdk/trace"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var downstream http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downstream = r.Header.Clone()
+	}))
+	defer srv.Close()
+	client, err := httpc.NewClient(httpc.WithHost(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.OnBeforeRequest(hook.Trace())
+
+	buf := new(bytes.Buffer)
+	l := logger.New().SetOutput(buf)
+	engine := gin.New()
+	engine.Use(Trace(), LoggingRequestWithLogger(l, true))
+	engine.GET("/call", func(c *gin.Context) {
+		if _, err := client.NewRequest(c.Request.Context()).Get("/"); err != nil {
+			t.Error(err)
+		}
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/call", nil)
+	req.Header.Set(trace.HeaderRequestID, "req-1")
+	req.Header.Set(trace.HeaderTraceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	if w.Header().Get(trace.HeaderRequestID) != "req-1" {
+		t.Fatalf("expect the request id echoed, got %v", w.Header())
+	}
+	if downstream.Get(trace.HeaderRequestID) != "req-1" {
+		t.Fatalf("expect the request id forwarded, got %v", downstream)
+	}
+	sc, err := trace.ParseTraceparent(downstream.Get(trace.HeaderTraceparent))
+	if err != nil || sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("expect the trace forwarded, got %v, err: %v", downstream, err)
+	}
+
+	entry := map[string]interface{}{}
+	if err = json.Unmarshal([]byte(strings.TrimSpace(buf.String())), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry["trace"] != sc.TraceID.String() || entry["span"] == nil || entry["request_id"] != "req-1" {
+		t.Fatalf("expect the trace in log, got %v", entry)
+	}
+}
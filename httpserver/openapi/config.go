@@ -0,0 +1,84 @@
+package openapi
+
+import "github.com/whereabouts/sdk/httpserver/handler/result"
+
+const (
+	defaultPath    = "/openapi.json"
+	defaultTitle   = "API"
+	defaultVersion = "1.0.0"
+)
+
+type Config struct {
+	// Path the path to serve the document, default "/openapi.json"
+	// 文档的访问路径, 默认"/openapi.json"
+	Path string `mapstructure:"path" json:"path"`
+	// UIPath the path to serve the UI page, the UI is disabled if it is empty
+	// UI页面的访问路径, 为空时不提供UI
+	UIPath      string   `mapstructure:"ui_path" json:"ui_path"`
+	Title       string   `mapstructure:"title" json:"title"`
+	Description string   `mapstructure:"description" json:"description"`
+	Version     string   `mapstructure:"version" json:"version"`
+	Servers     []string `mapstructure:"servers" json:"servers"`
+	// Encoder the encoder of the results rendered, the envelope of the responses is documented by its keys
+	// if it implements result.Envelope, otherwise by the keys of result.Result
+	// 渲染result的编码器, 若实现了result.Envelope, 响应的包装按其键名生成文档, 否则按result.Result的键名
+	Encoder result.Encoder `mapstructure:"-" json:"-"`
+}
+
+type Option func(config *Config)
+
+func newConfig(options ...Option) Config {
+	config := Config{
+		Path:    defaultPath,
+		Title:   defaultTitle,
+		Version: defaultVersion,
+	}
+	for _, option := range options {
+		option(&config)
+	}
+	return config
+}
+
+func WithPath(path string) Option {
+	return func(config *Config) {
+		config.Path = path
+	}
+}
+
+// WithUI serve the UI page at uiPath, which renders the document by swagger-ui loaded from CDN
+// 在uiPath提供UI页面, 页面使用从CDN加载的swagger-ui渲染文档
+func WithUI(uiPath string) Option {
+	return func(config *Config) {
+		config.UIPath = uiPath
+	}
+}
+
+func WithTitle(title string) Option {
+	return func(config *Config) {
+		config.Title = title
+	}
+}
+
+func WithDescription(description string) Option {
+	return func(config *Config) {
+		config.Description = description
+	}
+}
+
+func WithVersion(version string) Option {
+	return func(config *Config) {
+		config.Version = version
+	}
+}
+
+func WithServers(servers ...string) Option {
+	return func(config *Config) {
+		config.Servers = append(config.Servers, servers...)
+	}
+}
+
+func WithEncoder(encoder result.Encoder) Option {
+	return func(config *Config) {
+		config.Encoder = encoder
+	}
+}
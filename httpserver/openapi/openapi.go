@@ -0,0 +1,232 @@
+package openapi
+
+import (
+	_ "embed"
+	"github.com/gin-gonic/gin"
+	"github.com/whereabouts/sdk/httpserver/handler"
+	"github.com/whereabouts/sdk/httpserver/handler/result"
+	"github.com/whereabouts/sdk/httpserver/handler/validation"
+	"html/template"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+//go:embed ui.html
+var uiHTML string
+
+var uiTemplate = template.Must(template.New("ui").Parse(uiHTML))
+
+// Register Serve the OpenAPI document of the routes of engine, the document is generated at the first request,
+// so the routes registered after Register are included
+// 提供engine路由的OpenAPI文档, 文档在第一次请求时生成, 因此包含Register之后注册的路由
+func Register(engine *gin.Engine, options ...Option) {
+	config := newConfig(options...)
+	var (
+		once sync.Once
+		doc  *Document
+	)
+	engine.GET(config.Path, func(c *gin.Context) {
+		once.Do(func() {
+			doc = Generate(engine.Routes(), config)
+		})
+		c.JSON(http.StatusOK, doc)
+	})
+	if config.UIPath != "" {
+		engine.GET(config.UIPath, func(c *gin.Context) {
+			c.Header("Content-Type", "text/html; charset=utf-8")
+			c.Status(http.StatusOK)
+			_ = uiTemplate.Execute(c.Writer, config)
+		})
+	}
+}
+
+// Generate Generate the document of the routes whose handler func is created by package handler, others are skipped
+// 生成handler func由handler包创建的路由的文档, 其他路由被跳过
+func Generate(routes gin.RoutesInfo, config Config) *Document {
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: config.Title, Description: config.Description, Version: config.Version},
+		Paths:   make(map[string]PathItem),
+	}
+	for _, server := range config.Servers {
+		doc.Servers = append(doc.Servers, Server{URL: server})
+	}
+	b := newSchemaBuilder()
+	env := newEnvelope(config.Encoder)
+	tags := make(map[string]bool)
+	for _, route := range routes {
+		meta, ok := handler.Describe(route.HandlerFunc)
+		if !ok {
+			continue
+		}
+		path := convertPath(route.Path)
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = make(PathItem)
+			doc.Paths[path] = item
+		}
+		item[strings.ToLower(route.Method)] = newOperation(b, env, route.Method, path, meta)
+		for _, tag := range meta.Tags {
+			if !tags[tag] {
+				tags[tag] = true
+				doc.Tags = append(doc.Tags, Tag{Name: tag})
+			}
+		}
+	}
+	doc.Components.Schemas = b.schemas
+	return doc
+}
+
+func newOperation(b *schemaBuilder, env envelope, method, path string, meta handler.Meta) *Operation {
+	op := &Operation{
+		Tags:        meta.Tags,
+		Summary:     meta.Summary,
+		Description: meta.Description,
+		OperationID: operationID(method, path),
+		Deprecated:  meta.Deprecated,
+		Responses:   make(map[string]*Response),
+	}
+	if meta.Request != nil && meta.Request.Kind() == reflect.Struct {
+		op.Parameters, op.RequestBody = requestOf(b, method, meta.Request)
+	}
+
+	if meta.WithoutResult {
+		op.Responses["200"] = &Response{Description: "OK"}
+		return op
+	}
+	data := &Schema{}
+	if meta.Response != nil {
+		data = b.schema(meta.Response)
+	}
+	op.Responses["200"] = &Response{Description: "OK", Content: jsonContent(env.schema(data))}
+	op.Responses["400"] = &Response{
+		Description: "Bad Request",
+		Content:     jsonContent(env.schema(&Schema{Type: "array", Items: b.schema(reflect.TypeOf(validation.FieldError{}))})),
+	}
+	return op
+}
+
+// requestOf The parameters and body of the request, following the binding rules of handler.Bind:
+// tag "uri" is path param, tag "header" is header param, the file fields make the body a multipart form,
+// otherwise the methods without body take tag "form" as query param and the others take the json fields as body
+func requestOf(b *schemaBuilder, method string, t reflect.Type) ([]Parameter, *RequestBody) {
+	parameters := make([]Parameter, 0)
+	hasBody := method != http.MethodGet && method != http.MethodHead && method != http.MethodDelete
+	multipartForm := false
+	for _, field := range fields(t) {
+		if isFile(field.Type) {
+			multipartForm = true
+		}
+	}
+
+	var bodyFields []reflect.StructField
+	for _, field := range fields(t) {
+		if name, ok := tagName(field, "uri"); ok {
+			parameters = append(parameters, Parameter{Name: name, In: "path", Required: true, Schema: b.fieldSchema(field)})
+			continue
+		}
+		if name, ok := tagName(field, "header"); ok {
+			parameters = append(parameters, Parameter{Name: name, In: "header", Required: isRequired(field), Schema: b.fieldSchema(field)})
+			continue
+		}
+		formName, hasForm := tagName(field, "form")
+		_, hasJSON := field.Tag.Lookup("json")
+		switch {
+		case hasBody && multipartForm:
+			if hasForm {
+				bodyFields = append(bodyFields, field)
+			}
+		case hasBody && (hasJSON || !hasForm):
+			bodyFields = append(bodyFields, field)
+		case hasForm:
+			parameters = append(parameters, Parameter{Name: formName, In: "query", Required: isRequired(field), Schema: b.fieldSchema(field)})
+		case !hasBody && !hasJSON:
+			parameters = append(parameters, Parameter{Name: field.Name, In: "query", Required: isRequired(field), Schema: b.fieldSchema(field)})
+		}
+	}
+	if len(bodyFields) == 0 {
+		return parameters, nil
+	}
+
+	body := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for _, field := range bodyFields {
+		name, _, ok := jsonName(field)
+		if multipartForm {
+			name, ok = tagName(field, "form")
+		}
+		if !ok {
+			continue
+		}
+		body.Properties[name] = b.fieldSchema(field)
+		if isRequired(field) {
+			body.Required = append(body.Required, name)
+		}
+	}
+	contentType := gin.MIMEJSON
+	if multipartForm {
+		contentType = gin.MIMEMultipartPOSTForm
+	}
+	return parameters, &RequestBody{Required: len(body.Required) > 0, Content: map[string]MediaType{contentType: {Schema: body}}}
+}
+
+func isFile(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t == fileHeaderType
+}
+
+// envelope The keys of result.Result rendered by the encoder
+type envelope struct {
+	codeKey, messageKey, dataKey string
+}
+
+// newEnvelope The keys told by the encoder, or the keys of result.Result if it does not implement result.Envelope
+func newEnvelope(encoder result.Encoder) envelope {
+	env := envelope{codeKey: "code", messageKey: "message", dataKey: "data"}
+	if e, ok := encoder.(result.Envelope); ok {
+		env.codeKey, env.messageKey, env.dataKey = e.Keys()
+	}
+	return env
+}
+
+// schema The schema of result.Result with data
+func (env envelope) schema(data *Schema) *Schema {
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			env.codeKey:    {Description: "true or false, or the business code"},
+			env.messageKey: {Type: "string"},
+			env.dataKey:    data,
+		},
+	}
+}
+
+func jsonContent(schema *Schema) map[string]MediaType {
+	return map[string]MediaType{gin.MIMEJSON: {Schema: schema}}
+}
+
+// convertPath Convert the gin path "/users/:id/*path" to "/users/{id}/{path}"
+func convertPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// operationID Generate the id from method and path, example: GET /users/{id} -> getUsersId
+func operationID(method, path string) string {
+	var builder strings.Builder
+	builder.WriteString(strings.ToLower(method))
+	for _, word := range strings.FieldsFunc(path, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}) {
+		builder.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return builder.String()
+}
@@ -0,0 +1,138 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/whereabouts/sdk/httpserver/handler"
+	"github.com/whereabouts/sdk/httpserver/handler/result"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type Address struct {
+	City string   `json:"city"`
+	Next *Address `json:"next,omitempty"`
+}
+
+type userReq struct {
+	ID     int64  `uri:"id" binding:"required"`
+	Token  string `header:"X-Token"`
+	Name   string `json:"name" binding:"required" description:"the user name"`
+	Status string `json:"status" binding:"omitempty,oneof=active banned"`
+	Page   int    `form:"page"`
+}
+
+type userResp struct {
+	Name      string    `json:"name"`
+	Address   Address   `json:"address"`
+	CreatedAt time.Time `json:"created_at"`
+	Secret    string    `json:"-"`
+}
+
+type uploadReq struct {
+	File *multipart.FileHeader `form:"file" binding:"required"`
+	Host string                `form:"host"`
+}
+
+func newEngine() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	Register(engine, WithTitle("test"), WithUI("/docs"))
+	engine.PUT("/users/:id", handler.Handle(func(ctx context.Context, req *userReq) (*userResp, error) {
+		return &userResp{}, nil
+	}, handler.WithSummary("update user"), handler.WithTags("user")))
+	engine.GET("/users/:id", handler.New(func(ctx context.Context, req *userReq) (*userResp, error) {
+		return &userResp{}, nil
+	}))
+	engine.POST("/upload", handler.HandleWithoutResponse(func(ctx context.Context, req *uploadReq) error {
+		return nil
+	}))
+	engine.GET("/plain", func(c *gin.Context) {})
+	return engine
+}
+
+func TestGenerate(t *testing.T) {
+	engine := newEngine()
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expect 200, got %d", w.Code)
+	}
+	doc := Document{}
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.OpenAPI != Version || doc.Info.Title != "test" || len(doc.Paths) != 2 {
+		t.Fatalf("unexpected document: %s", w.Body.String())
+	}
+
+	put := doc.Paths["/users/{id}"]["put"]
+	if put == nil || put.Summary != "update user" || put.OperationID != "putUsersId" || len(put.Tags) != 1 {
+		t.Fatalf("unexpected put operation: %+v", put)
+	}
+	if len(put.Parameters) != 3 || put.Parameters[0].In != "path" || put.Parameters[1].In != "header" || put.Parameters[2].In != "query" {
+		t.Fatalf("unexpected put parameters: %+v", put.Parameters)
+	}
+	body := put.RequestBody.Content[gin.MIMEJSON].Schema
+	if len(body.Properties) != 2 || body.Required[0] != "name" || body.Properties["name"].Description != "the user name" ||
+		len(body.Properties["status"].Enum) != 2 {
+		t.Fatalf("unexpected put body: %+v", body)
+	}
+	data := put.Responses["200"].Content[gin.MIMEJSON].Schema.Properties["data"]
+	if data.Ref != refPrefix+"openapi.userResp" {
+		t.Fatalf("unexpected response data: %+v", data)
+	}
+	resp := doc.Components.Schemas["openapi.userResp"]
+	if len(resp.Properties) != 3 || resp.Properties["created_at"].Format != "date-time" ||
+		resp.Properties["address"].Ref != refPrefix+"openapi.Address" {
+		t.Fatalf("unexpected response schema: %+v", resp)
+	}
+	if next := doc.Components.Schemas["openapi.Address"].Properties["next"]; next.Ref != refPrefix+"openapi.Address" {
+		t.Fatalf("unexpected recursive schema: %+v", next)
+	}
+
+	get := doc.Paths["/users/{id}"]["get"]
+	if get.RequestBody != nil || len(get.Parameters) != 3 {
+		t.Fatalf("unexpected get operation: %+v", get)
+	}
+
+	upload := doc.Paths["/upload"]["post"]
+	form := upload.RequestBody.Content[gin.MIMEMultipartPOSTForm].Schema
+	if form.Properties["file"].Format != "binary" || upload.Responses["200"].Content != nil {
+		t.Fatalf("unexpected upload operation: %+v", upload)
+	}
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"\/openapi.json"`) {
+		t.Fatalf("unexpected ui page: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestGenerateWithEncoder(t *testing.T) {
+	encoder := result.NewEncoder(result.WithKeys("errcode", "errmsg", "payload"), result.WithBoolCode(0, -1))
+	doc := Generate(newEngine().Routes(), newConfig(WithEncoder(encoder)))
+	envelope := doc.Paths["/users/{id}"]["put"].Responses["200"].Content[gin.MIMEJSON].Schema
+	if len(envelope.Properties) != 3 || envelope.Properties["errcode"] == nil || envelope.Properties["errmsg"] == nil ||
+		envelope.Properties["payload"].Ref != refPrefix+"openapi.userResp" {
+		t.Fatalf("unexpected envelope: %+v", envelope)
+	}
+	// the encoder without keys is documented as the default one
+	doc = Generate(newEngine().Routes(), newConfig(WithEncoder(result.EncoderFunc(func(res *result.Result) interface{} {
+		return res
+	}))))
+	if envelope = doc.Paths["/users/{id}"]["put"].Responses["200"].Content[gin.MIMEJSON].Schema; envelope.Properties["data"] == nil {
+		t.Fatalf("unexpected envelope: %+v", envelope)
+	}
+}
+
+func TestConvertPath(t *testing.T) {
+	if path := convertPath("/users/:id/files/*path"); path != "/users/{id}/files/{path}" {
+		t.Fatalf("unexpected path %s", path)
+	}
+}
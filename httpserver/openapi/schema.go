@@ -0,0 +1,213 @@
+package openapi
+
+import (
+	"encoding/json"
+	"mime/multipart"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const refPrefix = "#/components/schemas/"
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	fileHeaderType = reflect.TypeOf(multipart.FileHeader{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+
+	invalidNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
+
+// schemaBuilder Build the schemas of go types, the named structs are collected into the components and referenced
+type schemaBuilder struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{schemas: make(map[string]*Schema), names: make(map[reflect.Type]string)}
+}
+
+func (b *schemaBuilder) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case fileHeaderType:
+		return &Schema{Type: "string", Format: "binary"}
+	case rawMessageType:
+		return &Schema{}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		min := float64(0)
+		return &Schema{Type: "integer", Format: "int32", Minimum: &min}
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		min := float64(0)
+		return &Schema{Type: "integer", Format: "int64", Minimum: &min}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: b.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t, nil)
+		}
+		return &Schema{Ref: refPrefix + b.define(t)}
+	}
+	// interface, func, chan and so on
+	return &Schema{}
+}
+
+// define Add the named struct into the components and return its name
+func (b *schemaBuilder) define(t reflect.Type) string {
+	if name, ok := b.names[t]; ok {
+		return name
+	}
+	base := invalidNameChars.ReplaceAllString(t.String(), "_")
+	name := base
+	for i := 2; b.schemas[name] != nil; i++ {
+		name = base + "_" + strconv.Itoa(i)
+	}
+	b.names[t] = name
+	// placeholder for recursive types
+	b.schemas[name] = &Schema{}
+	*b.schemas[name] = *b.structSchema(t, nil)
+	return name
+}
+
+// structSchema The schema of the struct with the fields accepted by filter, all json fields are accepted if filter is nil
+func (b *schemaBuilder) structSchema(t reflect.Type, filter func(field reflect.StructField) bool) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for _, field := range fields(t) {
+		if filter != nil && !filter(field) {
+			continue
+		}
+		name, asString, ok := jsonName(field)
+		if !ok {
+			continue
+		}
+		property := b.fieldSchema(field)
+		if asString && property.Ref == "" {
+			property.Type, property.Format = "string", ""
+		}
+		s.Properties[name] = property
+		if isRequired(field) {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return s
+}
+
+// fieldSchema The schema of the field with the description and the rules of tag "binding" applied
+func (b *schemaBuilder) fieldSchema(field reflect.StructField) *Schema {
+	s := b.schema(field.Type)
+	if s.Ref != "" {
+		// the siblings of $ref are ignored in OpenAPI 3.0
+		return s
+	}
+	s.Description = field.Tag.Get("description")
+	for _, v := range oneOf(field) {
+		switch s.Type {
+		case "integer", "number":
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				s.Enum = append(s.Enum, f)
+			}
+		default:
+			s.Enum = append(s.Enum, v)
+		}
+	}
+	return s
+}
+
+// fields The exported fields of the struct with the embedded structs flattened, the same as encoding/json
+func fields(t reflect.Type) []reflect.StructField {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	result := make([]reflect.StructField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			ft := field.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && strings.SplitN(field.Tag.Get("json"), ",", 2)[0] == "" {
+				result = append(result, fields(ft)...)
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		result = append(result, field)
+	}
+	return result
+}
+
+// jsonName The name of the field in json, and whether it is encoded as string by the option ",string"
+func jsonName(field reflect.StructField) (name string, asString bool, ok bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, false
+	}
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	if name == "" {
+		name = field.Name
+	}
+	for _, opt := range parts[1:] {
+		if opt == "string" {
+			asString = true
+		}
+	}
+	return name, asString, true
+}
+
+// tagName The name in tag like "name,default=value", "-" or empty tag returns false
+func tagName(field reflect.StructField, tag string) (string, bool) {
+	name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+	return name, name != "" && name != "-"
+}
+
+func bindingRules(field reflect.StructField) []string {
+	return strings.Split(field.Tag.Get("binding"), ",")
+}
+
+func isRequired(field reflect.StructField) bool {
+	for _, rule := range bindingRules(field) {
+		if rule == "required" {
+			return true
+		}
+	}
+	return false
+}
+
+func oneOf(field reflect.StructField) []string {
+	for _, rule := range bindingRules(field) {
+		if strings.HasPrefix(rule, "oneof=") {
+			return strings.Fields(strings.TrimPrefix(rule, "oneof="))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package panics
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// maxDedupEntries the entries expired are swept when the count exceeds it
+const maxDedupEntries = 1024
+
+type dedup struct {
+	reporter Reporter
+	window   time.Duration
+	mu       sync.Mutex
+	entries  map[string]*dedupEntry
+	now      func() time.Time
+}
+
+type dedupEntry struct {
+	reported   time.Time
+	suppressed int
+}
+
+// Dedup Report the same panics, with the same value on the same route, at most once in window,
+// the count of the suppressed ones is reported with the next one, so that a panic loop does not flood the reporter
+// 相同路由上值相同的panic在window内至多上报一次, 被抑制的数量随下一次上报, 以免panic循环淹没reporter
+func Dedup(reporter Reporter, window time.Duration) Reporter {
+	return &dedup{reporter: reporter, window: window, entries: make(map[string]*dedupEntry), now: time.Now}
+}
+
+func (d *dedup) Report(ctx context.Context, p *Panic) error {
+	key := p.Method + " " + p.Route + " " + p.Value
+	now := d.now()
+	d.mu.Lock()
+	entry, ok := d.entries[key]
+	if ok && now.Sub(entry.reported) < d.window {
+		entry.suppressed++
+		d.mu.Unlock()
+		return nil
+	}
+	suppressed := 0
+	if ok {
+		suppressed = entry.suppressed
+	}
+	if len(d.entries) >= maxDedupEntries {
+		for k, e := range d.entries {
+			if now.Sub(e.reported) >= d.window {
+				delete(d.entries, k)
+			}
+		}
+	}
+	d.entries[key] = &dedupEntry{reported: now}
+	d.mu.Unlock()
+
+	reported := *p
+	reported.Suppressed += suppressed
+	return d.reporter.Report(ctx, &reported)
+}
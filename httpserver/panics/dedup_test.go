@@ -0,0 +1,39 @@
+package panics
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDedup(t *testing.T) {
+	var reported []Panic
+	now := time.Now()
+	d := Dedup(ReporterFunc(func(ctx context.Context, p *Panic) error {
+		reported = append(reported, *p)
+		return nil
+	}), time.Minute).(*dedup)
+	d.now = func() time.Time {
+		return now
+	}
+	report := func(route, value string) {
+		if err := d.Report(context.Background(), &Panic{Method: "GET", Route: route, Value: value}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report("/a", "boom")
+	report("/a", "boom")
+	report("/a", "boom")
+	report("/b", "boom")
+	report("/a", "other")
+	if len(reported) != 3 {
+		t.Fatalf("expect 3 reported, got %d", len(reported))
+	}
+
+	now = now.Add(time.Minute)
+	report("/a", "boom")
+	if len(reported) != 4 || reported[3].Suppressed != 2 {
+		t.Fatalf("expect the suppressed count reported, got %+v", reported)
+	}
+}
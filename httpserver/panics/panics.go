@@ -0,0 +1,60 @@
+package panics
+
+import (
+	"context"
+	"github.com/whereabouts/sdk/logger"
+	"github.com/whereabouts/sdk/trace"
+	"time"
+)
+
+// reportTimeout the max duration of reporting a panic by a reporter
+const reportTimeout = 30 * time.Second
+
+// Panic A panic recovered from a request
+// 从请求中恢复的panic
+type Panic struct {
+	Value     string    `json:"value"`
+	Stack     string    `json:"stack"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Route     string    `json:"route"`
+	RequestID string    `json:"request_id"`
+	Time      time.Time `json:"time"`
+	// Suppressed the count of the same panics not reported since the last report, see Dedup
+	// 自上次上报以来未上报的相同panic数, 参考Dedup
+	Suppressed int `json:"suppressed"`
+}
+
+// Reporter Report the panic to somewhere, such as an email or a webhook
+// 将panic上报到某处, 例如邮件或webhook
+type Reporter interface {
+	Report(ctx context.Context, p *Panic) error
+}
+
+type ReporterFunc func(ctx context.Context, p *Panic) error
+
+func (f ReporterFunc) Report(ctx context.Context, p *Panic) error {
+	return f(ctx, p)
+}
+
+// Report Run the reporters asynchronously, so that the response is not delayed, the errors are logged.
+// The context of reporting is detached from the request but keeps its request id and trace
+// 异步执行reporter以免延迟响应, 错误会被记录. 上报使用的context与请求分离, 但保留其request id与trace
+func Report(ctx context.Context, p *Panic, reporters ...Reporter) {
+	if len(reporters) == 0 {
+		return
+	}
+	detached := trace.WithRequestID(context.Background(), trace.RequestIDFrom(ctx))
+	if sc := trace.SpanContextFrom(ctx); sc.IsValid() {
+		detached = trace.WithSpanContext(detached, sc)
+	}
+	for _, reporter := range reporters {
+		go func(reporter Reporter) {
+			ctx, cancel := context.WithTimeout(detached, reportTimeout)
+			defer cancel()
+			if err := reporter.Report(ctx, p); err != nil {
+				logger.WithContext(ctx).Errorf("report panic by %T err: %v", reporter, err)
+			}
+		}(reporter)
+	}
+}
@@ -0,0 +1,38 @@
+package panics
+
+import (
+	"context"
+	"fmt"
+	"github.com/whereabouts/sdk/emailc"
+	"github.com/whereabouts/sdk/httpc"
+	"html"
+)
+
+// Email Send the panic to the receivers by email
+// 通过邮件将panic发送给收件人
+func Email(client *emailc.Client, sender string, receivers ...string) Reporter {
+	return ReporterFunc(func(ctx context.Context, p *Panic) error {
+		msg := emailc.NewMessage().WithSender(sender).WithReceiver(receivers...).
+			WithTitle(fmt.Sprintf("[panic] %s %s: %s", p.Method, p.Path, p.Value)).
+			WithHtml(formatHtml(p)).WithDate(p.Time)
+		return client.Send(msg)
+	})
+}
+
+// Webhook Post the panic as JSON to the path by client
+// 通过client将panic以JSON格式POST到path
+func Webhook(client httpc.Client, path string) Reporter {
+	return ReporterFunc(func(ctx context.Context, p *Panic) error {
+		return client.PostJSON(ctx, path, p, nil, nil)
+	})
+}
+
+func formatHtml(p *Panic) string {
+	suppressed := ""
+	if p.Suppressed > 0 {
+		suppressed = fmt.Sprintf("<p>%d same panics were suppressed before it</p>", p.Suppressed)
+	}
+	return fmt.Sprintf("<p><b>%s</b></p><p>%s %s (route: %s)<br/>request id: %s<br/>time: %s</p>%s<pre>%s</pre>",
+		html.EscapeString(p.Value), html.EscapeString(p.Method), html.EscapeString(p.Path), html.EscapeString(p.Route),
+		html.EscapeString(p.RequestID), p.Time.Format("2006-01-02 15:04:05.000"), suppressed, html.EscapeString(p.Stack))
+}
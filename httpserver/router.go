@@ -0,0 +1,206 @@
+package httpserver
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/whereabouts/sdk/httpserver/handler"
+	"github.com/whereabouts/sdk/httpserver/hook"
+	"github.com/whereabouts/sdk/httpserver/middleware"
+	"net/http"
+	"path"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// RouteGroup Register the routes under a path prefix with the middlewares and handler options of the group,
+// the method of route is converted by handler.NewWithOptions, and the options are applied by handler.Apply
+// to a gin.HandlerFunc, such as the one created by handler.Handle. The duplicate or conflicting routes, and the ones
+// whose options can not be applied, are not registered but returned as errors by Run
+// 在路径前缀下以组的中间件与handler选项注册路由, 路由的方法通过handler.NewWithOptions转换,
+// gin.HandlerFunc(例如由handler.Handle创建的)则通过handler.Apply应用选项. 重复或冲突的路由, 以及无法应用选项的路由
+// 不会被注册, 而是由Run以错误返回
+//
+// example:
+//
+//	v1 := s.Group("/v1", middleware.JWTAuth(secret)).WithOptions(handler.WithTags("user"))
+//	v1.GET("/users/:id", h.GetUser).POST("/users", h.CreateUser, handler.WithSummary("create user"))
+type RouteGroup interface {
+	// Group Create a sub group with the path prefix and middlewares appended
+	// 创建追加了路径前缀与中间件的子组
+	Group(relativePath string, middlewares ...middleware.Middleware) RouteGroup
+	// WithOptions Create a sub group of the same path whose routes are created with the handler options appended
+	// 创建相同路径的子组, 其路由创建时追加这些handler选项
+	WithOptions(options ...handler.Option) RouteGroup
+	Use(middlewares ...middleware.Middleware) RouteGroup
+	Handle(httpMethod, relativePath string, method interface{}, options ...handler.Option) RouteGroup
+	GET(relativePath string, method interface{}, options ...handler.Option) RouteGroup
+	POST(relativePath string, method interface{}, options ...handler.Option) RouteGroup
+	PUT(relativePath string, method interface{}, options ...handler.Option) RouteGroup
+	PATCH(relativePath string, method interface{}, options ...handler.Option) RouteGroup
+	DELETE(relativePath string, method interface{}, options ...handler.Option) RouteGroup
+	HEAD(relativePath string, method interface{}, options ...handler.Option) RouteGroup
+	OPTIONS(relativePath string, method interface{}, options ...handler.Option) RouteGroup
+	BasePath() string
+}
+
+// Route A route registered by RouteGroup
+// 由RouteGroup注册的路由
+type Route struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	// Handler the name of the method handling the route
+	// 处理该路由的方法名
+	Handler string `json:"handler"`
+}
+
+// routeTable The routes registered by the groups of a server and the errors of registration
+type routeTable struct {
+	mu     sync.Mutex
+	routes []Route
+	seen   map[string]struct{}
+	errs   hook.Errors
+}
+
+func newRouteTable() *routeTable {
+	return &routeTable{seen: make(map[string]struct{})}
+}
+
+func (t *routeTable) Routes() []Route {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Route{}, t.routes...)
+}
+
+func (t *routeTable) Err() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append(hook.Errors{}, t.errs...).Err()
+}
+
+// add Register the route on group, the duplicate one and the panics of creating or registering the handler are recorded as errors
+func (t *routeTable) add(group *gin.RouterGroup, httpMethod, relativePath string, method interface{}, options []handler.Option) {
+	route := Route{Method: httpMethod, Path: joinPaths(group.BasePath(), relativePath), Handler: nameOf(method)}
+	key := route.Method + " " + route.Path
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.seen[key]; ok {
+		t.errs = append(t.errs, errors.Errorf("duplicate route %s by %s", key, route.Handler))
+		return
+	}
+	if err := register(group, httpMethod, relativePath, method, options); err != nil {
+		t.errs = append(t.errs, errors.Wrapf(err, "register route %s err", key))
+		return
+	}
+	t.seen[key] = struct{}{}
+	t.routes = append(t.routes, route)
+}
+
+func register(group *gin.RouterGroup, httpMethod, relativePath string, method interface{}, options []handler.Option) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("%v", r)
+		}
+	}()
+	var h gin.HandlerFunc
+	switch m := method.(type) {
+	case gin.HandlerFunc:
+		h = m
+	case func(*gin.Context):
+		h = m
+	default:
+		group.Handle(httpMethod, relativePath, handler.NewWithOptions(method, options...))
+		return nil
+	}
+	// the options of the route and group are applied to the handler func created, instead of dropped silently
+	if len(options) > 0 {
+		if h, err = handler.Apply(h, options...); err != nil {
+			return err
+		}
+	}
+	group.Handle(httpMethod, relativePath, h)
+	return nil
+}
+
+type routeGroup struct {
+	table   *routeTable
+	group   *gin.RouterGroup
+	options []handler.Option
+}
+
+func (g *routeGroup) Group(relativePath string, middlewares ...middleware.Middleware) RouteGroup {
+	return &routeGroup{table: g.table, group: g.group.Group(relativePath, middlewares...), options: g.options}
+}
+
+func (g *routeGroup) WithOptions(options ...handler.Option) RouteGroup {
+	merged := append(append(make([]handler.Option, 0, len(g.options)+len(options)), g.options...), options...)
+	return &routeGroup{table: g.table, group: g.group, options: merged}
+}
+
+func (g *routeGroup) Use(middlewares ...middleware.Middleware) RouteGroup {
+	g.group.Use(middlewares...)
+	return g
+}
+
+func (g *routeGroup) Handle(httpMethod, relativePath string, method interface{}, options ...handler.Option) RouteGroup {
+	merged := append(append(make([]handler.Option, 0, len(g.options)+len(options)), g.options...), options...)
+	g.table.add(g.group, strings.ToUpper(httpMethod), relativePath, method, merged)
+	return g
+}
+
+func (g *routeGroup) GET(relativePath string, method interface{}, options ...handler.Option) RouteGroup {
+	return g.Handle(http.MethodGet, relativePath, method, options...)
+}
+
+func (g *routeGroup) POST(relativePath string, method interface{}, options ...handler.Option) RouteGroup {
+	return g.Handle(http.MethodPost, relativePath, method, options...)
+}
+
+func (g *routeGroup) PUT(relativePath string, method interface{}, options ...handler.Option) RouteGroup {
+	return g.Handle(http.MethodPut, relativePath, method, options...)
+}
+
+func (g *routeGroup) PATCH(relativePath string, method interface{}, options ...handler.Option) RouteGroup {
+	return g.Handle(http.MethodPatch, relativePath, method, options...)
+}
+
+func (g *routeGroup) DELETE(relativePath string, method interface{}, options ...handler.Option) RouteGroup {
+	return g.Handle(http.MethodDelete, relativePath, method, options...)
+}
+
+func (g *routeGroup) HEAD(relativePath string, method interface{}, options ...handler.Option) RouteGroup {
+	return g.Handle(http.MethodHead, relativePath, method, options...)
+}
+
+func (g *routeGroup) OPTIONS(relativePath string, method interface{}, options ...handler.Option) RouteGroup {
+	return g.Handle(http.MethodOptions, relativePath, method, options...)
+}
+
+func (g *routeGroup) BasePath() string {
+	return g.group.BasePath()
+}
+
+// joinPaths Join the paths as gin does, the trailing slash of relative path is kept
+func joinPaths(absolutePath, relativePath string) string {
+	if relativePath == "" {
+		return absolutePath
+	}
+	finalPath := path.Join(absolutePath, relativePath)
+	if strings.HasSuffix(relativePath, "/") && !strings.HasSuffix(finalPath, "/") {
+		return finalPath + "/"
+	}
+	return finalPath
+}
+
+func nameOf(method interface{}) string {
+	v := reflect.ValueOf(method)
+	if v.Kind() != reflect.Func {
+		return fmt.Sprintf("%T", method)
+	}
+	if f := runtime.FuncForPC(v.Pointer()); f != nil {
+		return strings.TrimSuffix(f.Name(), "-fm")
+	}
+	return v.Type().String()
+}
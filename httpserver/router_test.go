@@ -0,0 +1,136 @@
+package httpserver
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/whereabouts/sdk/httpserver/handler"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type echoReq struct {
+	Name string `form:"name" uri:"name"`
+}
+
+type echoResp struct {
+	Name string `json:"name"`
+}
+
+func echo(ctx context.Context, req *echoReq) (*echoResp, error) {
+	return &echoResp{Name: req.Name}, nil
+}
+
+func echoWithContext(ctx context.Context, req *echoReq, c *gin.Context) (*echoResp, error) {
+	return &echoResp{Name: c.GetHeader("X-Group") + req.Name}, nil
+}
+
+func TestRouteGroup(t *testing.T) {
+	s := NewServer(WithPort(0), WithMode(ModeTest))
+	tag := func(c *gin.Context) {
+		c.Request.Header.Set("X-Group", c.Request.Header.Get("X-Group")+"v1.")
+	}
+	v1 := s.Group("/v1", tag).WithOptions(handler.WithContext())
+	v1.GET("/users/:name", echoWithContext).
+		Group("admin", tag).POST("/users", echoWithContext)
+	s.Group("/").GET("/ping", func(c *gin.Context) {
+		c.String(http.StatusOK, "pong")
+	}).GET("/echo", echo)
+
+	for _, tc := range []struct {
+		method, path, expect string
+	}{
+		{http.MethodGet, "/v1/users/foo", `"name":"v1.foo"`},
+		{http.MethodPost, "/v1/admin/users?name=bar", `"name":"v1.v1.bar"`},
+		{http.MethodGet, "/ping", "pong"},
+		{http.MethodGet, "/echo?name=baz", `"name":"baz"`},
+	} {
+		w := httptest.NewRecorder()
+		s.Kernel().ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), tc.expect) {
+			t.Fatalf("%s %s expect %s, got %d %s", tc.method, tc.path, tc.expect, w.Code, w.Body.String())
+		}
+	}
+
+	routes := s.RouteTable()
+	expect := []Route{
+		{Method: http.MethodGet, Path: "/v1/users/:name"},
+		{Method: http.MethodPost, Path: "/v1/admin/users"},
+		{Method: http.MethodGet, Path: "/ping"},
+		{Method: http.MethodGet, Path: "/echo"},
+	}
+	if len(routes) != len(expect) {
+		t.Fatalf("expect %d routes, got %+v", len(expect), routes)
+	}
+	for i, route := range routes {
+		if route.Method != expect[i].Method || route.Path != expect[i].Path {
+			t.Fatalf("expect route %+v, got %+v", expect[i], route)
+		}
+	}
+	if !strings.HasSuffix(routes[0].Handler, "echoWithContext") {
+		t.Fatalf("expect the handler name echoWithContext, got %s", routes[0].Handler)
+	}
+}
+
+func TestRouteGroupErr(t *testing.T) {
+	s := NewServer(WithPort(0), WithMode(ModeTest))
+	api := s.Group("/api")
+	api.GET("/users/:name", echo)
+	// duplicate
+	s.Group("/api/").GET("users/:name", echo)
+	// conflict with the wildcard of gin
+	api.GET("/users/:id", echo)
+	// invalid handler method
+	api.GET("/invalid", func() {})
+
+	if routes := s.RouteTable(); len(routes) != 1 {
+		t.Fatalf("expect only the first route registered, got %+v", routes)
+	}
+	err := s.Run(context.Background())
+	if err == nil {
+		t.Fatal("expect the registration err")
+	}
+	for _, msg := range []string{"duplicate route GET /api/users/:name", "GET /api/users/:id", "GET /api/invalid"} {
+		if !strings.Contains(err.Error(), msg) {
+			t.Fatalf("expect err containing %q, got %v", msg, err)
+		}
+	}
+}
+
+func TestRouteGroupHandlerOptions(t *testing.T) {
+	s := NewServer(WithPort(0), WithMode(ModeTest))
+	api := s.Group("/api").WithOptions(handler.WithTags("user"))
+	api.GET("/echo", handler.Handle(echo, handler.WithTags("echo")), handler.WithSummary("echo"), handler.WithTimeout(time.Second))
+	for _, route := range s.Kernel().Routes() {
+		if route.Path != "/api/echo" {
+			continue
+		}
+		meta, ok := handler.Describe(route.HandlerFunc)
+		if !ok || meta.Summary != "echo" || strings.Join(meta.Tags, ",") != "echo,user" {
+			t.Fatalf("expect the options applied, got %+v", meta)
+		}
+	}
+	w := httptest.NewRecorder()
+	s.Kernel().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/echo?name=foo", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"name":"foo"`) {
+		t.Fatalf("unexpected response %d %s", w.Code, w.Body.String())
+	}
+
+	// the options can not be applied
+	api.WithOptions(handler.WithContext()).GET("/context", handler.Handle(echo))
+	api.GET("/plain", func(c *gin.Context) {})
+	if routes := s.RouteTable(); len(routes) != 1 {
+		t.Fatalf("expect only the first route registered, got %+v", routes)
+	}
+	err := s.Run(context.Background())
+	if err == nil {
+		t.Fatal("expect the registration err")
+	}
+	for _, msg := range []string{"GET /api/context", "GET /api/plain"} {
+		if !strings.Contains(err.Error(), msg) {
+			t.Fatalf("expect err containing %q, got %v", msg, err)
+		}
+	}
+}
@@ -2,16 +2,27 @@ package httpserver
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
+	"github.com/whereabouts/sdk/httpserver/admin"
+	"github.com/whereabouts/sdk/httpserver/handler"
+	"github.com/whereabouts/sdk/httpserver/health"
 	"github.com/whereabouts/sdk/httpserver/hook"
+	"github.com/whereabouts/sdk/httpserver/metrics"
 	"github.com/whereabouts/sdk/httpserver/middleware"
+	"github.com/whereabouts/sdk/httpserver/openapi"
+	"github.com/whereabouts/sdk/httpserver/ws"
 	"github.com/whereabouts/sdk/logger"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 )
 
 type Server interface {
@@ -20,35 +31,142 @@ type Server interface {
 	Kernel() (engine *gin.Engine)
 	Run(ctx context.Context) error
 	Close(ctx context.Context) error
-	OnShutdown(shutdownHook hook.ShutdownHook) Server
+	// OnShutdown the timeout of hook defaults to the HookTimeout of config
+	// hook的超时时间默认为config中的HookTimeout
+	OnShutdown(shutdownHook hook.ShutdownHook, timeout ...time.Duration) Server
 	OnBeforeRun(hook.RunHook) Server
+	OnReload(reloadHook hook.ReloadHook) Server
 	Routes(routes Router) Server
+	// Group Create a route group under the path prefix with the middlewares
+	// 以路径前缀与中间件创建路由组
+	Group(relativePath string, middlewares ...middleware.Middleware) RouteGroup
+	// RouteTable the routes registered by the route groups in registration order
+	// 由路由组注册的路由, 按注册顺序排列
+	RouteTable() []Route
+	// Hub the websocket hub to serve the connections by ws.Handle, which are closed when the server is closing
+	// 供ws.Handle服务连接的websocket hub, 其连接在server关闭时被关闭
+	Hub() *ws.Hub
+	// Ready report whether the server is serving and not shutting down
+	// 报告server是否正在服务且未在关闭中
+	Ready() bool
+}
+
+type shutdownHook struct {
+	hook    hook.ShutdownHook
+	timeout time.Duration
 }
 
 type server struct {
 	http.Server
+	engine      *gin.Engine
 	config      Config
+	bindings    []*binding
+	routes      *routeTable
+	hub         *ws.Hub
 	onBeforeRun []hook.RunHook
-	onShutdown  []hook.ShutdownHook
+	onShutdown  []shutdownHook
+	onReload    []hook.ReloadHook
+	ready       int32
+	closeOnce   sync.Once
+	closeErr    error
 }
 
 func NewServer(options ...Option) Server {
 	return NewServerWithConfig(newConfig(options...))
 }
 
+// NewServerWithConfig the ShutdownTimeout and HookTimeout not positive are taken as the defaults
+// ShutdownTimeout与HookTimeout不为正数时使用默认值
 func NewServerWithConfig(config Config) Server {
-	s := &server{config: config}
+	if config.ShutdownTimeout <= 0 {
+		config.ShutdownTimeout = defaultShutdownTimeout
+	}
+	if config.HookTimeout <= 0 {
+		config.HookTimeout = defaultHookTimeout
+	}
+	s := &server{config: config, routes: newRouteTable(), hub: ws.NewHub(config.hub...)}
 	gin.SetMode(config.Mode)
 	engine := gin.New()
 	// default Use middleware
-	engine.Use()
+	if config.encoder != nil {
+		engine.Use(handler.UseEncoder(config.encoder))
+	}
+	if config.registry != nil {
+		engine.Use(handler.UseRegistry(config.registry))
+	}
+	if config.bundle != nil {
+		engine.Use(handler.UseBundle(config.bundle))
+	}
+	// the health routes are mounted before the user middlewares, so that the probes are not logged
+	if config.health != nil {
+		health.New(s.Ready, config.health.checkers, config.health.options...).Register(engine)
+	}
+	// the metrics route is mounted on the admin port if set, otherwise it is mounted before its middleware,
+	// so that the scrapes are not recorded
+	var adminOptions []admin.Option
+	if config.metrics != nil {
+		path, registry := metrics.Route(config.metrics...)
+		if config.AdminPort > 0 {
+			adminOptions = append(adminOptions, admin.WithHandler(path, registry))
+		} else {
+			engine.GET(path, gin.WrapH(registry))
+		}
+		engine.Use(middleware.MetricsWithRegistry(registry))
+	}
+	if config.Secure != nil {
+		engine.Use(middleware.SecureHeaders(*config.Secure))
+	}
+	if config.CORS != nil {
+		engine.Use(middleware.CORS(*config.CORS))
+	}
+	if config.MaxBodySize > 0 {
+		engine.Use(middleware.BodyLimit(config.MaxBodySize))
+	}
 	// user set middleware
 	engine.Use(config.middlewares...)
-	s.Handler = engine
+	if config.openAPI != nil {
+		var options []openapi.Option
+		if config.Name != "" {
+			options = append(options, openapi.WithTitle(config.Name))
+		}
+		if config.encoder != nil {
+			options = append(options, openapi.WithEncoder(config.encoder))
+		}
+		openapi.Register(engine, append(options, config.openAPI...)...)
+	}
+	s.engine = engine
 	s.Addr = fmt.Sprintf(":%d", config.Port)
+	// the server embedded serves the port, the others serve the extra listeners
+	if config.Port >= 0 {
+		port := ListenerConfig{Network: NetworkTCP, Address: s.Addr, TLS: config.TLS, H2C: config.H2C}
+		s.Handler = newHandler(engine, port)
+		s.bindings = append(s.bindings, &binding{config: port, server: &s.Server})
+	} else {
+		s.Handler = engine
+	}
+	for _, listener := range config.Listeners {
+		s.bindings = append(s.bindings, &binding{config: listener, server: &http.Server{Handler: newHandler(engine, listener)}})
+	}
+	if config.AdminPort > 0 {
+		adminListener := ListenerConfig{Network: NetworkTCP, Address: fmt.Sprintf(":%d", config.AdminPort)}
+		s.bindings = append(s.bindings, &binding{config: adminListener, server: &http.Server{Handler: newAdminHandler(config, adminOptions...)}})
+	}
 	return s
 }
 
+// newAdminHandler Create the handler of admin endpoints showing the config without the admin token
+func newAdminHandler(config Config, options ...admin.Option) http.Handler {
+	var summary map[string]interface{}
+	if data, err := json.Marshal(config); err == nil && json.Unmarshal(data, &summary) == nil {
+		delete(summary, "admin_token")
+	}
+	options = append([]admin.Option{admin.WithName(config.Name), admin.WithSummary(summary)}, options...)
+	if config.AdminToken != "" {
+		options = append(options, admin.WithToken(config.AdminToken))
+	}
+	return admin.New(append(options, config.admin...)...)
+}
+
 type Router func(engine *gin.Engine)
 
 func (s *server) Routes(routes Router) Server {
@@ -56,39 +174,155 @@ func (s *server) Routes(routes Router) Server {
 	return s
 }
 
+func (s *server) Group(relativePath string, middlewares ...middleware.Middleware) RouteGroup {
+	return &routeGroup{table: s.routes, group: s.Kernel().Group(relativePath, middlewares...)}
+}
+
+func (s *server) RouteTable() []Route {
+	return s.routes.Routes()
+}
+
+func (s *server) Hub() *ws.Hub {
+	return s.hub
+}
+
 func (s *server) Name() string {
 	return s.config.Name
 }
 
+// Run Serve until ctx is done, the server fails, or SIGTERM, SIGQUIT or SIGINT is received, then close the server.
+// SIGHUP reloads the TLS certificates and runs the reload hooks without stopping the server.
+// The errors of registering routes by the route groups are returned before serving.
+// The server is closed with ctx, which bounds the shutdown, or with the values of ctx but not its cancellation
+// if the shutdown is caused by ctx done. The errors of serving and closing are returned as hook.Errors
+// 持续服务直到ctx结束, server出错, 或收到SIGTERM, SIGQUIT, SIGINT, 然后关闭server.
+// SIGHUP重新加载TLS证书并执行reload hook而不停止server. 路由组注册路由的错误在服务前返回.
+// 以ctx关闭server, ctx限制关闭的时间, 若因ctx结束而关闭, 则只使用ctx中的值而不继承其取消.
+// 服务与关闭过程中的错误以hook.Errors返回
 func (s *server) Run(ctx context.Context) error {
 	// register func before run
 	for _, beforeRun := range s.onBeforeRun {
 		beforeRun()
 	}
-	// register func on shutdown
-	for _, shutdown := range s.onShutdown {
-		s.RegisterOnShutdown(shutdown)
+	if err := s.routes.Err(); err != nil {
+		return errors.Wrap(err, "http server register routes err")
 	}
-	// server listenAndServe
-	ch := make(chan os.Signal)
-	go func() {
-		logger.Infof("http server is starting in port:%d", s.config.Port)
-		if err := s.ListenAndServe(); err != nil {
-			if !errors.Is(err, http.ErrServerClosed) {
-				logger.Printf("http server ListenAndServe err:%v\n", err)
-				ch <- sigErr
+	for _, route := range s.RouteTable() {
+		logger.Infof("http server route %-7s %s --> %s", route.Method, route.Path, route.Handler)
+	}
+	if len(s.bindings) == 0 {
+		return errors.New("http server has no listener to serve")
+	}
+	listeners := make([]net.Listener, 0, len(s.bindings))
+	for _, b := range s.bindings {
+		listener, err := b.listen()
+		if err != nil {
+			for _, l := range listeners {
+				_ = l.Close()
 			}
-			logger.Println("http server closed")
+			return errors.Wrapf(err, "http server listen %s err", b.config)
 		}
-	}()
+		listeners = append(listeners, listener)
+	}
+	serveErr := make(chan error, len(listeners))
+	for i, listener := range listeners {
+		go func(b *binding, listener net.Listener) {
+			logger.Infof("http server is serving on %s", b.config)
+			if err := b.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				serveErr <- errors.Wrapf(err, "serve %s", b.config)
+			}
+			logger.Printf("http server on %s closed\n", b.config)
+		}(s.bindings[i], listener)
+	}
+
 	// handle signal, to elegant closing server
-	signal.Notify(ch, syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGINT, sigErr)
-	logger.Printf("got signal %v, http server exit\n", <-ch)
-	return s.Close(ctx)
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGINT, syscall.SIGHUP)
+	defer signal.Stop(ch)
+	atomic.StoreInt32(&s.ready, 1)
+	var (
+		errs hook.Errors
+		err  error
+	)
+	for running := true; running; {
+		select {
+		case sig := <-ch:
+			if sig == syscall.SIGHUP {
+				logger.Printf("got signal %v, http server reload\n", sig)
+				s.reload(ctx)
+				continue
+			}
+			logger.Printf("got signal %v, http server exit\n", sig)
+			running = false
+		case err = <-serveErr:
+			logger.Printf("http server serve err:%v\n", err)
+			errs = append(errs, errors.Wrap(err, "http server serve err"))
+			running = false
+		case <-ctx.Done():
+			logger.Printf("context done, http server exit\n")
+			running = false
+		}
+	}
+	closeCtx := ctx
+	if ctx.Err() != nil {
+		closeCtx = detachedContext{parent: ctx}
+	}
+	if err = s.Close(closeCtx); err != nil {
+		if closeErrs, ok := err.(hook.Errors); ok {
+			errs = append(errs, closeErrs...)
+		} else {
+			errs = append(errs, err)
+		}
+	}
+	return errs.Err()
+}
+
+// detachedContext Keep the values of parent without its deadline and cancellation,
+// so that the shutdown caused by the parent done is bounded by the timeouts of config only
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
 }
 
-func (s *server) OnShutdown(f hook.ShutdownHook) Server {
-	s.onShutdown = append(s.onShutdown, f)
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
+
+// reload Reload the TLS certificates and run the reload hooks in registration order,
+// the errors are logged and do not stop the server
+func (s *server) reload(ctx context.Context) {
+	for _, b := range s.bindings {
+		if b.reloader == nil {
+			continue
+		}
+		if err := b.reloader.reload(); err != nil {
+			logger.Errorf("reload tls certificate of %s err: %v", b.config, err)
+		}
+	}
+	for i, reload := range s.onReload {
+		if err := reload(ctx); err != nil {
+			logger.Errorf("reload hook #%d err: %v", i, err)
+		}
+	}
+}
+
+func (s *server) OnShutdown(f hook.ShutdownHook, timeout ...time.Duration) Server {
+	h := shutdownHook{hook: f, timeout: time.Duration(s.config.HookTimeout) * time.Second}
+	if len(timeout) > 0 {
+		h.timeout = timeout[0]
+	}
+	s.onShutdown = append(s.onShutdown, h)
 	return s
 }
 
@@ -97,27 +331,110 @@ func (s *server) OnBeforeRun(f hook.RunHook) Server {
 	return s
 }
 
-func (s *server) Close(ctx context.Context) error {
-	logger.Println("http server is closing...")
-	return s.Shutdown(ctx)
+func (s *server) OnReload(f hook.ReloadHook) Server {
+	s.onReload = append(s.onReload, f)
+	return s
 }
 
-func (s *server) AddMiddlewares(middlewares ...middleware.Middleware) Server {
-	s.Kernel().Use(middlewares...)
-	return s
+func (s *server) Ready() bool {
+	return atomic.LoadInt32(&s.ready) == 1
 }
 
-func (s *server) Kernel() *gin.Engine {
-	return s.Handler.(*gin.Engine)
+// Close Shut down the server in phases, only the first call takes effect:
+// mark not ready, wait the drain period for the load balancers to notice, stop accepting connections and
+// wait the active requests and the websocket connections closed within ShutdownTimeout,
+// then run the shutdown hooks in reverse registration order.
+// 分阶段关闭server, 只有第一次调用生效: 标记为未就绪, 等待drain period让负载均衡感知,
+// 停止接收连接并在ShutdownTimeout内等待处理中的请求与被关闭的websocket连接, 然后按注册的逆序执行shutdown hook
+func (s *server) Close(ctx context.Context) error {
+	s.closeOnce.Do(func() {
+		s.closeErr = s.shutdown(ctx)
+	})
+	return s.closeErr
 }
 
-type signalErr string
+func (s *server) shutdown(ctx context.Context) error {
+	logger.Println("http server is closing...")
+	var errs hook.Errors
+
+	// phase 1: not ready
+	atomic.StoreInt32(&s.ready, 0)
+	for _, b := range s.bindings {
+		b.server.SetKeepAlivesEnabled(false)
+	}
 
-const sigErr = signalErr("httpserver err signal")
+	// phase 2: drain
+	if drain := time.Duration(s.config.DrainPeriod) * time.Second; drain > 0 {
+		logger.Printf("http server is draining for %v\n", drain)
+		select {
+		case <-time.After(drain):
+		case <-ctx.Done():
+		}
+	}
 
-func (s signalErr) Signal() {
+	// phase 3: stop accepting connections and wait the active requests,
+	// the websocket connections are hijacked, so they are closed by the hub
+	// the bindings are shut down concurrently, so that none keeps accepting while another waits its requests
+	shutdownCtx, cancel := context.WithTimeout(ctx, time.Duration(s.config.ShutdownTimeout)*time.Second)
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, b := range s.bindings {
+		wg.Add(1)
+		go func(b *binding) {
+			defer wg.Done()
+			if err := b.server.Shutdown(shutdownCtx); err != nil {
+				mu.Lock()
+				errs = append(errs, errors.Wrapf(err, "http server on %s shutdown err", b.config))
+				mu.Unlock()
+			}
+		}(b)
+	}
+	wg.Wait()
+	if err := s.hub.Close(shutdownCtx); err != nil {
+		errs = append(errs, err)
+	}
+	cancel()
 
+	// phase 4: run hooks in reverse order
+	for i := len(s.onShutdown) - 1; i >= 0; i-- {
+		if err := runShutdownHook(ctx, s.onShutdown[i]); err != nil {
+			errs = append(errs, errors.Wrapf(err, "shutdown hook #%d err", i))
+		}
+	}
+	return errs.Err()
 }
-func (s signalErr) String() string {
-	return "listen and serve err"
+
+// runShutdownHook Run the hook with its timeout, return when the hook returns or the timeout is reached
+func runShutdownHook(ctx context.Context, h shutdownHook) (err error) {
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- errors.Errorf("panic: %v", r)
+			}
+		}()
+		done <- h.hook(ctx)
+	}()
+	select {
+	case err = <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *server) AddMiddlewares(middlewares ...middleware.Middleware) Server {
+	s.Kernel().Use(middlewares...)
+	return s
+}
+
+func (s *server) Kernel() *gin.Engine {
+	return s.engine
 }
@@ -0,0 +1,374 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/whereabouts/sdk/httpserver/admin"
+	"github.com/whereabouts/sdk/httpserver/hook"
+	"github.com/whereabouts/sdk/httpserver/metrics"
+	"github.com/whereabouts/sdk/httpserver/ws"
+	"golang.org/x/net/websocket"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func waitReady(t *testing.T, s Server) {
+	for i := 0; i < 100; i++ {
+		if s.Ready() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("server is not ready")
+}
+
+func TestGracefulShutdown(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		order  []int
+		reload = make(chan struct{}, 1)
+	)
+	record := func(i int) {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, i)
+	}
+	s := NewServer(WithPort(0), WithMode(ModeTest))
+	s.OnShutdown(func(ctx context.Context) error {
+		record(0)
+		return nil
+	}).OnShutdown(func(ctx context.Context) error {
+		record(1)
+		return errors.New("close db failed")
+	}).OnShutdown(func(ctx context.Context) error {
+		record(2)
+		<-ctx.Done()
+		return nil
+	}, 50*time.Millisecond).OnReload(func(ctx context.Context) error {
+		reload <- struct{}{}
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Run(ctx)
+	}()
+	waitReady(t, s)
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-reload:
+	case <-time.After(time.Second):
+		t.Fatal("reload hook is not run")
+	}
+	if !s.Ready() {
+		t.Fatal("expect server ready after reload")
+	}
+
+	cancel()
+	var err error
+	select {
+	case err = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("server is not closed")
+	}
+	if s.Ready() {
+		t.Fatal("expect server not ready after closed")
+	}
+	errs, ok := err.(hook.Errors)
+	if !ok || len(errs) != 2 {
+		t.Fatalf("expect 2 errors, got %v", err)
+	}
+	if !errors.Is(errs[0], context.DeadlineExceeded) {
+		t.Fatalf("expect the first err to be timeout, got %v", errs[0])
+	}
+	if !reflect.DeepEqual(order, []int{2, 1, 0}) {
+		t.Fatalf("expect hooks run in reverse order, got %v", order)
+	}
+	// only the first close takes effect
+	if err = s.Close(context.Background()); err == nil {
+		t.Fatal("expect the same err of the first close")
+	}
+}
+
+func TestRunListenErr(t *testing.T) {
+	if err := NewServer(WithPort(-1), WithMode(ModeTest)).Run(context.Background()); err == nil {
+		t.Fatal("expect listen err")
+	}
+}
+
+func TestReadinessDuringShutdown(t *testing.T) {
+	s := NewServer(WithPort(0), WithMode(ModeTest), WithHealthChecks(), WithDrainPeriod(1))
+	readyz := func() int {
+		w := httptest.NewRecorder()
+		s.Kernel().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		return w.Code
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Run(ctx)
+	}()
+	waitReady(t, s)
+	if code := readyz(); code != http.StatusOK {
+		t.Fatalf("expect readiness 200, got %d", code)
+	}
+
+	cancel()
+	time.Sleep(100 * time.Millisecond)
+	// draining, the readiness fails while the server is still serving
+	if code := readyz(); code != http.StatusServiceUnavailable {
+		t.Fatalf("expect readiness 503 during shutdown, got %d", code)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSecurityConfig(t *testing.T) {
+	var config Config
+	if err := json.Unmarshal([]byte(`{
+		"mode": "test",
+		"cors": {"allow_origins": ["https://*.example.com"], "max_age": 600},
+		"secure": {"frame_options": "DENY"},
+		"max_body_size": 4
+	}`), &config); err != nil {
+		t.Fatal(err)
+	}
+	s := NewServerWithConfig(config)
+	s.Kernel().POST("/", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodOptions, "/", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	w := httptest.NewRecorder()
+	s.Kernel().ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Max-Age") != "600" || w.Header().Get("X-Frame-Options") != "DENY" {
+		t.Fatalf("unexpected preflight response %d %v", w.Code, w.Header())
+	}
+
+	w = httptest.NewRecorder()
+	s.Kernel().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("12345")))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expect 413, got %d", w.Code)
+	}
+}
+
+func TestAdminSummary(t *testing.T) {
+	h := newAdminHandler(newConfig(WithName("demo"), WithAdminPort(9090, admin.WithToken("secret"))))
+	req := httptest.NewRequest(http.MethodGet, admin.PathInfo, nil)
+	req.Header.Set("X-Admin-Token", "secret")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"admin_port":9090`) || strings.Contains(w.Body.String(), "admin_token") {
+		t.Fatalf("unexpected info %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestMetricsOnAdminPort(t *testing.T) {
+	s := NewServer(WithPort(0), WithMode(ModeTest), WithAdminPort(9090, admin.WithToken("secret")),
+		WithMetrics(metrics.WithRegistry(metrics.NewRegistry())))
+	s.Routes(func(engine *gin.Engine) {
+		engine.GET("/ping", func(c *gin.Context) {
+			c.String(http.StatusOK, "pong")
+		})
+	})
+	s.Kernel().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ping", nil))
+	w := httptest.NewRecorder()
+	s.Kernel().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expect the metrics not served on the server, got %d", w.Code)
+	}
+
+	bindings := s.(*server).bindings
+	adminHandler := bindings[len(bindings)-1].server.Handler
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("X-Admin-Token", "secret")
+	w = httptest.NewRecorder()
+	adminHandler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `route="/ping"`) {
+		t.Fatalf("expect the metrics served on the admin port, got %d %s", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	adminHandler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expect the metrics behind the admin token, got %d", w.Code)
+	}
+}
+
+func TestCloseWebSocket(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(WithPort(-1), WithMode(ModeTest), WithListener(listener))
+	closed := make(chan struct{})
+	s.Group("/").GET("/ws", ws.Handle(s.Hub(), func(ctx context.Context, conn *ws.Conn, msg *struct{}) error {
+		return nil
+	}, ws.WithOnClose(func(conn *ws.Conn) {
+		close(closed)
+	})))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Run(ctx)
+	}()
+	waitReady(t, s)
+
+	origin := "http://" + listener.Addr().String()
+	conn, err := websocket.Dial("ws://"+listener.Addr().String()+"/ws", "", origin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	cancel()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expect the websocket connection closed by the server closing")
+	}
+	if err = <-done; err != nil {
+		t.Fatal(err)
+	}
+	if s.Hub().Count("") != 0 {
+		t.Fatalf("expect the websocket connections drained, got %d", s.Hub().Count(""))
+	}
+}
+
+func TestShutdownWithBareConfig(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the config without ShutdownTimeout, such as the one loaded from JSON without shutdown_timeout
+	s := NewServerWithConfig(Config{Port: -1, Mode: ModeTest, Listeners: []ListenerConfig{{listener: listener}}})
+	entered := make(chan struct{})
+	s.Routes(func(engine *gin.Engine) {
+		engine.GET("/slow", func(c *gin.Context) {
+			close(entered)
+			time.Sleep(200 * time.Millisecond)
+			c.String(http.StatusOK, "done")
+		})
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Run(ctx)
+	}()
+	waitReady(t, s)
+
+	type response struct {
+		body string
+		err  error
+	}
+	responses := make(chan response, 1)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String() + "/slow")
+		if err != nil {
+			responses <- response{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		responses <- response{body: string(body), err: err}
+	}()
+	<-entered
+	cancel()
+	if r := <-responses; r.err != nil || r.body != "done" {
+		t.Fatalf("expect the in-flight request finished, got %q, err: %v", r.body, r.err)
+	}
+	if err = <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestShutdownHookWithBareConfig(t *testing.T) {
+	// the config without HookTimeout, such as the one loaded from JSON without hook_timeout
+	s := NewServerWithConfig(Config{Port: 0, Mode: ModeTest})
+	deadline := make(chan time.Duration, 1)
+	s.OnShutdown(func(ctx context.Context) error {
+		d, ok := ctx.Deadline()
+		if !ok {
+			deadline <- 0
+			return nil
+		}
+		deadline <- time.Until(d)
+		return nil
+	})
+	if err := s.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if d := <-deadline; d <= 0 || d > time.Duration(defaultHookTimeout)*time.Second {
+		t.Fatalf("expect the hook bounded by the default hook timeout, got %v", d)
+	}
+}
+
+func TestShutdownListenersTogether(t *testing.T) {
+	slowListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(WithPort(-1), WithMode(ModeTest),
+		WithListeners(ListenerConfig{listener: slowListener}, ListenerConfig{listener: otherListener}))
+	entered, release := make(chan struct{}), make(chan struct{})
+	s.Routes(func(engine *gin.Engine) {
+		engine.GET("/slow", func(c *gin.Context) {
+			close(entered)
+			<-release
+			c.String(http.StatusOK, "done")
+		})
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Run(ctx)
+	}()
+	waitReady(t, s)
+	go func() {
+		resp, err := http.Get("http://" + slowListener.Addr().String() + "/slow")
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-entered
+	cancel()
+
+	// the other listener stops accepting while the slow request is still active
+	closed := false
+	for i := 0; i < 100 && !closed; i++ {
+		conn, err := net.Dial("tcp", otherListener.Addr().String())
+		if err != nil {
+			closed = true
+			break
+		}
+		_ = conn.Close()
+		time.Sleep(10 * time.Millisecond)
+	}
+	close(release)
+	if !closed {
+		t.Fatal("expect the other listener closed while the slow request is active")
+	}
+	if err = <-done; err != nil {
+		t.Fatal(err)
+	}
+}
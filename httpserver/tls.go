@@ -0,0 +1,136 @@
+package httpserver
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"github.com/pkg/errors"
+	"github.com/whereabouts/sdk/logger"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+const defaultReloadInterval = 10
+
+type TLSConfig struct {
+	CertFile string `mapstructure:"cert_file" json:"cert_file"`
+	KeyFile  string `mapstructure:"key_file" json:"key_file"`
+	// ClientCAFile the client certificates are required and verified by the CAs in it if set, known as mTLS
+	// 设置时要求客户端证书并用其中的CA校验, 即mTLS
+	ClientCAFile string `mapstructure:"client_ca_file" json:"client_ca_file"`
+	// ReloadInterval the min seconds between the checks of whether the cert and key files are changed,
+	// they are reloaded without restarting the server if changed, default 10
+	// 检查证书与私钥文件是否变化的最小间隔秒数, 变化时无需重启server即可重新加载, 默认10
+	ReloadInterval int `mapstructure:"reload_interval" json:"reload_interval"`
+}
+
+// newTLSConfig Create the tls.Config which loads the certificate by the reloader
+func newTLSConfig(config TLSConfig) (*tls.Config, *certReloader, error) {
+	reloader, err := newCertReloader(config.CertFile, config.KeyFile, config.ReloadInterval)
+	if err != nil {
+		return nil, nil, err
+	}
+	tlsConfig := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}
+	if config.ClientCAFile != "" {
+		pem, err := ioutil.ReadFile(config.ClientCAFile)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "read client ca file err")
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, nil, errors.Errorf("no certificate found in client ca file %s", config.ClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, reloader, nil
+}
+
+// certReloader Reload the certificate when the cert or key file is changed, the check is made at most once
+// per interval during the handshakes, the certificate in use is kept if the reloading fails
+type certReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	modTime  time.Time
+	checked  time.Time
+}
+
+func newCertReloader(certFile, keyFile string, interval int) (*certReloader, error) {
+	if interval <= 0 {
+		interval = defaultReloadInterval
+	}
+	r := &certReloader{certFile: certFile, keyFile: keyFile, interval: time.Duration(interval) * time.Second}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	cert, due := r.cert, time.Since(r.checked) >= r.interval
+	r.mu.RUnlock()
+	if !due {
+		return cert, nil
+	}
+	if err := r.reloadIfChanged(); err != nil {
+		logger.Errorf("reload tls certificate err: %v", err)
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+func (r *certReloader) reloadIfChanged() error {
+	r.mu.Lock()
+	r.checked = time.Now()
+	r.mu.Unlock()
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return err
+	}
+	r.mu.RLock()
+	changed := !modTime.Equal(r.modTime)
+	r.mu.RUnlock()
+	if !changed {
+		return nil
+	}
+	return r.reload()
+}
+
+// reload Load the cert and key files whether they are changed or not
+func (r *certReloader) reload() error {
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return errors.Wrap(err, "load tls key pair err")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert, r.modTime, r.checked = &cert, modTime, time.Now()
+	return nil
+}
+
+func (r *certReloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, file := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, errors.Wrap(err, "stat tls file err")
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
@@ -0,0 +1,76 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/whereabouts/sdk/db/redisc"
+	"github.com/whereabouts/sdk/logger"
+)
+
+// Broker Fan out the messages broadcast by the hubs of all the instances
+// 在所有实例的hub间分发广播的消息
+type Broker interface {
+	// Publish Publish the JSON message to the room of all the instances
+	// 将JSON消息发布到所有实例的房间
+	Publish(ctx context.Context, room string, data []byte) error
+	// Subscribe Deliver the messages published by all the instances until ctx is done, ready is called once
+	// the subscription is confirmed, the hub subscribes again with backoff if it returns before ctx is done
+	// 投递所有实例发布的消息直到ctx结束, 订阅确认后调用ready, 若在ctx结束前返回, hub会退避后重新订阅
+	Subscribe(ctx context.Context, ready func(), deliver func(room string, data []byte)) error
+}
+
+type redisBroker struct {
+	client  *redisc.Client
+	channel string
+}
+
+type envelope struct {
+	Room string          `json:"room"`
+	Data json.RawMessage `json:"data"`
+}
+
+// NewRedisBroker Fan out the messages by the pub/sub of redis on the channel, the messages published
+// while an instance is disconnected from redis are lost for it
+// 通过redis在channel上的pub/sub分发消息, 实例与redis断开期间发布的消息对其丢失
+func NewRedisBroker(client *redisc.Client, channel string) Broker {
+	return &redisBroker{client: client, channel: channel}
+}
+
+func (b *redisBroker) Publish(ctx context.Context, room string, data []byte) error {
+	message, err := json.Marshal(envelope{Room: room, Data: data})
+	if err != nil {
+		return errors.Wrap(err, "encode websocket envelope err")
+	}
+	if err = b.client.Publish(ctx, b.channel, message).Err(); err != nil {
+		return errors.Wrap(err, "publish websocket message err")
+	}
+	return nil
+}
+
+func (b *redisBroker) Subscribe(ctx context.Context, ready func(), deliver func(room string, data []byte)) error {
+	pubsub := b.client.Subscribe(ctx, b.channel)
+	defer pubsub.Close()
+	// wait for the confirmation, so that the failure of subscribing is returned
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return errors.Wrap(err, "subscribe websocket channel err")
+	}
+	ready()
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case message, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			var e envelope
+			if err := json.Unmarshal([]byte(message.Payload), &e); err != nil {
+				logger.Errorf("decode websocket envelope err: %v", err)
+				continue
+			}
+			deliver(e.Room, e.Data)
+		}
+	}
+}
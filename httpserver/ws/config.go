@@ -0,0 +1,124 @@
+package ws
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPingInterval   = 30 * time.Second
+	defaultPongTimeout    = 60 * time.Second
+	defaultWriteTimeout   = 10 * time.Second
+	defaultMaxMessageSize = 1 << 20
+	defaultQueueSize      = 64
+)
+
+type config struct {
+	pingInterval   time.Duration
+	pongTimeout    time.Duration
+	writeTimeout   time.Duration
+	maxMessageSize int
+	queueSize      int
+	checkOrigin    func(r *http.Request) bool
+	onConnect      func(conn *Conn) error
+	onClose        func(conn *Conn)
+}
+
+type Option func(config *config)
+
+func newConfig(options ...Option) config {
+	conf := config{
+		pingInterval:   defaultPingInterval,
+		pongTimeout:    defaultPongTimeout,
+		writeTimeout:   defaultWriteTimeout,
+		maxMessageSize: defaultMaxMessageSize,
+		queueSize:      defaultQueueSize,
+		checkOrigin:    sameOrigin,
+	}
+	for _, option := range options {
+		option(&conf)
+	}
+	return conf
+}
+
+// WithPingInterval send a ping every d, default 30s
+// 每隔d发送一次ping, 默认30s
+func WithPingInterval(d time.Duration) Option {
+	return func(conf *config) {
+		conf.pingInterval = d
+	}
+}
+
+// WithPongTimeout close the connection if nothing, including the pong, is received within d, default 60s,
+// it should be longer than the ping interval
+// 在d内未收到任何数据(包括pong)时关闭连接, 默认60s, 应大于ping的间隔
+func WithPongTimeout(d time.Duration) Option {
+	return func(conf *config) {
+		conf.pongTimeout = d
+	}
+}
+
+// WithWriteTimeout the deadline of writing a message, default 10s
+// 写入一条消息的期限, 默认10s
+func WithWriteTimeout(d time.Duration) Option {
+	return func(conf *config) {
+		conf.writeTimeout = d
+	}
+}
+
+// WithMaxMessageSize the max bytes of a received message, the connection is closed if exceeded, default 1MB
+// 接收消息的最大字节数, 超出时关闭连接, 默认1MB
+func WithMaxMessageSize(size int) Option {
+	return func(conf *config) {
+		conf.maxMessageSize = size
+	}
+}
+
+// WithQueueSize the max messages broadcast by the hub waiting to be sent to a connection,
+// the connection is closed as too slow if exceeded, default 64
+// 等待发送给一个连接的hub广播消息的最大数量, 超出时连接因过慢被关闭, 默认64
+func WithQueueSize(size int) Option {
+	return func(conf *config) {
+		conf.queueSize = size
+	}
+}
+
+// WithCheckOrigin report whether the origin of request is allowed, only the same origin is allowed by default
+// 报告请求的origin是否被允许, 默认只允许同源
+func WithCheckOrigin(check func(r *http.Request) bool) Option {
+	return func(conf *config) {
+		conf.checkOrigin = check
+	}
+}
+
+// WithOnConnect called after the connection is established and before the messages are received,
+// the connection is closed with the error sent if it returns an error, which can be used to authenticate
+// 在连接建立后, 接收消息前调用, 返回错误时发送该错误并关闭连接, 可用于鉴权
+func WithOnConnect(onConnect func(conn *Conn) error) Option {
+	return func(conf *config) {
+		conf.onConnect = onConnect
+	}
+}
+
+// WithOnClose called after the connection is closed
+// 在连接关闭后调用
+func WithOnClose(onClose func(conn *Conn)) Option {
+	return func(conf *config) {
+		conf.onClose = onClose
+	}
+}
+
+// sameOrigin Allow the requests without Origin, which are not sent by browsers, or from the same host
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
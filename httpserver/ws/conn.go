@@ -0,0 +1,204 @@
+package ws
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/whereabouts/sdk/logger"
+	"golang.org/x/net/websocket"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrSlowConn the connection is closed because the messages broadcast to it are queued beyond the queue size
+// 由于广播给连接的消息排队超出队列大小, 连接被关闭
+var ErrSlowConn = errors.New("websocket connection is too slow")
+
+// Conn A websocket connection, it is safe to call the methods concurrently
+// 一个websocket连接, 可并发调用其方法
+type Conn struct {
+	id      string
+	ws      *websocket.Conn
+	ctx     context.Context
+	cancel  context.CancelFunc
+	hub     *Hub
+	timeout time.Duration
+	queue   chan []byte
+	mu      sync.Mutex
+	once    sync.Once
+	values  sync.Map
+	// rooms the rooms joined, guarded by the mutex of hub
+	rooms map[string]struct{}
+}
+
+func newConn(ctx context.Context, ws *websocket.Conn, hub *Hub, id string, conf config) *Conn {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Conn{
+		id:      id,
+		ws:      ws,
+		ctx:     ctx,
+		cancel:  cancel,
+		hub:     hub,
+		timeout: conf.writeTimeout,
+		queue:   make(chan []byte, conf.queueSize),
+		rooms:   make(map[string]struct{}),
+	}
+}
+
+// ID the unique id of the connection
+// 连接的唯一id
+func (c *Conn) ID() string {
+	return c.id
+}
+
+// Context the context canceled when the connection is closed, derived from the context of the upgrade request
+// 连接关闭时被取消的context, 派生自升级请求的context
+func (c *Conn) Context() context.Context {
+	return c.ctx
+}
+
+// Request the upgrade request
+// 升级请求
+func (c *Conn) Request() *http.Request {
+	return c.ws.Request()
+}
+
+// Set Store a value in the connection, such as the user authenticated
+// 在连接中保存一个值, 例如已鉴权的用户
+func (c *Conn) Set(key string, value interface{}) {
+	c.values.Store(key, value)
+}
+
+func (c *Conn) Get(key string) (interface{}, bool) {
+	return c.values.Load(key)
+}
+
+// Send Send v encoded as JSON
+// 发送编码为JSON的v
+func (c *Conn) Send(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return errors.Wrap(err, "encode websocket message err")
+	}
+	return c.write(websocket.TextFrame, data)
+}
+
+// Join Join the rooms of hub, the connection leaves them when closed
+// 加入hub的房间, 连接关闭时离开
+func (c *Conn) Join(rooms ...string) {
+	c.hub.join(c, rooms...)
+}
+
+func (c *Conn) Leave(rooms ...string) {
+	c.hub.leave(c, rooms...)
+}
+
+// Close Close the connection, only the first call takes effect
+// 关闭连接, 只有第一次调用生效
+func (c *Conn) Close() error {
+	var err error
+	c.once.Do(func() {
+		c.cancel()
+		err = c.ws.Close()
+	})
+	return err
+}
+
+func (c *Conn) write(frameType byte, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.ctx.Err(); err != nil {
+		return errors.Wrap(err, "websocket connection closed")
+	}
+	if c.timeout > 0 {
+		_ = c.ws.SetWriteDeadline(time.Now().Add(c.timeout))
+	}
+	c.ws.PayloadType = frameType
+	_, err := c.ws.Write(data)
+	return err
+}
+
+// enqueue Queue the message broadcast, the connection is closed if the queue is full
+func (c *Conn) enqueue(data []byte) {
+	select {
+	case c.queue <- data:
+	default:
+		logger.WithContext(c.ctx).Warnf("websocket connection %s closed: %v", c.id, ErrSlowConn)
+		_ = c.Close()
+	}
+}
+
+// pump Write the queued messages and the pings until the connection is closed
+func (c *Conn) pump(pingInterval time.Duration) {
+	var tick <-chan time.Time
+	if pingInterval > 0 {
+		ticker := time.NewTicker(pingInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		var err error
+		select {
+		case data := <-c.queue:
+			err = c.write(websocket.TextFrame, data)
+		case <-tick:
+			err = c.write(websocket.PingFrame, nil)
+		case <-c.ctx.Done():
+			return
+		}
+		if err != nil {
+			_ = c.Close()
+			return
+		}
+	}
+}
+
+// hijacker Wrap the hijacked connection to extend the read deadline whenever data, including the pong, is received,
+// since the control frames are consumed inside golang.org/x/net/websocket
+type hijacker struct {
+	http.ResponseWriter
+	timeout time.Duration
+}
+
+func (h hijacker) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := h.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijack")
+	}
+	conn, buf, err := hj.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	alive := &aliveConn{Conn: conn, timeout: h.timeout}
+	alive.extend()
+	reader := io.Reader(alive)
+	if n := buf.Reader.Buffered(); n > 0 {
+		buffered, _ := buf.Reader.Peek(n)
+		reader = io.MultiReader(bytes.NewReader(append([]byte(nil), buffered...)), alive)
+	}
+	return alive, bufio.NewReadWriter(bufio.NewReader(reader), buf.Writer), nil
+}
+
+type aliveConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+func (c *aliveConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if n > 0 {
+		c.extend()
+	}
+	return n, err
+}
+
+func (c *aliveConn) extend() {
+	if c.timeout > 0 {
+		_ = c.Conn.SetReadDeadline(time.Now().Add(c.timeout))
+	}
+}
@@ -0,0 +1,234 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/whereabouts/sdk/logger"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	minResubscribeInterval = 100 * time.Millisecond
+	maxResubscribeInterval = 5 * time.Second
+)
+
+type hubConfig struct {
+	broker Broker
+}
+
+type HubOption func(config *hubConfig)
+
+// WithBroker fan out the broadcasts across the instances by the broker, such as RedisBroker
+// 通过broker在多个实例间分发广播, 例如RedisBroker
+func WithBroker(broker Broker) HubOption {
+	return func(config *hubConfig) {
+		config.broker = broker
+	}
+}
+
+// Hub The connections served with it grouped by rooms, the messages broadcast are queued to the connections,
+// so a slow connection does not block the others
+// 以房间分组的由其服务的连接, 广播的消息在连接的队列中排队, 因此慢连接不会阻塞其他连接
+type Hub struct {
+	mu     sync.RWMutex
+	conns  map[*Conn]struct{}
+	rooms  map[string]map[*Conn]struct{}
+	closed bool
+	active sync.WaitGroup
+	broker Broker
+	// subscribed 1 if the subscription of broker is confirmed
+	subscribed int32
+	cancel     context.CancelFunc
+	done       chan struct{}
+}
+
+func NewHub(options ...HubOption) *Hub {
+	config := hubConfig{}
+	for _, option := range options {
+		option(&config)
+	}
+	h := &Hub{
+		conns:  make(map[*Conn]struct{}),
+		rooms:  make(map[string]map[*Conn]struct{}),
+		broker: config.broker,
+		done:   make(chan struct{}),
+	}
+	if h.broker == nil {
+		close(h.done)
+		return h
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	h.cancel = cancel
+	go h.subscribe(ctx)
+	return h
+}
+
+// subscribe Subscribe the broker until ctx is done, the failed subscription is retried with backoff,
+// which is reset once a subscription is confirmed
+func (h *Hub) subscribe(ctx context.Context) {
+	defer close(h.done)
+	interval := minResubscribeInterval
+	for {
+		err := h.broker.Subscribe(ctx, func() {
+			atomic.StoreInt32(&h.subscribed, 1)
+		}, h.deliver)
+		if atomic.SwapInt32(&h.subscribed, 0) == 1 {
+			interval = minResubscribeInterval
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		logger.Errorf("websocket hub subscribe err: %v, retry in %v", err, interval)
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return
+		}
+		if interval *= 2; interval > maxResubscribeInterval {
+			interval = maxResubscribeInterval
+		}
+	}
+}
+
+// Broadcast Send v encoded as JSON to the connections in the room, or all the connections if room is empty.
+// With a broker, it is published to the connections of all the instances, and it is also delivered to
+// the connections of this instance directly while the subscription of broker is not confirmed
+// 将编码为JSON的v发送给房间内的连接, room为空时发送给所有连接. 设置broker时发布给所有实例的连接,
+// 在broker的订阅未确认期间, 同时直接投递给本实例的连接
+func (h *Hub) Broadcast(ctx context.Context, room string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return errors.Wrap(err, "encode websocket message err")
+	}
+	if h.broker != nil {
+		if atomic.LoadInt32(&h.subscribed) == 0 {
+			h.deliver(room, data)
+		}
+		return h.broker.Publish(ctx, room, data)
+	}
+	h.deliver(room, data)
+	return nil
+}
+
+// Count the count of the connections in the room, or all the connections if room is empty
+// 房间内的连接数, room为空时为所有连接数
+func (h *Hub) Count(room string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if room == "" {
+		return len(h.conns)
+	}
+	return len(h.rooms[room])
+}
+
+// Close Stop accepting connections, close the connections and wait their handlers to return until ctx is done,
+// it is called by the server closing if the hub is the one of server
+// 停止接收连接, 关闭所有连接并等待其handler返回, 直到ctx结束. 若为server的hub, 则在server关闭时调用
+func (h *Hub) Close(ctx context.Context) error {
+	h.mu.Lock()
+	h.closed = true
+	conns := make([]*Conn, 0, len(h.conns))
+	for conn := range h.conns {
+		conns = append(conns, conn)
+	}
+	h.mu.Unlock()
+	if h.cancel != nil {
+		h.cancel()
+	}
+	for _, conn := range conns {
+		_ = conn.Close()
+	}
+	drained := make(chan struct{})
+	go func() {
+		h.active.Wait()
+		<-h.done
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrapf(ctx.Err(), "websocket hub close with %d connections", h.Count(""))
+	}
+}
+
+func (h *Hub) deliver(room string, data []byte) {
+	h.mu.RLock()
+	targets := h.conns
+	if room != "" {
+		targets = h.rooms[room]
+	}
+	conns := make([]*Conn, 0, len(targets))
+	for conn := range targets {
+		conns = append(conns, conn)
+	}
+	h.mu.RUnlock()
+	for _, conn := range conns {
+		conn.enqueue(data)
+	}
+}
+
+// add Track the connection, return false if the hub is closed
+func (h *Hub) add(conn *Conn) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return false
+	}
+	h.conns[conn] = struct{}{}
+	h.active.Add(1)
+	return true
+}
+
+func (h *Hub) remove(conn *Conn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.conns[conn]; !ok {
+		return
+	}
+	for room := range conn.rooms {
+		h.leaveLocked(conn, room)
+	}
+	delete(h.conns, conn)
+	h.active.Done()
+}
+
+func (h *Hub) join(conn *Conn, rooms ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.conns[conn]; !ok {
+		return
+	}
+	for _, room := range rooms {
+		// the empty room means all the connections
+		if room == "" {
+			continue
+		}
+		if h.rooms[room] == nil {
+			h.rooms[room] = make(map[*Conn]struct{})
+		}
+		h.rooms[room][conn] = struct{}{}
+		conn.rooms[room] = struct{}{}
+	}
+}
+
+func (h *Hub) leave(conn *Conn, rooms ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, room := range rooms {
+		h.leaveLocked(conn, room)
+	}
+}
+
+func (h *Hub) leaveLocked(conn *Conn, room string) {
+	delete(conn.rooms, room)
+	if members, ok := h.rooms[room]; ok {
+		delete(members, conn)
+		if len(members) == 0 {
+			delete(h.rooms, room)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/pkg/errors"
+	"github.com/whereabouts/sdk/httpserver/handler"
+	"github.com/whereabouts/sdk/logger"
+	"github.com/whereabouts/sdk/trace"
+	"golang.org/x/net/websocket"
+	"net/http"
+)
+
+// Handle Create a handler func upgrading the request to a websocket connection served with hub.
+// Every text or binary message received is decoded as JSON into a new Msg and validated, then passed to method
+// in order, the errors of decoding and method are sent back rendered as the handler methods do.
+// The connection is kept alive by ping, and closed when the client is gone, Conn.Close is called or hub is closed
+// 创建将请求升级为由hub服务的websocket连接的handler func. 收到的每条文本或二进制消息按JSON解码为新的Msg并校验,
+// 然后依次传给方法, 解码与方法的错误以handler方法的方式渲染后发回. 连接通过ping保活,
+// 在客户端断开, 调用Conn.Close或hub关闭时关闭
+//
+// example:
+//
+//	func Chat(ctx context.Context, conn *ws.Conn, msg *proto.ChatMsg) error {
+//		if msg.Join != "" {
+//			conn.Join(msg.Join)
+//			return nil
+//		}
+//		return hub.Broadcast(ctx, msg.Room, msg)
+//	}
+//
+//	router.GET("/chat", ws.Handle(s.Hub(), Chat))
+func Handle[Msg any](hub *Hub, method func(ctx context.Context, conn *Conn, msg *Msg) error, options ...Option) gin.HandlerFunc {
+	conf := newConfig(options...)
+	return func(c *gin.Context) {
+		server := websocket.Server{
+			Handshake: func(config *websocket.Config, r *http.Request) error {
+				if !conf.checkOrigin(r) {
+					return errors.Errorf("websocket origin %s is not allowed", r.Header.Get("Origin"))
+				}
+				return nil
+			},
+			Handler: func(ws *websocket.Conn) {
+				serve(c, hub, conf, ws, method)
+			},
+		}
+		server.ServeHTTP(hijacker{ResponseWriter: c.Writer, timeout: conf.pongTimeout}, c.Request)
+		c.Abort()
+	}
+}
+
+func serve[Msg any](c *gin.Context, hub *Hub, conf config, ws *websocket.Conn, method func(ctx context.Context, conn *Conn, msg *Msg) error) {
+	ws.MaxPayloadBytes = conf.maxMessageSize
+	conn := newConn(c.Request.Context(), ws, hub, trace.NewRequestID(), conf)
+	defer conn.Close()
+	if !hub.add(conn) {
+		return
+	}
+	defer hub.remove(conn)
+	l := logger.WithContext(conn.Context())
+	if conf.onConnect != nil {
+		if err := conf.onConnect(conn); err != nil {
+			_ = conn.Send(handler.Failure(c, err))
+			return
+		}
+	}
+	if conf.onClose != nil {
+		defer conf.onClose(conn)
+	}
+	go conn.pump(conf.pingInterval)
+
+	for {
+		var data []byte
+		if err := websocket.Message.Receive(ws, &data); err != nil {
+			if conn.Context().Err() == nil {
+				l.Debugf("websocket connection %s closed: %v", conn.ID(), err)
+			}
+			return
+		}
+		msg := new(Msg)
+		if err := decode(data, msg); err != nil {
+			_ = conn.Send(handler.BindFailure(c, err))
+			l.Errorf("websocket(%T) failed to bind: %v", msg, err)
+			continue
+		}
+		if err := method(conn.Context(), conn, msg); err != nil {
+			_ = conn.Send(handler.Failure(c, err))
+		}
+	}
+}
+
+// decode Decode the JSON message into msg and validate it as handler.Bind does
+func decode(data []byte, msg interface{}) error {
+	if err := json.Unmarshal(data, msg); err != nil {
+		return errors.Wrap(err, "decode websocket message err")
+	}
+	if binding.Validator == nil {
+		return nil
+	}
+	return binding.Validator.ValidateStruct(msg)
+}
@@ -0,0 +1,255 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/whereabouts/sdk/db/redisc"
+	"golang.org/x/net/websocket"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type chatMsg struct {
+	Room string `json:"room"`
+	Text string `json:"text" binding:"required"`
+}
+
+func newChatServer(t *testing.T, hub *Hub, options ...Option) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/chat", Handle(hub, func(ctx context.Context, conn *Conn, msg *chatMsg) error {
+		switch {
+		case msg.Text == "join":
+			conn.Join(msg.Room)
+			return conn.Send(map[string]string{"joined": msg.Room})
+		case msg.Text == "fail":
+			return errors.New("chat failed")
+		case msg.Room != "":
+			return hub.Broadcast(ctx, msg.Room, msg)
+		default:
+			return conn.Send(msg)
+		}
+	}, options...))
+	server := httptest.NewServer(engine)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func dial(t *testing.T, server *httptest.Server) *websocket.Conn {
+	conn, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/chat", "", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return conn
+}
+
+func receive(t *testing.T, conn *websocket.Conn) string {
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	var data string
+	if err := websocket.Message.Receive(conn, &data); err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func waitCount(t *testing.T, hub *Hub, room string, count int) {
+	for i := 0; i < 100; i++ {
+		if hub.Count(room) == count {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("expect %d connections in room %q, got %d", count, room, hub.Count(room))
+}
+
+func TestHandle(t *testing.T) {
+	hub := NewHub()
+	server := newChatServer(t, hub)
+	conn := dial(t, server)
+
+	_ = websocket.JSON.Send(conn, chatMsg{Text: "hello"})
+	if data := receive(t, conn); data != `{"room":"","text":"hello"}` {
+		t.Fatalf("unexpected echo %s", data)
+	}
+	_ = websocket.Message.Send(conn, `{"room":"a"}`)
+	if data := receive(t, conn); !strings.Contains(data, `"code":false`) || !strings.Contains(data, "text") {
+		t.Fatalf("expect the validation failure, got %s", data)
+	}
+	_ = websocket.Message.Send(conn, `not json`)
+	if data := receive(t, conn); !strings.Contains(data, `"code":false`) {
+		t.Fatalf("expect the decode failure, got %s", data)
+	}
+	_ = websocket.JSON.Send(conn, chatMsg{Text: "fail"})
+	if data := receive(t, conn); !strings.Contains(data, `"message":"chat failed"`) {
+		t.Fatalf("expect the method failure, got %s", data)
+	}
+
+	// the origin of other sites is rejected
+	if _, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/chat", "", "http://evil.example"); err == nil {
+		t.Fatal("expect the cross origin request rejected")
+	}
+}
+
+func TestHubRooms(t *testing.T) {
+	hub := NewHub()
+	server := newChatServer(t, hub)
+	alice, bob, carol := dial(t, server), dial(t, server), dial(t, server)
+	for _, conn := range []*websocket.Conn{alice, bob} {
+		_ = websocket.JSON.Send(conn, chatMsg{Room: "golang", Text: "join"})
+		if data := receive(t, conn); data != `{"joined":"golang"}` {
+			t.Fatalf("unexpected join response %s", data)
+		}
+	}
+	waitCount(t, hub, "", 3)
+	if hub.Count("golang") != 2 {
+		t.Fatalf("expect 2 connections in room, got %d", hub.Count("golang"))
+	}
+
+	_ = websocket.JSON.Send(carol, chatMsg{Room: "golang", Text: "hi"})
+	for _, conn := range []*websocket.Conn{alice, bob} {
+		if data := receive(t, conn); data != `{"room":"golang","text":"hi"}` {
+			t.Fatalf("unexpected broadcast %s", data)
+		}
+	}
+	_ = hub.Broadcast(context.Background(), "", map[string]string{"notice": "all"})
+	for _, conn := range []*websocket.Conn{alice, bob, carol} {
+		if data := receive(t, conn); data != `{"notice":"all"}` {
+			t.Fatalf("unexpected broadcast to all %s", data)
+		}
+	}
+
+	// the connection leaves the rooms when closed
+	_ = alice.Close()
+	waitCount(t, hub, "golang", 1)
+	waitCount(t, hub, "", 2)
+}
+
+func TestKeepalive(t *testing.T) {
+	hub := NewHub()
+	server := newChatServer(t, hub, WithPingInterval(10*time.Millisecond), WithPongTimeout(50*time.Millisecond))
+	idle, reading := dial(t, server), dial(t, server)
+	waitCount(t, hub, "", 2)
+
+	// the reading client answers the pings, the idle one does not read, so no pong is sent
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var data string
+		_ = reading.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+		_ = websocket.Message.Receive(reading, &data)
+	}()
+	waitCount(t, hub, "", 1)
+	<-done
+	_ = idle.Close()
+	// the reading client has been alive for longer than the pong timeout
+	if hub.Count("") != 1 {
+		t.Fatalf("expect the reading client alive, got %d connections", hub.Count(""))
+	}
+}
+
+func TestHubClose(t *testing.T) {
+	hub := NewHub()
+	server := newChatServer(t, hub)
+	conn := dial(t, server)
+	waitCount(t, hub, "", 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := hub.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if hub.Count("") != 0 {
+		t.Fatalf("expect the connections drained, got %d", hub.Count(""))
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	var data string
+	if err := websocket.Message.Receive(conn, &data); err == nil {
+		t.Fatal("expect the connection closed")
+	}
+	// the hub closed does not accept connections
+	conn = dial(t, server)
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	if err := websocket.Message.Receive(conn, &data); err == nil {
+		t.Fatal("expect the connection refused by the closed hub")
+	}
+}
+
+func TestRedisBroker(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client, err := redisc.NewClientWithOptions(context.Background(), redisc.WithAddrs(mr.Addr()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	// two instances sharing the channel
+	hubA := NewHub(WithBroker(NewRedisBroker(client, "chat")))
+	hubB := NewHub(WithBroker(NewRedisBroker(client, "chat")))
+	defer hubA.Close(context.Background())
+	defer hubB.Close(context.Background())
+	connA, connB := dial(t, newChatServer(t, hubA)), dial(t, newChatServer(t, hubB))
+	_ = websocket.JSON.Send(connB, chatMsg{Room: "golang", Text: "join"})
+	receive(t, connB)
+	for i := 0; i < 100 && mr.PubSubNumSub("chat")["chat"] < 2; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	_ = websocket.JSON.Send(connA, chatMsg{Room: "golang", Text: "across"})
+	var msg chatMsg
+	if err = json.Unmarshal([]byte(receive(t, connB)), &msg); err != nil || msg.Text != "across" {
+		t.Fatalf("expect the message fanned out, got %+v, err: %v", msg, err)
+	}
+}
+
+func TestRedisBrokerResubscribe(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client, err := redisc.NewClientWithOptions(context.Background(), redisc.WithAddrs(mr.Addr()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	// the hub starts while redis is down
+	mr.Close()
+	hub := NewHub(WithBroker(NewRedisBroker(client, "chat")))
+	defer hub.Close(context.Background())
+	conn := dial(t, newChatServer(t, hub))
+	waitCount(t, hub, "", 1)
+
+	// the connections of this instance still get the broadcasts
+	if err = hub.Broadcast(context.Background(), "", map[string]string{"notice": "down"}); err == nil {
+		t.Fatal("expect the publish err while redis is down")
+	}
+	if data := receive(t, conn); data != `{"notice":"down"}` {
+		t.Fatalf("unexpected broadcast while redis is down %s", data)
+	}
+
+	if err = mr.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 300 && atomic.LoadInt32(&hub.subscribed) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if atomic.LoadInt32(&hub.subscribed) == 0 || mr.PubSubNumSub("chat")["chat"] != 1 {
+		t.Fatal("expect the hub subscribed again")
+	}
+	if err = hub.Broadcast(context.Background(), "", map[string]string{"notice": "up"}); err != nil {
+		t.Fatal(err)
+	}
+	if data := receive(t, conn); data != `{"notice":"up"}` {
+		t.Fatalf("unexpected broadcast after redis is up %s", data)
+	}
+	// delivered once by the subscription only
+	_ = conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	var data string
+	if err = websocket.Message.Receive(conn, &data); err == nil {
+		t.Fatalf("expect the broadcast delivered once, got %s again", data)
+	}
+}
@@ -0,0 +1,16 @@
+package jwt
+
+import "context"
+
+type payloadKey struct{}
+
+func WithPayload(ctx context.Context, payload *Payload) context.Context {
+	return context.WithValue(ctx, payloadKey{}, payload)
+}
+
+// PayloadFrom Get the payload stored by WithPayload, such as the one of the token verified by middleware.JWTAuth
+// 获取由WithPayload存入的payload, 例如由middleware.JWTAuth校验的token的payload
+func PayloadFrom(ctx context.Context) (*Payload, bool) {
+	payload, ok := ctx.Value(payloadKey{}).(*Payload)
+	return payload, ok && payload != nil
+}
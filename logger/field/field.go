@@ -14,6 +14,8 @@ const (
 	KeySource    = "source"
 	KeyHost      = "host"
 	KeyTrace     = "trace"
+	KeySpan      = "span"
+	KeyRequestID = "request_id"
 
 	ValueZero = iota
 )
@@ -21,6 +21,8 @@ var standardKeys = map[string]bool{
 	field.KeyFile:      true, // file
 	field.KeySource:    true, // source
 	field.KeyTrace:     true, // trace
+	field.KeySpan:      true, // span
+	field.KeyRequestID: true, // request_id
 	field.KeyHost:      true, // host
 }
 
@@ -0,0 +1,47 @@
+package hook
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/whereabouts/sdk/logger/field"
+	"github.com/whereabouts/sdk/logger/level"
+	"github.com/whereabouts/sdk/trace"
+)
+
+type traceHook struct {
+	levels []level.Level
+}
+
+// NewTraceHook Use to create the traceHook
+// StandardLogger adds this Hook by default, which adds the trace id, span id and request id
+// in the context of entry set by WithContext
+// StandardLogger默认添加了该Hook, 用于添加由WithContext设置的context中的trace id, span id与request id
+func NewTraceHook() *traceHook {
+	return &traceHook{
+		levels: level.AllLevels,
+	}
+}
+
+// Levels implement levels
+func (hook *traceHook) Levels() []level.Level {
+	return hook.levels
+}
+
+// Fire implement fire
+func (hook *traceHook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+	if sc := trace.SpanContextFrom(entry.Context); sc.IsValid() {
+		entry.Data[field.KeyTrace] = sc.TraceID.String()
+		entry.Data[field.KeySpan] = sc.SpanID.String()
+	}
+	if requestID := trace.RequestIDFrom(entry.Context); requestID != "" {
+		entry.Data[field.KeyRequestID] = requestID
+	}
+	return nil
+}
+
+func (hook *traceHook) SetLevels(levels []level.Level) *traceHook {
+	hook.levels = levels
+	return hook
+}
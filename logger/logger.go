@@ -63,7 +63,7 @@ func newStandardLogger() *Logger {
 		},
 	}).AddHook(hook.NewCallerHook().
 		SetSimplify(true),
-	)
+	).AddHook(hook.NewTraceHook())
 }
 
 func (logger *Logger) Kernel() *logrus.Logger {
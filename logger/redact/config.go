@@ -0,0 +1,77 @@
+package redact
+
+const (
+	// PatternPhone the mobile phone numbers of mainland China
+	// 中国大陆手机号
+	PatternPhone = `\b1[3-9]\d{9}\b`
+	// PatternIDCard the resident ID card numbers of mainland China
+	// 中国大陆居民身份证号
+	PatternIDCard = `\b\d{17}[\dXx]\b`
+
+	defaultMask = "******"
+)
+
+type Config struct {
+	// Headers the names of headers to mask, case-insensitive
+	// 需要脱敏的请求头名称, 不区分大小写
+	Headers []string `mapstructure:"headers" json:"headers"`
+	// Fields the JSON paths of body fields and the names of query params to mask, case-insensitive.
+	// A name without dot matches the field at any depth, such as "password",
+	// a path with dots matches from the root, "*" matches any field and the arrays are passed through,
+	// such as "user.phone", "*.code" or "items.card_no" for the field of the elements of array "items"
+	// 需要脱敏的body字段的JSON路径与query参数名, 不区分大小写. 不含"."的名称匹配任意层级的字段, 例如"password",
+	// 含"."的路径从根开始匹配, "*"匹配任意字段, 数组被直接穿过, 例如"user.phone", "*.code",
+	// 或数组"items"中元素的字段"items.card_no"
+	Fields []string `mapstructure:"fields" json:"fields"`
+	// Patterns the regular expressions of values to mask anywhere, such as PatternPhone and PatternIDCard
+	// 在任意位置需要脱敏的值的正则表达式, 例如PatternPhone与PatternIDCard
+	Patterns []string `mapstructure:"patterns" json:"patterns"`
+	// Mask the replacement of the masked values, default "******"
+	// 脱敏后的替换值, 默认"******"
+	Mask string `mapstructure:"mask" json:"mask"`
+}
+
+// DefaultConfig mask the credentials in headers, the password, token and secret fields, the phone and ID card numbers
+// 脱敏请求头中的凭证, password, token, secret字段, 以及手机号与身份证号
+func DefaultConfig() Config {
+	return Config{
+		Headers:  []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"},
+		Fields:   []string{"password", "passwd", "token", "access_token", "refresh_token", "secret"},
+		Patterns: []string{PatternPhone, PatternIDCard},
+		Mask:     defaultMask,
+	}
+}
+
+type Option func(config *Config)
+
+func newConfig(options ...Option) Config {
+	config := Config{Mask: defaultMask}
+	for _, option := range options {
+		option(&config)
+	}
+	return config
+}
+
+func WithHeaders(headers ...string) Option {
+	return func(config *Config) {
+		config.Headers = append(config.Headers, headers...)
+	}
+}
+
+func WithFields(fields ...string) Option {
+	return func(config *Config) {
+		config.Fields = append(config.Fields, fields...)
+	}
+}
+
+func WithPatterns(patterns ...string) Option {
+	return func(config *Config) {
+		config.Patterns = append(config.Patterns, patterns...)
+	}
+}
+
+func WithMask(mask string) Option {
+	return func(config *Config) {
+		config.Mask = mask
+	}
+}
@@ -0,0 +1,191 @@
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Default the redactor of DefaultConfig, used by the logging of httpserver and httpc by default
+// DefaultConfig的redactor, httpserver与httpc的日志默认使用
+var Default = mustNew(DefaultConfig())
+
+// Redactor Mask the sensitive headers, fields and values before they are logged, it is safe for concurrent use
+// 在记录日志前对敏感的请求头, 字段与值脱敏, 可并发使用
+type Redactor struct {
+	mask     string
+	headers  map[string]struct{}
+	names    map[string]struct{}
+	paths    [][]string
+	patterns []*regexp.Regexp
+	// nameField matches the fields of names in the JSON which can not be parsed, such as a truncated one
+	nameField *regexp.Regexp
+}
+
+func New(options ...Option) (*Redactor, error) {
+	return NewWithConfig(newConfig(options...))
+}
+
+func NewWithConfig(config Config) (*Redactor, error) {
+	r := &Redactor{
+		mask:    config.Mask,
+		headers: make(map[string]struct{}, len(config.Headers)),
+		names:   make(map[string]struct{}),
+	}
+	if r.mask == "" {
+		r.mask = defaultMask
+	}
+	for _, header := range config.Headers {
+		r.headers[http.CanonicalHeaderKey(header)] = struct{}{}
+	}
+	var names []string
+	for _, field := range config.Fields {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if field == "" {
+			continue
+		}
+		if !strings.Contains(field, ".") {
+			r.names[field] = struct{}{}
+			names = append(names, regexp.QuoteMeta(field))
+			continue
+		}
+		r.paths = append(r.paths, strings.Split(field, "."))
+	}
+	for _, pattern := range config.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, errors.Wrapf(err, "compile redact pattern %s err", pattern)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	if len(names) > 0 {
+		r.nameField = regexp.MustCompile(`(?i)("(?:` + strings.Join(names, "|") + `)"\s*:\s*)("(?:[^"\\]|\\.)*"?|[^,}\]\s]+)`)
+	}
+	return r, nil
+}
+
+func mustNew(config Config) *Redactor {
+	r, err := NewWithConfig(config)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Headers Return a copy of headers with the sensitive ones masked
+// 返回敏感请求头已脱敏的headers副本
+func (r *Redactor) Headers(headers http.Header) http.Header {
+	redacted := make(http.Header, len(headers))
+	for key, values := range headers {
+		masked := make([]string, len(values))
+		for i, value := range values {
+			if _, ok := r.headers[http.CanonicalHeaderKey(key)]; ok {
+				masked[i] = r.mask
+			} else {
+				masked[i] = r.String(value)
+			}
+		}
+		redacted[key] = masked
+	}
+	return redacted
+}
+
+// URL Return the request uri of u with the sensitive query params masked
+// 返回敏感query参数已脱敏的u的请求uri
+func (r *Redactor) URL(u *url.URL) string {
+	if u.RawQuery == "" {
+		return r.String(u.RequestURI())
+	}
+	pairs := strings.Split(u.RawQuery, "&")
+	for i, pair := range pairs {
+		rawKey := strings.SplitN(pair, "=", 2)[0]
+		key, err := url.QueryUnescape(rawKey)
+		if err != nil {
+			key = rawKey
+		}
+		if _, ok := r.names[strings.ToLower(key)]; ok {
+			pairs[i] = rawKey + "=" + r.mask
+		}
+	}
+	return r.String(u.EscapedPath() + "?" + strings.Join(pairs, "&"))
+}
+
+// Body Mask the sensitive fields of JSON body and the values matching patterns
+// 对JSON body的敏感字段与匹配正则的值脱敏
+func (r *Redactor) Body(body []byte) string {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') || (len(r.names) == 0 && len(r.paths) == 0) {
+		return r.String(string(body))
+	}
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		s := string(body)
+		if r.nameField != nil {
+			s = r.nameField.ReplaceAllString(s, `${1}"`+r.mask+`"`)
+		}
+		return r.String(s)
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(r.value(v, nil)); err != nil {
+		return r.String(string(body))
+	}
+	return r.String(strings.TrimSuffix(buf.String(), "\n"))
+}
+
+// String Mask the values matching patterns in s
+// 对s中匹配正则的值脱敏
+func (r *Redactor) String(s string) string {
+	for _, pattern := range r.patterns {
+		s = pattern.ReplaceAllLiteralString(s, r.mask)
+	}
+	return s
+}
+
+func (r *Redactor) value(v interface{}, path []string) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for key, child := range val {
+			childPath := append(path[:len(path):len(path)], strings.ToLower(key))
+			if r.match(childPath) {
+				val[key] = r.mask
+				continue
+			}
+			val[key] = r.value(child, childPath)
+		}
+	case []interface{}:
+		for i, child := range val {
+			val[i] = r.value(child, path)
+		}
+	}
+	return v
+}
+
+func (r *Redactor) match(path []string) bool {
+	if _, ok := r.names[path[len(path)-1]]; ok {
+		return true
+	}
+	for _, p := range r.paths {
+		if len(p) != len(path) {
+			continue
+		}
+		matched := true
+		for i := range p {
+			if p[i] != "*" && p[i] != path[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package redact
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestBody(t *testing.T) {
+	r, err := New(WithFields("password", "user.phone", "items.card", "*.code"), WithPatterns(PatternIDCard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		body, expect string
+	}{
+		{
+			`{"name":"<a>","Password":"123","user":{"phone":"13812345678","age":18},"phone":"1"}`,
+			`{"Password":"******","name":"<a>","phone":"1","user":{"age":18,"phone":"******"}}`,
+		},
+		{
+			`{"items":[{"card":"6222","n":1},{"card":"6223"}],"nested":{"password":1.50},"sms":{"code":"1234"},"code":0}`,
+			`{"code":0,"items":[{"card":"******","n":1},{"card":"******"}],"nested":{"password":"******"},"sms":{"code":"******"}}`,
+		},
+		{`{"password": "123", "id_card": "11010119900307123X", "remark": "trunc`, `{"password": "******", "id_card": "******", "remark": "trunc`},
+		{`id=11010119900307123X`, `id=******`},
+	}
+	for i, cs := range cases {
+		if got := r.Body([]byte(cs.body)); got != cs.expect {
+			t.Errorf("#%d expect %s, got %s", i, cs.expect, got)
+		}
+	}
+}
+
+func TestHeadersAndURL(t *testing.T) {
+	headers := Default.Headers(http.Header{
+		"Authorization": {"Bearer xxx"},
+		"X-Phone":       {"13812345678"},
+		"Accept":        {"*/*"},
+	})
+	if headers.Get("Authorization") != defaultMask || headers.Get("X-Phone") != defaultMask || headers.Get("Accept") != "*/*" {
+		t.Fatalf("unexpected headers %v", headers)
+	}
+	u, _ := url.Parse("http://example.com/login?token=abc&name=tom")
+	if got := Default.URL(u); got != "/login?token=******&name=tom" {
+		t.Fatalf("unexpected url %s", got)
+	}
+}
+
+func TestInvalidPattern(t *testing.T) {
+	if _, err := New(WithPatterns("(")); err == nil {
+		t.Fatal("expect compile err")
+	}
+}
@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// TokenBucket An in-process limiter, every key has a bucket of limit tokens refilled evenly in period,
+// an event takes one token, so the bursts up to limit are allowed
+// 进程内的限流器, 每个key有一个容量为limit的令牌桶, 在period内匀速补满, 每次事件取走一个令牌, 因此允许最多limit的突发
+type TokenBucket struct {
+	limit     int
+	period    time.Duration
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket the limit and the period must be positive
+// limit与period必须为正数
+func NewTokenBucket(limit int, period time.Duration) (*TokenBucket, error) {
+	if limit <= 0 {
+		return nil, errors.Errorf("the limit %d of token bucket must be positive", limit)
+	}
+	if period <= 0 {
+		return nil, errors.Errorf("the period %s of token bucket must be positive", period)
+	}
+	return &TokenBucket{
+		limit:   limit,
+		period:  period,
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}, nil
+}
+
+func (tb *TokenBucket) Allow(_ context.Context, key string) (Result, error) {
+	now := tb.now()
+	rate := float64(tb.limit) / float64(tb.period) // tokens per nanosecond
+	res := Result{Limit: tb.limit}
+
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.sweep(now, rate)
+	b, ok := tb.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(tb.limit), last: now}
+		tb.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(tb.limit), b.tokens+float64(now.Sub(b.last))*rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = time.Duration(math.Ceil((1 - b.tokens) / rate))
+	}
+	res.Remaining = int(b.tokens)
+	res.ResetAfter = time.Duration(math.Ceil((float64(tb.limit) - b.tokens) / rate))
+	return res, nil
+}
+
+// sweep Drop the buckets which are full again at most once a period, so that the idle keys do not pile up
+func (tb *TokenBucket) sweep(now time.Time, rate float64) {
+	if now.Sub(tb.lastSweep) < tb.period {
+		return
+	}
+	tb.lastSweep = now
+	for key, b := range tb.buckets {
+		if b.tokens+float64(now.Sub(b.last))*rate >= float64(tb.limit) {
+			delete(tb.buckets, key)
+		}
+	}
+}
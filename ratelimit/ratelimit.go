@@ -0,0 +1,30 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limiter Limit the count of events of a key, such as the requests of an ip or the messages sent to a phone number
+// 限制某个key的事件数, 例如一个ip的请求或发送到一个手机号的短信
+type Limiter interface {
+	// Allow Take one event of key, the event is not counted if it is not allowed
+	// 记录key的一次事件, 不被允许的事件不计数
+	Allow(ctx context.Context, key string) (Result, error)
+}
+
+type Result struct {
+	Allowed bool
+	// Limit the max count of events in a period
+	// 一个周期内的最大事件数
+	Limit int
+	// Remaining the count of events still allowed now
+	// 当前仍允许的事件数
+	Remaining int
+	// RetryAfter the duration to wait before the next event is allowed, zero if allowed
+	// 下一次事件被允许前需等待的时间, 被允许时为0
+	RetryAfter time.Duration
+	// ResetAfter the duration until the limit is fully restored
+	// 限额完全恢复前的时间
+	ResetAfter time.Duration
+}
@@ -0,0 +1,96 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/whereabouts/sdk/db/redisc"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Unix(0, 0)
+	if _, err := NewTokenBucket(2, 0); err == nil {
+		t.Fatal("expect err of zero period, got nil")
+	}
+	if _, err := NewTokenBucket(0, time.Second); err == nil {
+		t.Fatal("expect err of zero limit, got nil")
+	}
+	tb, err := NewTokenBucket(2, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tb.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for i, expect := range []Result{
+		{Allowed: true, Limit: 2, Remaining: 1, ResetAfter: 500 * time.Millisecond},
+		{Allowed: true, Limit: 2, Remaining: 0, ResetAfter: time.Second},
+		{Allowed: false, Limit: 2, Remaining: 0, RetryAfter: 500 * time.Millisecond, ResetAfter: time.Second},
+	} {
+		if res, _ := tb.Allow(ctx, "13800000000"); res != expect {
+			t.Fatalf("#%d expect %+v, got %+v", i, expect, res)
+		}
+	}
+	if res, _ := tb.Allow(ctx, "13900000000"); !res.Allowed {
+		t.Fatal("expect the other key allowed")
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if res, _ := tb.Allow(ctx, "13800000000"); !res.Allowed || res.Remaining != 0 {
+		t.Fatalf("expect one token refilled, got %+v", res)
+	}
+	now = now.Add(10 * time.Second)
+	if res, _ := tb.Allow(ctx, "13800000000"); !res.Allowed || res.Remaining != 1 {
+		t.Fatalf("expect the bucket refilled to limit, got %+v", res)
+	}
+	if len(tb.buckets) != 1 {
+		t.Fatalf("expect the idle bucket swept, got %d buckets", len(tb.buckets))
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client, err := redisc.NewClientWithOptions(context.Background(), redisc.WithAddrs(mr.Addr()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = NewSlidingWindow(client, 0, time.Minute); err == nil {
+		t.Fatal("expect err of zero limit, got nil")
+	}
+	redisNow := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+	mr.SetTime(redisNow)
+	sw, err := NewSlidingWindow(client, 2, time.Minute, WithPrefix("sms:"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		res, err := sw.Allow(ctx, "13800000000")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.Allowed || res.Remaining != 1-i || res.ResetAfter <= 0 || res.ResetAfter > time.Minute {
+			t.Fatalf("#%d unexpected %+v", i, res)
+		}
+	}
+	res, err := sw.Allow(ctx, "13800000000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Allowed || res.Remaining != 0 || res.RetryAfter <= 0 || res.RetryAfter > time.Minute {
+		t.Fatalf("expect denied, got %+v", res)
+	}
+	if count, _ := client.ZCard(ctx, "sms:13800000000").Result(); count != 2 {
+		t.Fatalf("expect the denied event not counted, got %d", count)
+	}
+	// the events are scored by the clock of redis
+	if scores, _ := client.ZRangeWithScores(ctx, "sms:13800000000", 0, 0).Result(); len(scores) != 1 ||
+		int64(scores[0].Score) != redisNow.UnixNano()/int64(time.Millisecond) {
+		t.Fatalf("expect the events scored by the redis time %v, got %+v", redisNow, scores)
+	}
+	if res, _ = sw.Allow(ctx, "13900000000"); !res.Allowed {
+		t.Fatal("expect the other key allowed")
+	}
+}
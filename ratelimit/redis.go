@@ -0,0 +1,89 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+	"github.com/whereabouts/sdk/db/redisc"
+	"math/rand"
+	"time"
+)
+
+const defaultPrefix = "ratelimit:"
+
+// slidingWindowScript Count the events in the window ending now by a sorted set scored by milliseconds,
+// the time of redis is used, so that the instances with clock skew share the same window,
+// returns {allowed, count, the score of the oldest event, the score of the newest event, now}
+var slidingWindowScript = redis.NewScript(`
+redis.replicate_commands()
+local key = KEYS[1]
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local window = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+local count = redis.call('ZCARD', key)
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', key, now, now .. '-' .. ARGV[3])
+	redis.call('PEXPIRE', key, window)
+	count = count + 1
+	allowed = 1
+end
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+local newest = redis.call('ZRANGE', key, -1, -1, 'WITHSCORES')
+return {allowed, count, tonumber(oldest[2]) or now, tonumber(newest[2]) or now, now}
+`)
+
+// SlidingWindow A distributed limiter on redis, at most limit events of a key are allowed in any window of period
+// 基于redis的分布式限流器, 任意period长度的窗口内一个key最多允许limit次事件
+type SlidingWindow struct {
+	client *redisc.Client
+	limit  int
+	period time.Duration
+	prefix string
+}
+
+type Option func(sw *SlidingWindow)
+
+// WithPrefix set the prefix of the redis keys, default "ratelimit:"
+// 设置redis key的前缀, 默认"ratelimit:"
+func WithPrefix(prefix string) Option {
+	return func(sw *SlidingWindow) {
+		sw.prefix = prefix
+	}
+}
+
+// NewSlidingWindow the limit must be positive and the period must be at least a millisecond
+// limit必须为正数, period至少为一毫秒
+func NewSlidingWindow(client *redisc.Client, limit int, period time.Duration, options ...Option) (*SlidingWindow, error) {
+	if limit <= 0 {
+		return nil, errors.Errorf("the limit %d of sliding window must be positive", limit)
+	}
+	if period < time.Millisecond {
+		return nil, errors.Errorf("the period %s of sliding window must be at least a millisecond", period)
+	}
+	sw := &SlidingWindow{client: client, limit: limit, period: period, prefix: defaultPrefix}
+	for _, option := range options {
+		option(sw)
+	}
+	return sw, nil
+}
+
+func (sw *SlidingWindow) Allow(ctx context.Context, key string) (Result, error) {
+	window := sw.period.Milliseconds()
+	values, err := slidingWindowScript.Run(ctx, sw.client, []string{sw.prefix + key}, window, sw.limit, rand.Int63()).Int64Slice()
+	if err != nil {
+		return Result{}, errors.Wrap(err, "run sliding window script err")
+	}
+	if len(values) != 5 {
+		return Result{}, errors.Errorf("unexpected result of sliding window script: %v", values)
+	}
+	now := values[4]
+	res := Result{Allowed: values[0] == 1, Limit: sw.limit, Remaining: sw.limit - int(values[1])}
+	if !res.Allowed {
+		res.RetryAfter = time.Duration(values[2]+window-now) * time.Millisecond
+	}
+	res.ResetAfter = time.Duration(values[3]+window-now) * time.Millisecond
+	return res, nil
+}
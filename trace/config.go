@@ -0,0 +1,79 @@
+package trace
+
+import (
+	"log"
+)
+
+const (
+	defaultBatchSize    = 512
+	defaultQueueSize    = 2048
+	defaultBatchTimeout = 5
+	defaultTimeout      = 30
+)
+
+type Config struct {
+	// BatchSize the max count of spans exported at once, default 512
+	// 一次导出的最大span数, 默认512
+	BatchSize int `mapstructure:"batch_size" json:"batch_size"`
+	// QueueSize the max count of spans waiting to be exported, the spans ended when it is full are dropped, default 2048
+	// 等待导出的最大span数, 队列满时结束的span会被丢弃, 默认2048
+	QueueSize int `mapstructure:"queue_size" json:"queue_size"`
+	// BatchTimeout the seconds to wait before exporting a batch which is not full, default 5
+	// 导出未满的批次前等待的秒数, 默认5
+	BatchTimeout int `mapstructure:"batch_timeout" json:"batch_timeout"`
+	// Timeout the seconds to wait each export, default 30
+	// 每次导出的等待秒数, 默认30
+	Timeout      int `mapstructure:"timeout" json:"timeout"`
+	errorHandler func(err error)
+}
+
+type Option func(config *Config)
+
+func newConfig(options ...Option) Config {
+	config := Config{
+		BatchSize:    defaultBatchSize,
+		QueueSize:    defaultQueueSize,
+		BatchTimeout: defaultBatchTimeout,
+		Timeout:      defaultTimeout,
+		errorHandler: func(err error) {
+			log.Printf("trace: %v", err)
+		},
+	}
+	for _, option := range options {
+		option(&config)
+	}
+	return config
+}
+
+func WithBatchSize(batchSize int) Option {
+	return func(config *Config) {
+		config.BatchSize = batchSize
+	}
+}
+
+func WithQueueSize(queueSize int) Option {
+	return func(config *Config) {
+		config.QueueSize = queueSize
+	}
+}
+
+func WithBatchTimeout(batchTimeout int) Option {
+	return func(config *Config) {
+		config.BatchTimeout = batchTimeout
+	}
+}
+
+func WithTimeout(timeout int) Option {
+	return func(config *Config) {
+		config.Timeout = timeout
+	}
+}
+
+// WithErrorHandler handle the errors of exporting and the dropped spans, they are printed by the standard log by default,
+// the logger of sdk can not be used here since it depends on this package
+// 处理导出错误与被丢弃的span, 默认由标准库log打印, 由于sdk的logger依赖本包, 此处无法使用
+func WithErrorHandler(handler func(err error)) Option {
+	return func(config *Config) {
+		config.errorHandler = handler
+	}
+}
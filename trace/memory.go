@@ -0,0 +1,42 @@
+package trace
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryExporter Keep the exported spans in memory, which is useful in tests
+// 将导出的span保存在内存中, 便于测试
+type MemoryExporter struct {
+	mu    sync.Mutex
+	spans []*Span
+}
+
+func NewMemoryExporter() *MemoryExporter {
+	return &MemoryExporter{}
+}
+
+func (e *MemoryExporter) ExportSpans(_ context.Context, spans []*Span) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func (e *MemoryExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+// Spans Get the exported spans in the order of ending
+// 按结束顺序获取已导出的span
+func (e *MemoryExporter) Spans() []*Span {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]*Span(nil), e.spans...)
+}
+
+func (e *MemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = nil
+}
@@ -0,0 +1,58 @@
+package otlp
+
+const (
+	defaultEndpoint = "http://localhost:4318/v1/traces"
+	defaultTimeout  = 10
+)
+
+type Config struct {
+	// Endpoint the url of the OTLP/HTTP traces receiver, default "http://localhost:4318/v1/traces"
+	// OTLP/HTTP traces接收端的url, 默认"http://localhost:4318/v1/traces"
+	Endpoint string `mapstructure:"endpoint" json:"endpoint"`
+	// ServiceName the service.name of resource
+	// resource的service.name
+	ServiceName string `mapstructure:"service_name" json:"service_name"`
+	// Headers the extra headers of export requests, such as the authorization
+	// 导出请求的额外请求头, 例如认证信息
+	Headers map[string]string `mapstructure:"headers" json:"headers"`
+	// Timeout the seconds to wait each export request, default 10
+	// 每次导出请求的等待秒数, 默认10
+	Timeout int `mapstructure:"timeout" json:"timeout"`
+}
+
+type Option func(config *Config)
+
+func newConfig(options ...Option) Config {
+	config := Config{
+		Endpoint: defaultEndpoint,
+		Timeout:  defaultTimeout,
+	}
+	for _, option := range options {
+		option(&config)
+	}
+	return config
+}
+
+func WithEndpoint(endpoint string) Option {
+	return func(config *Config) {
+		config.Endpoint = endpoint
+	}
+}
+
+func WithServiceName(serviceName string) Option {
+	return func(config *Config) {
+		config.ServiceName = serviceName
+	}
+}
+
+func WithHeaders(headers map[string]string) Option {
+	return func(config *Config) {
+		config.Headers = headers
+	}
+}
+
+func WithTimeout(timeout int) Option {
+	return func(config *Config) {
+		config.Timeout = timeout
+	}
+}
@@ -0,0 +1,175 @@
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/whereabouts/sdk/trace"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+const scopeName = "github.com/whereabouts/sdk/trace"
+
+// Exporter Export the spans to the OTLP/HTTP receiver in the JSON encoding, such as the OpenTelemetry Collector
+// 以JSON编码将span导出到OTLP/HTTP接收端, 例如OpenTelemetry Collector
+type Exporter struct {
+	config Config
+	client *http.Client
+}
+
+func NewExporter(options ...Option) *Exporter {
+	return NewExporterWithConfig(newConfig(options...))
+}
+
+func NewExporterWithConfig(config Config) *Exporter {
+	return &Exporter{
+		config: config,
+		client: &http.Client{Timeout: time.Duration(config.Timeout) * time.Second},
+	}
+}
+
+func (e *Exporter) ExportSpans(ctx context.Context, spans []*trace.Span) error {
+	body, err := json.Marshal(e.convert(spans))
+	if err != nil {
+		return errors.Wrap(err, "marshal spans err")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range e.config.Headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "send spans err")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errors.Errorf("unexpected status %s: %s", resp.Status, msg)
+	}
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	return nil
+}
+
+func (e *Exporter) Shutdown(context.Context) error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+type exportRequest struct {
+	ResourceSpans []resourceSpans `json:"resourceSpans"`
+}
+
+type resourceSpans struct {
+	Resource   resource     `json:"resource"`
+	ScopeSpans []scopeSpans `json:"scopeSpans"`
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes,omitempty"`
+}
+
+type scopeSpans struct {
+	Scope scope  `json:"scope"`
+	Spans []span `json:"spans"`
+}
+
+type scope struct {
+	Name string `json:"name"`
+}
+
+type span struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	ParentSpanID      string     `json:"parentSpanId,omitempty"`
+	Name              string     `json:"name"`
+	Kind              int        `json:"kind"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	Status            status     `json:"status"`
+}
+
+type status struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+// anyValue the int64 is encoded as string in the JSON encoding of OTLP
+type anyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+func (e *Exporter) convert(spans []*trace.Span) exportRequest {
+	res := resource{}
+	if e.config.ServiceName != "" {
+		res.Attributes = append(res.Attributes, newKeyValue("service.name", e.config.ServiceName))
+	}
+	list := make([]span, 0, len(spans))
+	for _, s := range spans {
+		item := span{
+			TraceID:           s.SpanContext.TraceID.String(),
+			SpanID:            s.SpanContext.SpanID.String(),
+			Name:              s.Name,
+			Kind:              int(s.Kind),
+			StartTimeUnixNano: strconv.FormatInt(s.StartTime.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.EndTime.UnixNano(), 10),
+			Status:            status{Code: int(s.StatusCode), Message: s.StatusMessage},
+		}
+		if s.ParentSpanID.IsValid() {
+			item.ParentSpanID = s.ParentSpanID.String()
+		}
+		for key, value := range s.Attributes {
+			item.Attributes = append(item.Attributes, newKeyValue(key, value))
+		}
+		list = append(list, item)
+	}
+	return exportRequest{ResourceSpans: []resourceSpans{{
+		Resource:   res,
+		ScopeSpans: []scopeSpans{{Scope: scope{Name: scopeName}, Spans: list}},
+	}}}
+}
+
+func newKeyValue(key string, value interface{}) keyValue {
+	kv := keyValue{Key: key}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String:
+		s := v.String()
+		kv.Value.StringValue = &s
+	case reflect.Bool:
+		b := v.Bool()
+		kv.Value.BoolValue = &b
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i := strconv.FormatInt(v.Int(), 10)
+		kv.Value.IntValue = &i
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i := strconv.FormatUint(v.Uint(), 10)
+		kv.Value.IntValue = &i
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		kv.Value.DoubleValue = &f
+	default:
+		s := fmt.Sprint(value)
+		kv.Value.StringValue = &s
+	}
+	return kv
+}
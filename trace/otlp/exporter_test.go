@@ -0,0 +1,84 @@
+package otlp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/whereabouts/sdk/trace"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestExporter(t *testing.T) {
+	var (
+		body   map[string]interface{}
+		header http.Header
+		fail   bool
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		data, _ := ioutil.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &body)
+		if fail {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte("bad spans"))
+		}
+	}))
+	defer srv.Close()
+
+	exporter := NewExporter(
+		WithEndpoint(srv.URL+"/v1/traces"),
+		WithServiceName("user"),
+		WithHeaders(map[string]string{"Authorization": "token"}),
+	)
+	p := trace.NewProvider(exporter)
+	trace.SetProvider(p)
+	defer trace.SetProvider(nil)
+	ctx, parent := trace.Start(context.Background(), "GET /users", trace.WithKind(trace.KindServer))
+	_, child := trace.Start(ctx, "redis get", trace.WithKind(trace.KindClient), trace.WithAttribute("db.system", "redis"),
+		trace.WithAttribute("retry", 2), trace.WithAttribute("cached", true), trace.WithAttribute("ratio", 0.5))
+	child.RecordError(errors.New("timeout"))
+	child.End()
+	parent.End()
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if header.Get("Content-Type") != "application/json" || header.Get("Authorization") != "token" {
+		t.Fatalf("unexpected headers %v", header)
+	}
+	resourceSpans := body["resourceSpans"].([]interface{})[0].(map[string]interface{})
+	attr := resourceSpans["resource"].(map[string]interface{})["attributes"].([]interface{})[0].(map[string]interface{})
+	if attr["key"] != "service.name" || attr["value"].(map[string]interface{})["stringValue"] != "user" {
+		t.Fatalf("unexpected resource %v", resourceSpans["resource"])
+	}
+	spans := resourceSpans["scopeSpans"].([]interface{})[0].(map[string]interface{})["spans"].([]interface{})
+	if len(spans) != 2 {
+		t.Fatalf("expect 2 spans, got %d", len(spans))
+	}
+	s := spans[0].(map[string]interface{})
+	if s["traceId"] != child.SpanContext.TraceID.String() || s["parentSpanId"] != parent.SpanContext.SpanID.String() ||
+		s["kind"] != float64(trace.KindClient) || s["name"] != "redis get" {
+		t.Fatalf("unexpected span %v", s)
+	}
+	if status := s["status"].(map[string]interface{}); status["code"] != float64(trace.StatusError) || status["message"] != "timeout" {
+		t.Fatalf("unexpected status %v", status)
+	}
+	values := map[string]interface{}{}
+	for _, a := range s["attributes"].([]interface{}) {
+		kv := a.(map[string]interface{})
+		for _, v := range kv["value"].(map[string]interface{}) {
+			values[kv["key"].(string)] = v
+		}
+	}
+	if values["db.system"] != "redis" || values["retry"] != "2" || values["cached"] != true || values["ratio"] != 0.5 {
+		t.Fatalf("unexpected attributes %v", values)
+	}
+
+	fail = true
+	if err := exporter.ExportSpans(context.Background(), []*trace.Span{child}); err == nil {
+		t.Fatal("expect err of bad status")
+	}
+}
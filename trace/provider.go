@@ -0,0 +1,163 @@
+package trace
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"sync"
+	"time"
+)
+
+// Exporter Export the ended spans to a backend, such as the OTLP collector
+// 将结束的span导出到后端, 例如OTLP collector
+type Exporter interface {
+	ExportSpans(ctx context.Context, spans []*Span) error
+	Shutdown(ctx context.Context) error
+}
+
+var (
+	providerMu sync.RWMutex
+	provider   *Provider
+)
+
+// SetProvider Set the global provider, the spans started after it are recorded and exported by it,
+// nil stops recording
+// 设置全局provider, 之后开始的span由其记录与导出, 为nil时停止记录
+func SetProvider(p *Provider) {
+	providerMu.Lock()
+	defer providerMu.Unlock()
+	provider = p
+}
+
+func GetProvider() *Provider {
+	providerMu.RLock()
+	defer providerMu.RUnlock()
+	return provider
+}
+
+// Provider Export the ended spans by the exporter in batches in the background
+// 在后台按批次通过exporter导出结束的span
+type Provider struct {
+	exporter  Exporter
+	config    Config
+	queue     chan *Span
+	flush     chan chan struct{}
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func NewProvider(exporter Exporter, options ...Option) *Provider {
+	p := &Provider{
+		exporter: exporter,
+		config:   newConfig(options...),
+		flush:    make(chan chan struct{}),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if p.config.BatchSize <= 0 {
+		p.config.BatchSize = defaultBatchSize
+	}
+	if p.config.BatchTimeout <= 0 {
+		p.config.BatchTimeout = defaultBatchTimeout
+	}
+	p.queue = make(chan *Span, p.config.QueueSize)
+	go p.run()
+	return p
+}
+
+func (p *Provider) enqueue(span *Span) {
+	select {
+	case <-p.stop:
+		return
+	default:
+	}
+	select {
+	case p.queue <- span:
+	default:
+		p.config.errorHandler(errors.Errorf("queue is full, span %s is dropped", span.Name))
+	}
+}
+
+// Flush Export the spans in queue and wait until it is done or ctx is done
+// 导出队列中的span, 并等待完成或ctx结束
+func (p *Provider) Flush(ctx context.Context) error {
+	ch := make(chan struct{})
+	select {
+	case p.flush <- ch:
+	case <-p.done:
+		return errors.New("provider is shut down")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown Export the spans in queue and shut down the exporter, only the first call takes effect,
+// it can be registered as a shutdown hook of httpserver
+// 导出队列中的span并关闭exporter, 只有第一次调用生效, 可注册为httpserver的shutdown hook
+func (p *Provider) Shutdown(ctx context.Context) error {
+	var err error
+	p.closeOnce.Do(func() {
+		close(p.stop)
+		select {
+		case <-p.done:
+		case <-ctx.Done():
+			err = ctx.Err()
+			return
+		}
+		err = p.exporter.Shutdown(ctx)
+	})
+	return err
+}
+
+func (p *Provider) run() {
+	defer close(p.done)
+	ticker := time.NewTicker(time.Duration(p.config.BatchTimeout) * time.Second)
+	defer ticker.Stop()
+	batch := make([]*Span, 0, p.config.BatchSize)
+	export := func() {
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(p.config.Timeout)*time.Second)
+		if err := p.exporter.ExportSpans(ctx, batch); err != nil {
+			p.config.errorHandler(errors.Wrapf(err, "export %d spans err", len(batch)))
+		}
+		cancel()
+		batch = make([]*Span, 0, p.config.BatchSize)
+	}
+	drain := func() {
+		for {
+			select {
+			case span := <-p.queue:
+				if batch = append(batch, span); len(batch) >= p.config.BatchSize {
+					export()
+				}
+			default:
+				export()
+				return
+			}
+		}
+	}
+	for {
+		select {
+		case span := <-p.queue:
+			if batch = append(batch, span); len(batch) >= p.config.BatchSize {
+				export()
+			}
+		case <-ticker.C:
+			export()
+		case ch := <-p.flush:
+			drain()
+			close(ch)
+		case <-p.stop:
+			drain()
+			return
+		}
+	}
+}
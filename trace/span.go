@@ -0,0 +1,158 @@
+package trace
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type SpanKind int
+
+const (
+	KindInternal SpanKind = 1
+	KindServer   SpanKind = 2
+	KindClient   SpanKind = 3
+)
+
+type StatusCode int
+
+const (
+	StatusUnset StatusCode = 0
+	StatusOK    StatusCode = 1
+	StatusError StatusCode = 2
+)
+
+// Span A timed operation of a trace, such as handling a request or querying a database.
+// The span is recorded only if a provider is set by SetProvider when it starts, and it is exported when it ends.
+// The fields must not be modified after the span ends
+// trace中的一个计时操作, 例如处理请求或查询数据库. 只有开始时已通过SetProvider设置了provider的span才会被记录,
+// 并在结束时导出. span结束后不得修改其字段
+type Span struct {
+	Name          string
+	Kind          SpanKind
+	SpanContext   SpanContext
+	ParentSpanID  SpanID
+	StartTime     time.Time
+	EndTime       time.Time
+	Attributes    map[string]interface{}
+	StatusCode    StatusCode
+	StatusMessage string
+
+	mu       sync.Mutex
+	provider *Provider
+	ended    bool
+}
+
+type StartOption func(span *Span)
+
+func WithKind(kind SpanKind) StartOption {
+	return func(span *Span) {
+		span.Kind = kind
+	}
+}
+
+func WithAttribute(key string, value interface{}) StartOption {
+	return func(span *Span) {
+		span.Attributes[key] = value
+	}
+}
+
+type spanKey struct{}
+
+// Start Start a span as the child of the span in ctx, or a new trace if there is none, and return the ctx with it.
+// The first server span of a request takes the span context extracted by Extract, so that the logs before it
+// carry the same span id
+// 以ctx中span的子span开始一个span, ctx中没有span时开启新的trace, 并返回带有该span的ctx.
+// 一个请求的第一个server span使用Extract提取的span context, 使其之前的日志带有相同的span id
+func Start(ctx context.Context, name string, options ...StartOption) (context.Context, *Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	span := &Span{
+		Name:       name,
+		Kind:       KindInternal,
+		StartTime:  time.Now(),
+		Attributes: make(map[string]interface{}),
+		provider:   GetProvider(),
+	}
+	for _, option := range options {
+		option(span)
+	}
+	parent := SpanContextFrom(ctx)
+	remoteParent, extracted := ctx.Value(remoteParentKey{}).(SpanID)
+	switch {
+	case span.Kind == KindServer && extracted && SpanFrom(ctx) == nil && parent.IsValid():
+		span.SpanContext = parent
+		span.ParentSpanID = remoteParent
+	case parent.IsValid():
+		span.SpanContext = SpanContext{TraceID: parent.TraceID, SpanID: NewSpanID(), Sampled: parent.Sampled}
+		span.ParentSpanID = parent.SpanID
+	default:
+		span.SpanContext = SpanContext{TraceID: NewTraceID(), SpanID: NewSpanID(), Sampled: true}
+	}
+	ctx = context.WithValue(ctx, spanKey{}, span)
+	return WithSpanContext(ctx, span.SpanContext), span
+}
+
+// SpanFrom Get the span started by Start from ctx, nil if not exists
+// 从ctx获取由Start开始的span, 不存在时为nil
+func SpanFrom(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// IsRecording Report whether the span will be exported when it ends
+// 报告span结束时是否会被导出
+func (s *Span) IsRecording() bool {
+	return s != nil && s.provider != nil && s.SpanContext.Sampled
+}
+
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if !s.IsRecording() {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.Attributes[key] = value
+	}
+}
+
+func (s *Span) SetStatus(code StatusCode, message string) {
+	if !s.IsRecording() {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.StatusCode, s.StatusMessage = code, message
+	}
+}
+
+// RecordError Set the status to error with the message of err, nil err is ignored
+// 将状态设置为error并使用err的信息, err为nil时忽略
+func (s *Span) RecordError(err error) {
+	if err != nil {
+		s.SetStatus(StatusError, err.Error())
+	}
+}
+
+// End End the span and export it, only the first call takes effect
+// 结束并导出span, 只有第一次调用生效
+func (s *Span) End() {
+	if !s.IsRecording() {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.EndTime = time.Now()
+	s.mu.Unlock()
+	s.provider.enqueue(s)
+}
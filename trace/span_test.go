@@ -0,0 +1,104 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func newTestProvider(t *testing.T) *MemoryExporter {
+	exporter := NewMemoryExporter()
+	p := NewProvider(exporter, WithBatchSize(2))
+	SetProvider(p)
+	t.Cleanup(func() {
+		SetProvider(nil)
+		_ = p.Shutdown(context.Background())
+	})
+	return exporter
+}
+
+func flush(t *testing.T) {
+	if err := GetProvider().Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStart(t *testing.T) {
+	exporter := newTestProvider(t)
+
+	header := http.Header{}
+	header.Set(HeaderTraceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := Extract(context.Background(), header)
+	extracted := SpanContextFrom(ctx)
+
+	ctx, server := Start(ctx, "GET /users", WithKind(KindServer))
+	if server.SpanContext != extracted || server.ParentSpanID.String() != "00f067aa0ba902b7" {
+		t.Fatalf("expect the server span takes the extracted span context, got %+v", server)
+	}
+	childCtx, child := Start(ctx, "query", WithAttribute("db.system", "mysql"))
+	if child.SpanContext.TraceID != server.SpanContext.TraceID || child.ParentSpanID != server.SpanContext.SpanID {
+		t.Fatalf("expect child of server span, got %+v", child)
+	}
+	if SpanFrom(childCtx) != child || SpanContextFrom(childCtx) != child.SpanContext {
+		t.Fatal("expect the child span in ctx")
+	}
+	// a nested server span does not take the extracted span context again
+	if _, nested := Start(ctx, "nested", WithKind(KindServer)); nested.SpanContext.SpanID == server.SpanContext.SpanID {
+		t.Fatal("expect a new span id of nested server span")
+	}
+	child.RecordError(errors.New("timeout"))
+	child.End()
+	child.End()
+	server.End()
+	server.SetAttribute("ignored", true)
+	flush(t)
+
+	spans := exporter.Spans()
+	if len(spans) != 2 || spans[0] != child || spans[1] != server {
+		t.Fatalf("expect child and server spans exported once, got %v", spans)
+	}
+	if child.StatusCode != StatusError || child.StatusMessage != "timeout" || child.Attributes["db.system"] != "mysql" {
+		t.Fatalf("unexpected child span %+v", child)
+	}
+	if _, ok := server.Attributes["ignored"]; ok || server.EndTime.Before(server.StartTime) {
+		t.Fatalf("expect the span not modified after ended, got %+v", server)
+	}
+}
+
+func TestNotRecording(t *testing.T) {
+	ctx, span := Start(context.Background(), "root")
+	if span.IsRecording() || !span.SpanContext.IsValid() {
+		t.Fatalf("expect a valid but not recording span without provider, got %+v", span)
+	}
+	span.SetAttribute("key", "value")
+	span.End()
+
+	exporter := newTestProvider(t)
+	header := http.Header{}
+	header.Set(HeaderTraceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	_, unsampled := Start(Extract(ctx, header), "unsampled", WithKind(KindServer))
+	unsampled.End()
+	flush(t)
+	if unsampled.IsRecording() || len(exporter.Spans()) != 0 {
+		t.Fatal("expect the unsampled span not exported")
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	exporter := NewMemoryExporter()
+	p := NewProvider(exporter)
+	SetProvider(p)
+	defer SetProvider(nil)
+	_, span := Start(context.Background(), "root")
+	span.End()
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(exporter.Spans()) != 1 {
+		t.Fatalf("expect the queued span exported when shut down, got %d", len(exporter.Spans()))
+	}
+	if err := p.Flush(context.Background()); err == nil {
+		t.Fatal("expect flush err after shut down")
+	}
+}
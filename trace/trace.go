@@ -0,0 +1,192 @@
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/pkg/errors"
+	"net/http"
+	"strings"
+)
+
+const (
+	HeaderRequestID   = "X-Request-ID"
+	HeaderTraceparent = "traceparent"
+
+	// maxRequestIDLen the incoming request id longer than it is replaced, to keep the logs clean
+	maxRequestIDLen = 128
+
+	traceparentVersion = "00"
+	flagSampled        = 0x01
+)
+
+type TraceID [16]byte
+
+type SpanID [8]byte
+
+func (id TraceID) IsValid() bool {
+	return id != TraceID{}
+}
+
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+func (id SpanID) IsValid() bool {
+	return id != SpanID{}
+}
+
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// NewTraceID Generate a random trace id
+// 生成随机的trace id
+func NewTraceID() TraceID {
+	var id TraceID
+	_, _ = rand.Read(id[:])
+	return id
+}
+
+// NewSpanID Generate a random span id
+// 生成随机的span id
+func NewSpanID() SpanID {
+	var id SpanID
+	_, _ = rand.Read(id[:])
+	return id
+}
+
+// NewRequestID Generate a random request id in the form of uuid
+// 生成uuid形式的随机request id
+func NewRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// SpanContext The identity of a span propagated by the W3C traceparent header
+// 通过W3C traceparent请求头传播的span标识
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// Traceparent Format as the W3C traceparent header, example: 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+// 格式化为W3C traceparent请求头, 例: 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+func (sc SpanContext) Traceparent() string {
+	var flags byte
+	if sc.Sampled {
+		flags |= flagSampled
+	}
+	return fmt.Sprintf("%s-%s-%s-%02x", traceparentVersion, sc.TraceID, sc.SpanID, flags)
+}
+
+// ParseTraceparent Parse the W3C traceparent header
+// 解析W3C traceparent请求头
+func ParseTraceparent(traceparent string) (SpanContext, error) {
+	sc := SpanContext{}
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == traceparentVersion && len(parts) != 4) {
+		return sc, errors.Errorf("invalid traceparent %q", traceparent)
+	}
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil || len(parts[1]) != 32 {
+		return sc, errors.Errorf("invalid trace id of traceparent %q", traceparent)
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil || len(parts[2]) != 16 {
+		return sc, errors.Errorf("invalid span id of traceparent %q", traceparent)
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil || len(flags) != 1 {
+		return sc, errors.Errorf("invalid flags of traceparent %q", traceparent)
+	}
+	if !sc.IsValid() {
+		return sc, errors.Errorf("all zero id of traceparent %q", traceparent)
+	}
+	sc.Sampled = flags[0]&flagSampled != 0
+	return sc, nil
+}
+
+type requestIDKey struct{}
+
+type spanContextKey struct{}
+
+type remoteParentKey struct{}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFrom Get the request id from ctx, empty if not exists
+// 从ctx获取request id, 不存在时为空
+func RequestIDFrom(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+func WithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// SpanContextFrom Get the span context from ctx, invalid if not exists
+// 从ctx获取span context, 不存在时无效
+func SpanContextFrom(ctx context.Context) SpanContext {
+	if ctx == nil {
+		return SpanContext{}
+	}
+	sc, _ := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc
+}
+
+// Extract Read the request id and traceparent from the incoming headers, the request id is generated if it is missing
+// or invalid, a new trace is started if the traceparent is missing or invalid, otherwise the trace is continued
+// with a new span whose parent is the incoming one
+// 从请求头读取request id与traceparent, request id缺失或无效时生成新的,
+// traceparent缺失或无效时开启新的trace, 否则以新的span延续该trace, 其父span为传入的span
+func Extract(ctx context.Context, header http.Header) context.Context {
+	requestID := header.Get(HeaderRequestID)
+	if !validRequestID(requestID) {
+		requestID = NewRequestID()
+	}
+	sc, err := ParseTraceparent(header.Get(HeaderTraceparent))
+	if err != nil {
+		sc = SpanContext{TraceID: NewTraceID(), Sampled: true}
+	}
+	// the new span id is taken by the server span started later, whose parent is the incoming span
+	ctx = context.WithValue(ctx, remoteParentKey{}, sc.SpanID)
+	sc.SpanID = NewSpanID()
+	return WithSpanContext(WithRequestID(ctx, requestID), sc)
+}
+
+// Inject Write the request id and traceparent of ctx into the outgoing headers
+// 将ctx中的request id与traceparent写入请求头
+func Inject(ctx context.Context, header http.Header) {
+	if requestID := RequestIDFrom(ctx); requestID != "" {
+		header.Set(HeaderRequestID, requestID)
+	}
+	if sc := SpanContextFrom(ctx); sc.IsValid() {
+		header.Set(HeaderTraceparent, sc.Traceparent())
+	}
+}
+
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] <= ' ' || requestID[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+package trace
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, err := ParseTraceparent(traceparent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || sc.SpanID.String() != "00f067aa0ba902b7" || !sc.Sampled {
+		t.Fatalf("unexpected span context %+v", sc)
+	}
+	if sc.Traceparent() != traceparent {
+		t.Fatalf("expect %s, got %s", traceparent, sc.Traceparent())
+	}
+	for _, invalid := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902bz-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	} {
+		if _, err = ParseTraceparent(invalid); err == nil {
+			t.Fatalf("expect err of %q", invalid)
+		}
+	}
+	// the future version may have more fields
+	if _, err = ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExtractAndInject(t *testing.T) {
+	header := http.Header{}
+	header.Set(HeaderRequestID, "req-1")
+	header.Set(HeaderTraceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := Extract(context.Background(), header)
+	sc := SpanContextFrom(ctx)
+	if RequestIDFrom(ctx) != "req-1" || sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("expect the incoming trace continued, got %s %+v", RequestIDFrom(ctx), sc)
+	}
+	if sc.SpanID.String() == "00f067aa0ba902b7" || !sc.SpanID.IsValid() {
+		t.Fatalf("expect a new span id, got %s", sc.SpanID)
+	}
+
+	out := http.Header{}
+	Inject(ctx, out)
+	if out.Get(HeaderRequestID) != "req-1" || out.Get(HeaderTraceparent) != sc.Traceparent() {
+		t.Fatalf("unexpected outgoing headers %v", out)
+	}
+
+	header.Set(HeaderRequestID, "bad id")
+	header.Set(HeaderTraceparent, "invalid")
+	ctx = Extract(context.Background(), header)
+	if id := RequestIDFrom(ctx); id == "bad id" || len(id) != 36 {
+		t.Fatalf("expect a generated request id, got %s", id)
+	}
+	if !SpanContextFrom(ctx).IsValid() {
+		t.Fatal("expect a new trace started")
+	}
+}